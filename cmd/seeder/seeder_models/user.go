@@ -18,9 +18,10 @@ func SeedUser(d *gorm.DB, count int) {
 	}
 
 	for i := 0; i < count; i++ {
+		gewisID := uint(1000 + i)
 		user := models.User{
 			Name:    "User" + strconv.Itoa(i),
-			GEWISID: uint(1000 + i),
+			GEWISID: &gewisID,
 		}
 
 		if err := d.Create(&user).Error; err != nil {
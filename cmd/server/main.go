@@ -2,12 +2,19 @@ package main
 
 import (
 	"GEWIS-Rooster/docs"
+	"GEWIS-Rooster/internal/admin"
+	"GEWIS-Rooster/internal/audit"
 	"GEWIS-Rooster/internal/auth"
 	"GEWIS-Rooster/internal/export"
+	"GEWIS-Rooster/internal/guest"
+	"GEWIS-Rooster/internal/me"
+	"GEWIS-Rooster/internal/notification"
 	"GEWIS-Rooster/internal/organ"
 	"GEWIS-Rooster/internal/platform/database"
 	"GEWIS-Rooster/internal/platform/middleware"
+	"GEWIS-Rooster/internal/platform/signing"
 	"GEWIS-Rooster/internal/roster"
+	"GEWIS-Rooster/internal/token"
 	"GEWIS-Rooster/internal/user"
 	"database/sql"
 	"github.com/gin-contrib/cors"
@@ -18,6 +25,7 @@ import (
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"os"
 	"strings"
+	"time"
 )
 
 // @title						GRooster
@@ -68,35 +76,53 @@ func main() {
 		AllowOrigins:     origins,
 		AllowMethods:     []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
 		AllowHeaders:     []string{"Content-Type", "Authorization"},
+		ExposeHeaders:    []string{"X-Impersonated-By"},
 		AllowCredentials: true, // If you need to support cookies or authentication
 	}))
 
 	api := r.Group(os.Getenv("BASE_PATH"))
 
 	userService := user.NewUserService(db)
-	rosterService := roster.NewRosterService(db, userService)
+	notificationService := notification.NewNotificationService(db)
+	rosterService := roster.NewRosterService(db, userService, notificationService)
+	go roster.PurgeTrashPeriodically(rosterService, roster.TrashRetentionFromEnv(), time.Hour)
 	exportService := export.NewExportService(rosterService, db)
 	organService := organ.NewOrganService(db)
+	tokenService := token.NewTokenService(db)
+	auditService := audit.NewAuditService(db)
+	adminService := admin.NewAdminService(db)
+	meService := me.NewMeService(db)
 
 	m := middleware.AuthMiddleware{}
-	provider, config := m.SetupOIDC()
-
-	authService := auth.NewAuthService(userService, db)
-	authMiddle := middleware.NewAuthMiddleware(authService, userService)
-
-	// Auth routes (no authentication required)
-	authGroup := api.Group("/auth")
-	auth.NewAuthHandler(authGroup, authService, provider, config)
-
-	protectedGroup := api.Group("")
-	protectedGroup.Use(authMiddle.AuthMiddlewareCheck())
-	{
-		user.NewUserHandler(protectedGroup, userService)
-		roster.NewRosterHandler(rosterService, protectedGroup, db)
-		export.NewExportHandler(exportService, protectedGroup)
-		organ.NewOrganHandler(protectedGroup, organService, db)
+	oidcConfig := auth.ConfigFromEnv()
+	provider, config, verifier := m.SetupOIDC(oidcConfig.Issuer)
+
+	keys, err := signing.LoadFromEnv()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load token signing keys")
 	}
 
+	authService := auth.NewAuthService(userService, db, verifier, oidcConfig, keys)
+	authMiddle := middleware.NewAuthMiddleware(authService, userService, tokenService, auditService, keys)
+	guestService := guest.NewGuestService(db, keys, rosterService, userService)
+
+	registerRoutes(api, db, authMiddle.AuthMiddlewareCheck(), services{
+		auth:         authService,
+		user:         userService,
+		roster:       rosterService,
+		export:       exportService,
+		organ:        organService,
+		notification: notificationService,
+		token:        tokenService,
+		audit:        auditService,
+		admin:        adminService,
+		me:           meService,
+		guest:        guestService,
+		keys:         keys,
+		provider:     provider,
+		oauthConfig:  config,
+	})
+
 	r.GET("swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	if err := r.Run(":8080"); err != nil {
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"GEWIS-Rooster/internal/admin"
+	"GEWIS-Rooster/internal/audit"
+	"GEWIS-Rooster/internal/auth"
+	"GEWIS-Rooster/internal/export"
+	"GEWIS-Rooster/internal/guest"
+	"GEWIS-Rooster/internal/me"
+	"GEWIS-Rooster/internal/notification"
+	"GEWIS-Rooster/internal/organ"
+	"GEWIS-Rooster/internal/platform/authz"
+	"GEWIS-Rooster/internal/platform/signing"
+	"GEWIS-Rooster/internal/roster"
+	"GEWIS-Rooster/internal/token"
+	"GEWIS-Rooster/internal/user"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+)
+
+type services struct {
+	auth         auth.Service
+	user         user.Service
+	roster       roster.Service
+	export       export.Service
+	organ        organ.Service
+	notification notification.Service
+	token        token.Service
+	audit        audit.Service
+	admin        admin.Service
+	me           me.Service
+	guest        guest.Service
+
+	keys *signing.KeySet
+
+	provider    *oidc.Provider
+	oauthConfig *oauth2.Config
+}
+
+// registerRoutes registers the API routes on api. Every route states its
+// authorization policy, the returned registry holds the policy of each route.
+func registerRoutes(api *gin.RouterGroup, db *gorm.DB, authCheck gin.HandlerFunc, s services) *authz.Registry {
+	registry := authz.NewRegistry()
+
+	// Auth routes (no authentication required)
+	authGroup := authz.NewRouter(api.Group("/auth"), db, registry)
+	auth.NewAuthHandler(authGroup, s.auth, s.provider, s.oauthConfig)
+	auth.NewJWKSHandler(authz.NewRouter(api, db, registry), s.keys)
+	guest.NewGuestLinkHandler(authz.NewRouter(api, db, registry), s.guest)
+
+	protectedGroup := api.Group("")
+	protectedGroup.Use(authCheck)
+	registerProtectedRoutes(authz.NewRouter(protectedGroup, db, registry), s)
+
+	return registry
+}
+
+func registerProtectedRoutes(rg *authz.Router, s services) {
+	user.NewUserHandler(rg, s.user)
+	roster.NewRosterHandler(s.roster, rg)
+	export.NewExportHandler(s.export, rg)
+	organ.NewOrganHandler(rg, s.organ, s.auth)
+	notification.NewNotificationHandler(rg, s.notification)
+	token.NewTokenHandler(rg, s.token)
+	auth.NewImpersonationHandler(rg, s.auth, s.audit)
+	admin.NewAdminHandler(rg, s.admin)
+	me.NewMeHandler(rg, s.me)
+	guest.NewGuestHandler(rg, s.guest)
+}
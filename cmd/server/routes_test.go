@@ -0,0 +1,188 @@
+package main
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/admin"
+	"GEWIS-Rooster/internal/audit"
+	"GEWIS-Rooster/internal/auth"
+	"GEWIS-Rooster/internal/export"
+	"GEWIS-Rooster/internal/guest"
+	"GEWIS-Rooster/internal/me"
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/notification"
+	"GEWIS-Rooster/internal/organ"
+	"GEWIS-Rooster/internal/platform/authz"
+	"GEWIS-Rooster/internal/platform/signing"
+	"GEWIS-Rooster/internal/roster"
+	"GEWIS-Rooster/internal/token"
+	"GEWIS-Rooster/internal/user"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestRouter registers the routes on a test engine, authenticating every
+// request with authCheck.
+func newTestRouter(t *testing.T, authCheck gin.HandlerFunc) (*gin.Engine, *authz.Registry, *gorm.DB) {
+	gin.SetMode(gin.TestMode)
+	db := seeder.Seeder(":memory:")
+
+	userService := user.NewUserService(db)
+	notificationService := notification.NewNotificationService(db)
+	rosterService := roster.NewRosterService(db, userService, notificationService)
+
+	t.Setenv("JWT_SECRET", "test-secret")
+	keys, err := signing.LoadFromEnv()
+	assert.NoError(t, err)
+
+	r := gin.New()
+	registry := registerRoutes(r.Group("/api"), db, authCheck, services{
+		auth:         auth.NewAuthService(userService, db, nil, auth.ConfigFromEnv(), keys),
+		user:         userService,
+		roster:       rosterService,
+		export:       export.NewExportService(rosterService, db),
+		organ:        organ.NewOrganService(db),
+		notification: notificationService,
+		token:        token.NewTokenService(db),
+		audit:        audit.NewAuditService(db),
+		admin:        admin.NewAdminService(db),
+		me:           me.NewMeService(db),
+		guest:        guest.NewGuestService(db, keys, rosterService, userService),
+		keys:         keys,
+	})
+
+	return r, registry, db
+}
+
+func TestRegisterRoutes_EveryRouteHasPolicy(t *testing.T) {
+	r, registry, _ := newTestRouter(t, func(c *gin.Context) {})
+
+	routes := r.Routes()
+	assert.NotEmpty(t, routes)
+
+	for _, route := range routes {
+		_, ok := registry.Lookup(route.Method, route.Path)
+		assert.True(t, ok, "%s %s is registered without an authorization policy", route.Method, route.Path)
+	}
+}
+
+func TestRegisterRoutes_PersonalAccessTokensRefused(t *testing.T) {
+	var userID uint = 1
+	r, _, db := newTestRouter(t, func(c *gin.Context) {
+		c.Set("userID", userID)
+		authz.SetTokenScope(c, authz.TokenScope{})
+	})
+	db.Model(&models.User{}).Where("id = ?", userID).Update("platform_admin", true)
+
+	// Irreversible or personal routes need a login session, even for write tokens
+	routes := []struct {
+		method string
+		path   string
+	}{
+		{http.MethodDelete, "/api/user/1"},
+		{http.MethodGet, "/api/me/export"},
+		{http.MethodPost, "/api/impersonate/2"},
+		{http.MethodDelete, "/api/impersonate"},
+		// Platform admin routes span every organ, so no token scope fits them
+		{http.MethodGet, "/api/admin/users"},
+		{http.MethodGet, "/api/admin/organs"},
+		{http.MethodPut, "/api/admin/organ/1/member/2"},
+		{http.MethodPost, "/api/organ"},
+	}
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(route.method, route.path, nil))
+		assert.Equal(t, http.StatusForbidden, w.Code, "%s %s", route.method, route.path)
+	}
+}
+
+func TestRegisterRoutes_OrganScopedTokens(t *testing.T) {
+	var userID uint = 1
+	scope := authz.TokenScope{OrganIDs: []uint{1}}
+	r, _, _ := newTestRouter(t, func(c *gin.Context) {
+		c.Set("userID", userID)
+		authz.SetTokenScope(c, scope)
+	})
+
+	// The dashboard and notifications span all organs, so a token limited to
+	// one organ is refused there but still works for that organ
+	routes := []struct {
+		path string
+		want int
+	}{
+		{"/api/me", http.StatusForbidden},
+		{"/api/notification", http.StatusForbidden},
+		{"/api/user/?organId=1", http.StatusOK},
+	}
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, route.path, nil))
+		assert.Equal(t, route.want, w.Code, route.path)
+	}
+
+	scope = authz.TokenScope{}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/me", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestRegisterRoutes_RevokeSessionsGuarded(t *testing.T) {
+	var userID uint
+	r, _, db := newTestRouter(t, func(c *gin.Context) {
+		c.Set("userID", userID)
+	})
+
+	var members []models.UserOrgan
+	db.Where("organ_id = ?", 1).Order("user_id").Limit(4).Find(&members)
+	admin, owner, platformAdmin, member := members[0].UserID, members[1].UserID, members[2].UserID, members[3].UserID
+	roles := map[uint]models.OrganRole{admin: models.RoleAdmin, owner: models.RoleOwner, platformAdmin: models.RoleMember, member: models.RoleMember}
+	for id, role := range roles {
+		db.Model(&models.UserOrgan{}).Where("organ_id = ? AND user_id = ?", 1, id).
+			Updates(map[string]interface{}{"role": role, "status": models.MembershipActive})
+	}
+	db.Model(&models.User{}).Where("id IN ?", []uint{admin, owner, member}).Update("platform_admin", false)
+	db.Model(&models.User{}).Where("id = ?", platformAdmin).Update("platform_admin", true)
+
+	revoke := func(caller uint, target uint) int {
+		userID = caller
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, fmt.Sprintf("/api/organ/1/member/%d/sessions", target), nil))
+		return w.Code
+	}
+
+	assert.Equal(t, http.StatusOK, revoke(admin, member))
+	assert.Equal(t, http.StatusForbidden, revoke(admin, owner))
+	assert.Equal(t, http.StatusForbidden, revoke(admin, platformAdmin))
+	assert.Equal(t, http.StatusForbidden, revoke(owner, platformAdmin))
+	assert.Equal(t, http.StatusOK, revoke(owner, admin))
+	assert.Equal(t, http.StatusOK, revoke(platformAdmin, owner))
+}
+
+func TestRegisterRoutes_UserRoutesRestricted(t *testing.T) {
+	var userID uint = 1
+	r, _, db := newTestRouter(t, func(c *gin.Context) {
+		c.Set("userID", userID)
+	})
+	other := models.Organ{Name: "Elsewhere"}
+	db.Create(&other)
+
+	routes := []struct {
+		method string
+		path   string
+		want   int
+	}{
+		{http.MethodPost, "/api/user/create", http.StatusForbidden},
+		{http.MethodGet, "/api/user/", http.StatusBadRequest},
+		{http.MethodGet, fmt.Sprintf("/api/user/?organId=%d", other.ID), http.StatusForbidden},
+		{http.MethodGet, "/api/user/?organId=1", http.StatusOK},
+	}
+	for _, route := range routes {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(route.method, route.path, nil))
+		assert.Equal(t, route.want, w.Code, "%s %s", route.method, route.path)
+	}
+}
@@ -0,0 +1,24 @@
+package admin
+
+import "GEWIS-Rooster/internal/models"
+
+// OrganSummary is an organ with its number of members.
+type OrganSummary struct {
+	models.Organ
+
+	MemberCount int64 `json:"memberCount"`
+} // @name AdminOrganSummary
+
+type RosterFilterParams struct {
+	OrganID *uint `form:"organId"`
+	Saved   *bool `form:"saved"`
+} // @name AdminRosterFilterParams
+
+type AuditLogFilterParams struct {
+	ActorID *uint `form:"actorId"`
+	UserID  *uint `form:"userId"`
+} // @name AdminAuditLogFilterParams
+
+type SetMembershipParams struct {
+	Role models.OrganRole `json:"role" binding:"required,oneof=admin member owner"`
+} // @name AdminSetMembershipParams
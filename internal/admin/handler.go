@@ -0,0 +1,174 @@
+package admin
+
+import (
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	adminService Service
+}
+
+func NewAdminHandler(rg *authz.Router, adminService Service) *Handler {
+	h := &Handler{adminService: adminService}
+
+	g := rg.Group("/admin")
+
+	g.GET("/organs", authz.PlatformAdmin, h.GetOrgans)
+	g.GET("/users", authz.PlatformAdmin, h.GetUsers)
+	g.GET("/rosters", authz.PlatformAdmin, h.GetRosters)
+	g.GET("/audit-logs", authz.PlatformAdmin, h.GetAuditLogs)
+	g.PUT("/organ/:id/member/:userId", authz.PlatformAdmin, h.SetMembership)
+
+	return h
+}
+
+// GetOrgans
+//
+//	@Summary		List all organs
+//	@Security		BearerAuth
+//	@Description	Platform admins only. Lists every organ with its number of members.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}		admin.OrganSummary
+//	@Failure		403	{object}	map[string]string
+//	@Router			/admin/organs [get]
+func (h *Handler) GetOrgans(c *gin.Context) {
+	organs, err := h.adminService.GetOrgans()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, organs)
+}
+
+// GetUsers
+//
+//	@Summary		List all users
+//	@Security		BearerAuth
+//	@Description	Platform admins only. Lists every user with their organs.
+//	@Tags			Admin
+//	@Produce		json
+//	@Success		200	{array}		models.User
+//	@Failure		403	{object}	map[string]string
+//	@Router			/admin/users [get]
+func (h *Handler) GetUsers(c *gin.Context) {
+	users, err := h.adminService.GetUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// GetRosters
+//
+//	@Summary		List rosters of all organs
+//	@Security		BearerAuth
+//	@Description	Platform admins only. Lists rosters across organs, newest first.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			organId	query		uint	false	"Organ ID"
+//	@Param			saved	query		bool	false	"Only saved (true) or unsaved (false) rosters"
+//	@Success		200		{array}		models.Roster
+//	@Failure		400		{object}	map[string]string
+//	@Failure		403		{object}	map[string]string
+//	@Router			/admin/rosters [get]
+func (h *Handler) GetRosters(c *gin.Context) {
+	var filters RosterFilterParams
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	rosters, err := h.adminService.GetRosters(&filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rosters)
+}
+
+// GetAuditLogs
+//
+//	@Summary		List the impersonation audit trail
+//	@Security		BearerAuth
+//	@Description	Platform admins only. Lists the requests made while impersonating, newest first.
+//	@Tags			Admin
+//	@Produce		json
+//	@Param			actorId	query		uint	false	"Admin that impersonated"
+//	@Param			userId	query		uint	false	"Impersonated user"
+//	@Success		200		{array}		models.AuditLog
+//	@Failure		400		{object}	map[string]string
+//	@Failure		403		{object}	map[string]string
+//	@Router			/admin/audit-logs [get]
+func (h *Handler) GetAuditLogs(c *gin.Context) {
+	var filters AuditLogFilterParams
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	logs, err := h.adminService.GetAuditLogs(&filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, logs)
+}
+
+// SetMembership
+//
+//	@Summary		Repair a membership
+//	@Security		BearerAuth
+//	@Description	Platform admins only. Adds the user to the organ or changes their role, e.g. when a committee lost its admins.
+//	@Tags			Admin
+//	@Accept			json
+//	@Produce		json
+//	@Param			id			path		uint						true	"Organ ID"
+//	@Param			userId		path		uint						true	"User ID"
+//	@Param			params		body		admin.SetMembershipParams	true	"Role"
+//	@Success		200			{object}	models.UserOrgan
+//	@Failure		400			{object}	map[string]string
+//	@Failure		403			{object}	map[string]string
+//	@Failure		404			{object}	map[string]string
+//	@Router			/admin/organ/{id}/member/{userId} [put]
+func (h *Handler) SetMembership(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Organ ID"})
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid User ID"})
+		return
+	}
+
+	var params SetMembershipParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	membership, err := h.adminService.SetMembership(uint(organID), uint(userID), &params)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Could not find Organ or User"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, membership)
+}
@@ -0,0 +1,127 @@
+package admin
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	GetOrgans() ([]*OrganSummary, error)
+	GetUsers() ([]*models.User, error)
+	GetRosters(filters *RosterFilterParams) ([]*models.Roster, error)
+	GetAuditLogs(filters *AuditLogFilterParams) ([]*models.AuditLog, error)
+	SetMembership(organID uint, userID uint, params *SetMembershipParams) (*models.UserOrgan, error)
+}
+
+type service struct {
+	db *gorm.DB
+}
+
+func NewAdminService(db *gorm.DB) Service {
+	return &service{db: db}
+}
+
+func (s *service) GetOrgans() ([]*OrganSummary, error) {
+	var organs []*OrganSummary
+	err := s.db.Model(&models.Organ{}).
+		Select("organs.*, COUNT(user_organs.user_id) AS member_count").
+		Joins("LEFT JOIN user_organs ON user_organs.organ_id = organs.id AND user_organs.status = ?", models.MembershipActive).
+		Group("organs.id").
+		Order("organs.name ASC").
+		Find(&organs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return organs, nil
+}
+
+func (s *service) GetUsers() ([]*models.User, error) {
+	var users []*models.User
+	if err := s.db.Preload("Organs").Order("name ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+func (s *service) GetRosters(filters *RosterFilterParams) ([]*models.Roster, error) {
+	db := s.db.Model(&models.Roster{}).Preload("Organ")
+
+	if filters != nil {
+		if filters.OrganID != nil {
+			db = db.Where("organ_id = ?", *filters.OrganID)
+		}
+		if filters.Saved != nil {
+			db = db.Where("saved = ?", *filters.Saved)
+		}
+	}
+
+	var rosters []*models.Roster
+	if err := db.Order("date DESC, id DESC").Find(&rosters).Error; err != nil {
+		return nil, err
+	}
+
+	return rosters, nil
+}
+
+func (s *service) GetAuditLogs(filters *AuditLogFilterParams) ([]*models.AuditLog, error) {
+	db := s.db.Model(&models.AuditLog{})
+
+	if filters != nil {
+		if filters.ActorID != nil {
+			db = db.Where("actor_id = ?", *filters.ActorID)
+		}
+		if filters.UserID != nil {
+			db = db.Where("user_id = ?", *filters.UserID)
+		}
+	}
+
+	var logs []*models.AuditLog
+	if err := db.Order("created_at DESC, id DESC").Find(&logs).Error; err != nil {
+		return nil, err
+	}
+
+	return logs, nil
+}
+
+// SetMembership adds the user to the organ or changes their role, e.g. to give
+// a committee without admins a new one. Former members become active again.
+// The role counts as set by hand, so the login sync keeps it.
+func (s *service) SetMembership(organID uint, userID uint, params *SetMembershipParams) (*models.UserOrgan, error) {
+	if err := s.db.First(&models.Organ{}, organID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&models.User{}, userID).Error; err != nil {
+		return nil, err
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.UserOrgan
+		err := tx.Where("organ_id = ? AND user_id = ?", organID, userID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&models.UserOrgan{UserID: userID, OrganID: organID, Role: params.Role, ManualRole: true}).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		updates := existing.StatusChange(models.MembershipActive)
+		updates["role"] = params.Role
+		updates["manual_role"] = true
+		return tx.Model(&models.UserOrgan{}).
+			Where("organ_id = ? AND user_id = ?", organID, userID).
+			Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var updated models.UserOrgan
+	if err := s.db.Where("organ_id = ? AND user_id = ?", organID, userID).First(&updated).Error; err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
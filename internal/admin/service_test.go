@@ -0,0 +1,78 @@
+package admin
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"testing"
+)
+
+type TestAdminSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	service service
+}
+
+func (suite *TestAdminSuite) SetupTest() {
+	db := seeder.Seeder(":memory:")
+	suite.db = db
+	suite.service = service{db: db}
+}
+
+func (suite *TestAdminSuite) TestGetOrgans_CountsMembers() {
+	var organ models.Organ
+	suite.db.First(&organ)
+	var expected int64
+	suite.db.Model(&models.UserOrgan{}).Where("organ_id = ?", organ.ID).Count(&expected)
+
+	organs, err := suite.service.GetOrgans()
+	assert.NoError(suite.T(), err)
+
+	for _, summary := range organs {
+		if summary.ID == organ.ID {
+			assert.Equal(suite.T(), expected, summary.MemberCount)
+			return
+		}
+	}
+	suite.Fail("organ not listed")
+}
+
+func (suite *TestAdminSuite) TestSetMembership_AddsAndUpdates() {
+	var user models.User
+	suite.db.First(&user)
+	suite.db.Where("organ_id = ? AND user_id = ?", 1, user.ID).Delete(&models.UserOrgan{})
+
+	membership, err := suite.service.SetMembership(1, user.ID, &SetMembershipParams{Role: models.RoleAdmin})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.RoleAdmin, membership.Role)
+	assert.True(suite.T(), membership.ManualRole)
+
+	membership, err = suite.service.SetMembership(1, user.ID, &SetMembershipParams{Role: models.RoleOwner})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.RoleOwner, membership.Role)
+
+	_, err = suite.service.SetMembership(999, user.ID, &SetMembershipParams{Role: models.RoleOwner})
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+}
+
+func (suite *TestAdminSuite) TestGetRosters_AcrossOrgans() {
+	suite.db.Create(&models.Roster{Name: "First", OrganID: 1, Values: []string{"yes"}})
+	suite.db.Create(&models.Roster{Name: "Second", OrganID: 2, Values: []string{"yes"}})
+
+	rosters, err := suite.service.GetRosters(nil)
+	assert.NoError(suite.T(), err)
+	assert.GreaterOrEqual(suite.T(), len(rosters), 2)
+
+	organID := uint(2)
+	rosters, err = suite.service.GetRosters(&RosterFilterParams{OrganID: &organID})
+	assert.NoError(suite.T(), err)
+	for _, roster := range rosters {
+		assert.Equal(suite.T(), organID, roster.OrganID)
+	}
+}
+
+func TestAdminService(t *testing.T) {
+	suite.Run(t, new(TestAdminSuite))
+}
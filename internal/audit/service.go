@@ -0,0 +1,22 @@
+package audit
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"gorm.io/gorm"
+)
+
+type Service interface {
+	Record(entry *models.AuditLog) error
+}
+
+type service struct {
+	db *gorm.DB
+}
+
+func NewAuditService(db *gorm.DB) Service {
+	return &service{db: db}
+}
+
+func (s *service) Record(entry *models.AuditLog) error {
+	return s.db.Create(entry).Error
+}
@@ -1,28 +1,57 @@
 package auth
 
 import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
 	"fmt"
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gin-gonic/gin"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/oauth2"
 	"net/http"
+	"net/url"
 	"os"
 )
 
+const refreshCookie = "refresh_token"
+
 type Handler struct {
 	config   *oauth2.Config
 	provider *oidc.Provider
 	service  Service
+
+	// basePath is the path of the auth routes, the refresh token cookie is
+	// limited to it
+	basePath string
+
+	// secureCookie marks the refresh token cookie Secure. It is on unless
+	// COOKIE_SECURE is false, as TLS usually ends at a proxy in front of us.
+	secureCookie bool
 }
 
-func NewAuthHandler(rg *gin.RouterGroup, auth Service, provider *oidc.Provider, config *oauth2.Config) *Handler {
-	h := &Handler{config: config, provider: provider, service: auth}
+func NewAuthHandler(rg *authz.Router, auth Service, provider *oidc.Provider, config *oauth2.Config) *Handler {
+	h := &Handler{
+		config:       config,
+		provider:     provider,
+		service:      auth,
+		basePath:     rg.BasePath(),
+		secureCookie: boolOrDefault("COOKIE_SECURE", true),
+	}
 
 	log.Printf("Path %s", rg.BasePath())
 
-	rg.GET("/redirect", h.AuthRedirect)
-	rg.GET("/callback", h.AuthCallback)
+	rg.GET("/redirect", authz.Public, h.AuthRedirect)
+	rg.GET("/callback", authz.Public, h.AuthCallback)
+	// Token is authorized by the single use code from the login redirect,
+	// refresh and logout by the refresh token cookie, none by an access token
+	rg.POST("/token", authz.Public, h.Token)
+	rg.POST("/refresh", authz.Public, h.Refresh)
+	rg.POST("/logout", authz.Public, h.Logout)
+
+	if devMode() {
+		h.registerDevRoutes(rg)
+	}
 
 	return h
 }
@@ -38,15 +67,8 @@ func NewAuthHandler(rg *gin.RouterGroup, auth Service, provider *oidc.Provider,
 //	@Failure		500		{object}	map[string]string	"pkg server error"
 //	@Router			/auth/redirect [get]
 func (h *Handler) AuthRedirect(c *gin.Context) {
-	if os.Getenv("DEV_TYPE") == "local" {
-		token, err := h.service.HandleLocalAuthentication(c)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-			return
-		}
-
-		redirectUrl := fmt.Sprintf(os.Getenv("FRONTEND_CALLBACK"), token)
-		c.Redirect(http.StatusTemporaryRedirect, redirectUrl)
+	if devMode() {
+		c.Redirect(http.StatusTemporaryRedirect, h.basePath+"/dev/login")
 		return
 	}
 
@@ -56,9 +78,16 @@ func (h *Handler) AuthRedirect(c *gin.Context) {
 		return
 	}
 
+	nonce, err := h.service.RandString(32)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	h.service.SetCallBackCookie(c, state)
+	h.service.SetNonceCookie(c, nonce)
 
-	redirectURL := h.config.AuthCodeURL(state)
+	redirectURL := h.config.AuthCodeURL(state, oidc.Nonce(nonce))
 
 	c.Redirect(http.StatusTemporaryRedirect, redirectURL)
 }
@@ -66,13 +95,14 @@ func (h *Handler) AuthRedirect(c *gin.Context) {
 // AuthCallback
 //
 //	@Summary		Handle OAuth2 Callback
-//	@Description	Validates state, exchanges code for token, and returns user info
+//	@Description	Validates state, verifies the ID token and redirects to FRONTEND_CALLBACK with a single use code for POST /auth/token
 //	@Security		BasicAuth
 //	@Tags			Auth
 //	@Param			state	query		string				true	"State returned from provider"
 //	@Param			code	query		string				true	"Authorization code from provider"
 //	@Success		200		{object}	map[string]string	"User info and token"
-//	@Failure		400		{object}	map[string]string	"Bad request: missing or invalid state"
+//	@Success		303		{string}	string				"redirect"
+//	@Failure		401		{object}	map[string]string	"ID token could not be verified"
 //	@Failure		500		{object}	map[string]string	"Internal server error"
 //	@Router			/auth/callback [get]
 func (h *Handler) AuthCallback(c *gin.Context) {
@@ -87,19 +117,150 @@ func (h *Handler) AuthCallback(c *gin.Context) {
 		return
 	}
 
+	nonce, err := c.Cookie("auth_nonce")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "nonce not found"})
+		return
+	}
+
 	oauth2Token, err := h.config.Exchange(c.Request.Context(), c.Query("code"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not exchange token"})
 		return
 	}
 
-	jwtToken, err := h.service.ProcessUserInfo(oauth2Token)
+	signedIn, err := h.service.ProcessUserInfo(c.Request.Context(), oauth2Token, nonce)
+	if err != nil {
+		var claimErr *ClaimError
+		switch {
+		case errors.Is(err, ErrMissingIDToken), errors.Is(err, ErrInvalidIDToken), errors.Is(err, ErrNonceMismatch):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		case errors.As(err, &claimErr):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": claimErr.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not process user info"})
+		}
+		return
+	}
+
+	h.redirectWithCode(c, signedIn)
+}
 
+// redirectWithCode sends the browser back to the frontend with a single use
+// code for the signed in user.
+func (h *Handler) redirectWithCode(c *gin.Context, signedIn *models.User) {
+	code, err := h.service.CreateAuthCode(signedIn)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not process user info"})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not create authorization code"})
+		return
+	}
+
+	redirectUrl := fmt.Sprintf(os.Getenv("FRONTEND_CALLBACK"), url.QueryEscape(code))
+	c.Redirect(http.StatusSeeOther, redirectUrl)
+}
+
+// TokenRequest
+// @Description Authorization code from the login redirect
+type TokenRequest struct {
+	Code string `json:"code" binding:"required"`
+} // @name TokenRequest
+
+// Token
+//
+//	@Summary		Exchange an authorization code
+//	@Description	Exchanges the single use code from the login redirect for an access token and sets the refresh token cookie. Codes expire after a minute.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			tokenRequest	body		TokenRequest		true	"Authorization code"
+//	@Success		200				{object}	TokenPair
+//	@Failure		400				{object}	map[string]string	"Missing code"
+//	@Failure		401				{object}	map[string]string	"Invalid, expired or used code"
+//	@Failure		500				{object}	map[string]string	"Internal server error"
+//	@Router			/auth/token [post]
+func (h *Handler) Token(c *gin.Context) {
+	var request TokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	redirectUrl := fmt.Sprintf(os.Getenv("FRONTEND_CALLBACK"), jwtToken)
-	c.Redirect(http.StatusTemporaryRedirect, redirectUrl)
+	pair, err := h.service.ExchangeAuthCode(request.Code)
+	if err != nil {
+		if errors.Is(err, ErrInvalidAuthCode) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not exchange code"})
+		return
+	}
+
+	h.setRefreshCookie(c, pair.RefreshToken)
+	c.JSON(http.StatusOK, pair)
+}
+
+// Refresh
+//
+//	@Summary		Refresh the access token
+//	@Description	Exchanges the refresh token cookie for a new access token and rotates the cookie. Reusing a refresh token revokes its session.
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	TokenPair
+//	@Failure		401	{object}	map[string]string	"Missing, invalid or reused refresh token"
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/auth/refresh [post]
+func (h *Handler) Refresh(c *gin.Context) {
+	refreshToken, err := c.Cookie(refreshCookie)
+	if err != nil || refreshToken == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "refresh token not found"})
+		return
+	}
+
+	pair, err := h.service.Refresh(refreshToken)
+	if err != nil {
+		if errors.Is(err, ErrInvalidRefreshToken) || errors.Is(err, ErrRefreshTokenReused) {
+			h.clearRefreshCookie(c)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not refresh token"})
+		return
+	}
+
+	h.setRefreshCookie(c, pair.RefreshToken)
+	c.JSON(http.StatusOK, pair)
+}
+
+// Logout
+//
+//	@Summary		Log out
+//	@Description	Revokes the session of the refresh token cookie and clears the cookie
+//	@Tags			Auth
+//	@Success		204
+//	@Failure		500	{object}	map[string]string	"Internal server error"
+//	@Router			/auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	refreshToken, err := c.Cookie(refreshCookie)
+	if err == nil && refreshToken != "" {
+		err = h.service.Logout(refreshToken)
+		if err != nil && !errors.Is(err, ErrInvalidRefreshToken) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not log out"})
+			return
+		}
+	}
+
+	h.clearRefreshCookie(c)
+	c.Status(http.StatusNoContent)
+}
+
+// setRefreshCookie stores the refresh token in a cookie that is only sent to
+// the auth routes and never along with requests from other sites.
+func (h *Handler) setRefreshCookie(c *gin.Context, value string) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshCookie, value, int(refreshTokenTTL.Seconds()), h.basePath, "", h.secureCookie, true)
+}
+
+func (h *Handler) clearRefreshCookie(c *gin.Context) {
+	c.SetSameSite(http.SameSiteStrictMode)
+	c.SetCookie(refreshCookie, "", -1, h.basePath, "", h.secureCookie, true)
 }
@@ -2,51 +2,96 @@ package auth
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/signing"
 	"GEWIS-Rooster/internal/user"
+	"context"
 	"crypto/rand"
 	"encoding/base64"
-	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/rs/zerolog/log"
 	"golang.org/x/oauth2"
 	"gorm.io/gorm"
 	"io"
-	"os"
 	"strconv"
 	"strings"
-	"time"
 )
 
 type Service interface {
 	SetCallBackCookie(*gin.Context, string)
+	SetNonceCookie(*gin.Context, string)
 	RandString(int) (string, error)
-	ProcessUserInfo(*oauth2.Token) (string, error)
-	GetOrgans(claims map[string]interface{}) ([]models.Organ, error)
-	HandleLocalAuthentication(ctx *gin.Context) (string, error)
-	CreateInternalToken(user *models.User) (string, error)
+	ProcessUserInfo(ctx context.Context, token *oauth2.Token, nonce string) (*models.User, error)
+	GetOrgans(claims map[string]interface{}) ([]OrganClaim, error)
+	DevUsers() ([]DevUser, error)
+	DevLogin(userID uint) (*models.User, error)
+	CreateAuthCode(user *models.User) (string, error)
+	ExchangeAuthCode(code string) (*TokenPair, error)
+	CreateSession(user *models.User) (*TokenPair, error)
+	Refresh(refreshToken string) (*TokenPair, error)
+	Logout(refreshToken string) error
+	RevokeUserSessions(userID uint) (int64, error)
+	CheckSession(sessionID uint) (*models.Session, error)
+	RevokeSession(sessionID uint) error
+	IsPlatformAdmin(userID uint) (bool, error)
+	Impersonate(actorID uint, targetID uint) (*TokenPair, error)
 }
 
 type UserProvider interface {
 	Create(*user.CreateRequest) (*models.User, error)
 }
 
+// IDTokenVerifier verifies ID tokens issued by the identity provider.
+type IDTokenVerifier interface {
+	Verify(ctx context.Context, rawIDToken string) (*oidc.IDToken, error)
+}
+
+var (
+	ErrMissingIDToken = errors.New("token response does not contain an id_token")
+	ErrInvalidIDToken = errors.New("id_token could not be verified")
+	ErrNonceMismatch  = errors.New("id_token nonce does not match the login request")
+)
+
+// ClaimError is returned when a claim the login depends on is missing or has
+// an unexpected format.
+type ClaimError struct {
+	Claim  string
+	Reason string
+}
+
+func (e *ClaimError) Error() string {
+	return fmt.Sprintf("claim %s: %s", e.Claim, e.Reason)
+}
+
 type service struct {
-	u  UserProvider
-	db *gorm.DB
+	u        UserProvider
+	db       *gorm.DB
+	verifier IDTokenVerifier
+	config   Config
+	keys     *signing.KeySet
 }
 
-func NewAuthService(u UserProvider, db *gorm.DB) Service {
-	return &service{u, db}
+func NewAuthService(u UserProvider, db *gorm.DB, verifier IDTokenVerifier, config Config, keys *signing.KeySet) Service {
+	return &service{u, db, verifier, config, keys}
 }
 
 func (s *service) SetCallBackCookie(c *gin.Context, value string) {
+	setAuthCookie(c, "auth_state", value)
+}
+
+// SetNonceCookie stores the nonce sent to the provider, so the callback can
+// check that the ID token was issued for this login request.
+func (s *service) SetNonceCookie(c *gin.Context, value string) {
+	setAuthCookie(c, "auth_nonce", value)
+}
+
+func setAuthCookie(c *gin.Context, name string, value string) {
 	secure := c.Request.TLS != nil // true if HTTPS
 
 	c.SetCookie(
-		"auth_state",
+		name,
 		value,
 		3600,
 		"/", // path
@@ -64,61 +109,26 @@ func (s *service) RandString(nByte int) (string, error) {
 	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
-func (s *service) HandleLocalAuthentication(ctx *gin.Context) (string, error) {
-	var localUser models.User
-	if err := s.db.First(&localUser).Error; err != nil {
-		ctx.JSON(500, gin.H{"error": "No user in local database found, try to seed it first"})
-		return "", err
-	}
-
-	jwtToken, err := s.CreateInternalToken(&localUser)
-
+// ProcessUserInfo verifies the ID token of the provider's token response and
+// signs in the user it belongs to, creating them on their first login.
+func (s *service) ProcessUserInfo(ctx context.Context, OAuth2Token *oauth2.Token, nonce string) (*models.User, error) {
+	claims, err := s.verifyIDToken(ctx, OAuth2Token, nonce)
 	if err != nil {
-		log.Error().Msg(err.Error())
-		return "", err
+		log.Error().Err(err).Msg("Failed to verify ID token")
+		return nil, err
 	}
 
-	ctx.Set("userID", localUser.ID)
-
-	return jwtToken, nil
-}
-
-func (s *service) ProcessUserInfo(OAuth2Token *oauth2.Token) (string, error) {
-	token := OAuth2Token.AccessToken
-	infoString := strings.Split(token, ".")[1]
-
-	// Decode the payload (second part)
-	payloadBytes, err := base64.RawURLEncoding.DecodeString(infoString)
+	id, err := s.userID(claims)
 	if err != nil {
-		log.Error().Msg(err.Error())
-		return "", err
+		log.Error().Err(err).Msg("Failed to read the user ID claim")
+		return nil, err
 	}
 
-	// Unmarshal JSON into a map
-	var claims map[string]interface{}
-	err = json.Unmarshal(payloadBytes, &claims)
+	username, err := stringClaim(claims, s.config.NameClaim)
 	if err != nil {
-		log.Error().Msg(err.Error())
-		return "", err
-	}
-
-	// Extract the id
-	idParts := strings.Split(claims["preferred_username"].(string), "m")
-	if len(idParts) < 2 {
-		log.Error().Msg("Failed to parse preferred_username: missing 'm' prefix")
-		return "", errors.New("invalid username format")
+		return nil, err
 	}
 
-	idStr := idParts[1]
-	idInt, err := strconv.Atoi(idStr)
-	if err != nil {
-		log.Error().Err(err).Str("idStr", idStr).Msg("Failed to convert ID to int")
-		return "", err
-	}
-	username := claims["name"].(string)
-
-	id := uint(idInt)
-
 	var userToProc *models.User
 	err = s.db.Where("gewis_id = ?", id).First(&userToProc).Error
 
@@ -128,28 +138,22 @@ func (s *service) ProcessUserInfo(OAuth2Token *oauth2.Token) (string, error) {
 			userToProc = nil
 		} else {
 			log.Error().Err(err).Uint("id", id).Msg("Database error during direct lookup")
-			return "", err
+			return nil, err
 		}
 	}
 
+	organClaims, err := s.GetOrgans(claims)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to get organs from claims")
+		return nil, err
+	}
+
 	if userToProc == nil {
 		log.Info().Uint("gewis_id", id).Msg("User not found, attempting to create new record")
 
-		organs, organErr := s.GetOrgans(claims)
-		if organErr != nil {
-			log.Error().Err(organErr).Msg("Failed to get organs for new user")
-			return "", organErr
-		}
-
-		organIDs := make([]uint, len(organs))
-		for i, o := range organs {
-			organIDs[i] = o.ID
-		}
-
 		params := user.CreateRequest{
-			Name:     username,
-			GEWISID:  id,
-			OrganIDs: organIDs,
+			Name:    username,
+			GEWISID: id,
 		}
 
 		// 3. Log the creation parameters to see exactly what is being sent to GORM
@@ -158,104 +162,239 @@ func (s *service) ProcessUserInfo(OAuth2Token *oauth2.Token) (string, error) {
 		userToProc, err = s.u.Create(&params)
 		if err != nil {
 			log.Error().Err(err).Uint("attempted_id", id).Msg("User creation failed")
-			return "", err
+			return nil, err
 		}
 		log.Info().Uint("new_user_id", userToProc.ID).Msg("Successfully created new user")
-	} else {
-		organs, organErr := s.GetOrgans(claims)
-		if organErr != nil {
-			log.Error().Msg("Failed to get organs: " + organErr.Error())
-			return "", organErr
-		}
+	}
 
-		if err := s.db.Model(userToProc).Association("OrganIDs").Replace(organs); err != nil {
-			log.Error().Msg("Failed to update user organs: " + err.Error())
-		}
+	if err := s.syncPlatformAdmin(userToProc, claims); err != nil {
+		log.Error().Err(err).Uint("user_id", userToProc.ID).Msg("Failed to sync platform admin")
+		return nil, err
+	}
+
+	if err := s.syncMemberships(userToProc.ID, organClaims); err != nil {
+		log.Error().Err(err).Uint("user_id", userToProc.ID).Msg("Failed to sync organ memberships")
+		return nil, err
+	}
+
+	return userToProc, nil
+}
+
+// verifyIDToken checks the signature, issuer, audience, expiry and nonce of the
+// ID token in the token response and returns its claims.
+func (s *service) verifyIDToken(ctx context.Context, OAuth2Token *oauth2.Token, nonce string) (map[string]interface{}, error) {
+	rawIDToken, ok := OAuth2Token.Extra("id_token").(string)
+	if !ok || rawIDToken == "" {
+		return nil, ErrMissingIDToken
 	}
 
-	jwtToken, err := s.CreateInternalToken(userToProc)
+	if s.verifier == nil {
+		return nil, fmt.Errorf("%w: no verifier configured", ErrInvalidIDToken)
+	}
 
+	idToken, err := s.verifier.Verify(ctx, rawIDToken)
 	if err != nil {
-		log.Error().Msg(err.Error())
-		return "", err
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	if nonce == "" || idToken.Nonce != nonce {
+		return nil, ErrNonceMismatch
 	}
 
-	return jwtToken, nil
+	var claims map[string]interface{}
+	if err := idToken.Claims(&claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidIDToken, err)
+	}
+
+	return claims, nil
 }
 
-func (s *service) GetOrgans(claims map[string]interface{}) ([]models.Organ, error) {
-	resourceAccess, ok := claims["resource_access"].(map[string]interface{})
-	if !ok {
-		log.Debug().Interface("available_claims", claims).Msg("resource_access missing")
-		return nil, fmt.Errorf("resource_access not found or wrong type")
+func stringClaim(claims map[string]interface{}, name string) (string, error) {
+	value, exists := claims[name]
+	if !exists {
+		return "", &ClaimError{Claim: name, Reason: "missing"}
 	}
 
-	envType := os.Getenv("RESOURCE_ENV_TYPE")
-	key := fmt.Sprintf("grooster-%s", envType)
+	str, ok := value.(string)
+	if !ok || str == "" {
+		return "", &ClaimError{Claim: name, Reason: "not a non-empty string"}
+	}
 
-	OICDName, ok := resourceAccess[key].(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("grooster-test not found or wrong type")
+	return str, nil
+}
+
+// OrganClaim is an organ membership granted by the identity provider.
+type OrganClaim struct {
+	Organ models.Organ
+
+	Role models.OrganRole
+}
+
+// GetOrgans returns the organ memberships named by the organ roles in the
+// claims, creating organs that do not exist yet. A role is either "<organ>",
+// which grants membership, or "<role>:<organ>" with role one of owner, admin
+// or member. An organ named more than once gets the highest of its roles.
+// Archived organs are skipped.
+func (s *service) GetOrgans(claims map[string]interface{}) ([]OrganClaim, error) {
+	roles, err := s.config.organRoles(claims)
+	if err != nil {
+		log.Debug().Interface("available_claims", claims).Msg("organ roles missing")
+		return nil, err
 	}
 
-	roles, ok := OICDName["roles"].([]interface{})
-	if !ok {
-		return nil, fmt.Errorf("roles not found or wrong type")
+	var organClaims []OrganClaim
+	index := make(map[string]int)
+
+	for _, roleStr := range roles {
+		if s.config.PlatformAdminRole != "" && roleStr == s.config.PlatformAdminRole {
+			continue
+		}
+
+		organString, ok := strings.CutPrefix(roleStr, s.config.OrganRolePrefix)
+		if !ok || organString == "" {
+			continue
+		}
+
+		role := models.RoleMember
+		if roleName, organName, found := strings.Cut(organString, ":"); found {
+			if _, valid := models.RoleWeights[models.OrganRole(roleName)]; valid && organName != "" {
+				role = models.OrganRole(roleName)
+				organString = organName
+			}
+		}
+
+		if i, seen := index[organString]; seen {
+			if models.RoleWeights[role] > models.RoleWeights[organClaims[i].Role] {
+				organClaims[i].Role = role
+			}
+			continue
+		}
+
+		organToGet := models.Organ{
+			Name: organString,
+		}
+		if err := s.db.FirstOrCreate(&organToGet, models.Organ{Name: organString}).Error; err != nil {
+			return nil, err
+		}
+
+		// Dissolved organs no longer gain members through the identity provider
+		if organToGet.ArchivedAt != nil {
+			continue
+		}
+
+		index[organString] = len(organClaims)
+		organClaims = append(organClaims, OrganClaim{Organ: organToGet, Role: role})
 	}
 
-	var organs []models.Organ
+	return organClaims, nil
+}
 
-	separator := envType + " "
+// syncMemberships makes the organ memberships of the user match the claims.
+// Memberships that are no longer claimed become alumni, and alumni that are
+// claimed again become active. When manual roles are kept, memberships whose
+// role was set by an admin keep that role and do not become alumni.
+func (s *service) syncMemberships(userID uint, organClaims []OrganClaim) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var memberships []models.UserOrgan
+		if err := tx.Where("user_id = ?", userID).Find(&memberships).Error; err != nil {
+			return err
+		}
 
-	for _, role := range roles {
-		if roleStr, ok := role.(string); ok {
-			if strings.Contains(roleStr, separator) {
+		existing := make(map[uint]models.UserOrgan, len(memberships))
+		for _, membership := range memberships {
+			existing[membership.OrganID] = membership
+		}
 
-				parts := strings.SplitN(roleStr, separator, 2)
+		for _, claim := range organClaims {
+			membership, found := existing[claim.Organ.ID]
+			delete(existing, claim.Organ.ID)
 
-				if len(parts) > 1 && parts[1] != "" {
-					organString := parts[1]
+			if !found {
+				membership = models.UserOrgan{UserID: userID, OrganID: claim.Organ.ID, Role: claim.Role}
+				if err := tx.Create(&membership).Error; err != nil {
+					return err
+				}
+				continue
+			}
 
-					organToGet := models.Organ{
-						Name: organString,
-					}
-					s.db.FirstOrCreate(&organToGet, models.Organ{Name: organString})
-					organs = append(organs, organToGet)
+			// Members who left are claimed again, so they rejoin the organ
+			if membership.Status == models.MembershipAlumni {
+				updates := membership.StatusChange(models.MembershipActive)
+				updates["role"] = claim.Role
+				updates["manual_role"] = false
+
+				err := tx.Model(&models.UserOrgan{}).
+					Where("user_id = ? AND organ_id = ?", userID, claim.Organ.ID).
+					Updates(updates).Error
+				if err != nil {
+					return err
+				}
+				continue
+			}
+
+			if membership.ManualRole && s.config.KeepManualRoles {
+				continue
+			}
+
+			if membership.Role != claim.Role || membership.ManualRole {
+				err := tx.Model(&models.UserOrgan{}).
+					Where("user_id = ? AND organ_id = ?", userID, claim.Organ.ID).
+					Updates(map[string]interface{}{"role": claim.Role, "manual_role": false}).Error
+				if err != nil {
+					return err
 				}
 			}
 		}
-	}
 
-	return organs, nil
+		for organID, membership := range existing {
+			if membership.Status == models.MembershipAlumni {
+				continue
+			}
+			if membership.ManualRole && s.config.KeepManualRoles {
+				continue
+			}
+
+			err := tx.Model(&models.UserOrgan{}).
+				Where("user_id = ? AND organ_id = ?", userID, organID).
+				Updates(membership.StatusChange(models.MembershipAlumni)).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
-func (s *service) CreateInternalToken(user *models.User) (string, error) {
-	now := time.Now()
+// userID reads the external user ID from the configured claim. String claims
+// have the configured prefix stripped before they are parsed.
+func (s *service) userID(claims map[string]interface{}) (uint, error) {
+	claim := s.config.UserIDClaim
 
-	var userOrgans []models.UserOrgan
-	if err := s.db.Where("user_id = ?", user.ID).Find(&userOrgans).Error; err != nil {
-		return "", err
+	switch value := claims[claim].(type) {
+	case float64:
+		if value < 1 || value != float64(uint32(value)) {
+			return 0, &ClaimError{Claim: claim, Reason: "not a valid ID"}
+		}
+		return uint(value), nil
+	case nil:
+		return 0, &ClaimError{Claim: claim, Reason: "missing"}
 	}
 
-	roles := make(map[uint]string)
-	for _, uo := range userOrgans {
-		roles[uo.OrganID] = string(uo.Role)
+	str, err := stringClaim(claims, claim)
+	if err != nil {
+		return 0, err
 	}
 
-	claims := jwt.MapClaims{
-		"sub":    user.GEWISID,
-		"name":   user.Name,
-		"organs": roles,
-		"iat":    now.Unix(),
-		"exp":    now.Add(7 * 24 * time.Hour).Unix(),
+	idStr, ok := strings.CutPrefix(str, s.config.UserIDPrefix)
+	if !ok {
+		return 0, &ClaimError{Claim: claim, Reason: fmt.Sprintf("missing %q prefix", s.config.UserIDPrefix)}
 	}
 
-	secret := os.Getenv("JWT_SECRET")
-	if strings.TrimSpace(secret) == "" {
-		log.Error().Msg("JWT_SECRET environment variable is not set or empty")
-		return "", fmt.Errorf("JWT_SECRET environment variable is not set or empty")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil || id == 0 {
+		return 0, &ClaimError{Claim: claim, Reason: "not a valid ID"}
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	return token.SignedString([]byte(secret))
+	return uint(id), nil
 }
@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/signing"
+	"GEWIS-Rooster/internal/user"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"github.com/coreos/go-oidc/v3/oidc"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"golang.org/x/oauth2"
+	"gorm.io/gorm"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const testClientID = "grooster-test"
+
+// testIssuer is a minimal stand-in for the identity provider. It serves the
+// discovery document and key set, and signs ID tokens with its own key.
+type testIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	issuer := &testIssuer{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"issuer":                                issuer.server.URL,
+			"authorization_endpoint":                issuer.server.URL + "/auth",
+			"token_endpoint":                        issuer.server.URL + "/token",
+			"jwks_uri":                              issuer.server.URL + "/keys",
+			"id_token_signing_alg_values_supported": []string{"RS256"},
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "test",
+				"alg": "RS256",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	})
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (i *testIssuer) verifier(t *testing.T) *oidc.IDTokenVerifier {
+	provider, err := oidc.NewProvider(context.Background(), i.server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return provider.Verifier(&oidc.Config{ClientID: testClientID})
+}
+
+// token returns a token response with an ID token signed by the issuer. The
+// overrides replace or, when nil, remove the default claims.
+func (i *testIssuer) token(t *testing.T, overrides jwt.MapClaims) *oauth2.Token {
+	claims := jwt.MapClaims{
+		"iss":                i.server.URL,
+		"aud":                testClientID,
+		"sub":                "subject",
+		"exp":                time.Now().Add(time.Hour).Unix(),
+		"iat":                time.Now().Unix(),
+		"nonce":              "nonce",
+		"preferred_username": "m4242",
+		"name":               "Test Member",
+		"resource_access": map[string]interface{}{
+			"grooster-test": map[string]interface{}{"roles": []string{"test BAC"}},
+		},
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+
+	idToken := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	idToken.Header["kid"] = "test"
+	raw, err := idToken.SignedString(i.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := &oauth2.Token{AccessToken: "opaque"}
+	return token.WithExtra(map[string]interface{}{"id_token": raw})
+}
+
+type TestAuthSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	issuer  *testIssuer
+	service service
+}
+
+func (suite *TestAuthSuite) SetupTest() {
+	suite.T().Setenv("JWT_SECRET", "test-secret")
+	suite.T().Setenv("RESOURCE_ENV_TYPE", "test")
+
+	db := seeder.Seeder(":memory:")
+	suite.db = db
+	suite.issuer = newTestIssuer(suite.T())
+	keys, err := signing.LoadFromEnv()
+	suite.Require().NoError(err)
+	suite.service = service{
+		u:        user.NewUserService(db),
+		db:       db,
+		verifier: suite.issuer.verifier(suite.T()),
+		config:   ConfigFromEnv(),
+		keys:     keys,
+	}
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_Valid() {
+	token, err := suite.service.ProcessUserInfo(context.Background(), suite.issuer.token(suite.T(), nil), "nonce")
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), token)
+
+	var created models.User
+	assert.NoError(suite.T(), suite.db.Where("gewis_id = ?", 4242).First(&created).Error)
+	assert.Equal(suite.T(), "Test Member", created.Name)
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_MissingIDToken() {
+	_, err := suite.service.ProcessUserInfo(context.Background(), &oauth2.Token{AccessToken: "opaque"}, "nonce")
+	assert.ErrorIs(suite.T(), err, ErrMissingIDToken)
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_NonceMismatch() {
+	_, err := suite.service.ProcessUserInfo(context.Background(), suite.issuer.token(suite.T(), nil), "other")
+	assert.ErrorIs(suite.T(), err, ErrNonceMismatch)
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_InvalidToken() {
+	cases := map[string]jwt.MapClaims{
+		"wrong audience": {"aud": "another-client"},
+		"wrong issuer":   {"iss": "https://issuer.invalid"},
+		"expired":        {"exp": time.Now().Add(-time.Hour).Unix()},
+	}
+
+	for name, overrides := range cases {
+		_, err := suite.service.ProcessUserInfo(context.Background(), suite.issuer.token(suite.T(), overrides), "nonce")
+		assert.ErrorIs(suite.T(), err, ErrInvalidIDToken, name)
+	}
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_ForgedSignature() {
+	forger := newTestIssuer(suite.T())
+	token := forger.token(suite.T(), jwt.MapClaims{"iss": suite.issuer.server.URL})
+
+	_, err := suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.ErrorIs(suite.T(), err, ErrInvalidIDToken)
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_MissingClaims() {
+	cases := map[string]jwt.MapClaims{
+		"preferred_username": {"preferred_username": nil},
+		"name":               {"name": 42},
+	}
+
+	for claim, overrides := range cases {
+		_, err := suite.service.ProcessUserInfo(context.Background(), suite.issuer.token(suite.T(), overrides), "nonce")
+
+		var claimErr *ClaimError
+		if assert.True(suite.T(), errors.As(err, &claimErr), claim) {
+			assert.Equal(suite.T(), claim, claimErr.Claim)
+		}
+	}
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_CustomClaimMapping() {
+	suite.service.config = Config{
+		UserIDClaim:     "member_number",
+		NameClaim:       "given_name",
+		OrganClaimPath:  "groups",
+		OrganRolePrefix: "grooster:",
+	}
+
+	token := suite.issuer.token(suite.T(), jwt.MapClaims{
+		"member_number": 5151,
+		"given_name":    "Sister Member",
+		"groups":        []string{"grooster:Board", "unrelated"},
+	})
+
+	_, err := suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	var created models.User
+	assert.NoError(suite.T(), suite.db.Preload("Organs").Where("gewis_id = ?", 5151).First(&created).Error)
+	assert.Equal(suite.T(), "Sister Member", created.Name)
+	if assert.Len(suite.T(), created.Organs, 1) {
+		assert.Equal(suite.T(), "Board", created.Organs[0].Name)
+	}
+}
+
+func (suite *TestAuthSuite) TestConfigFromEnv_Defaults() {
+	config := ConfigFromEnv()
+	assert.Equal(suite.T(), defaultIssuer, config.Issuer)
+	assert.Equal(suite.T(), "preferred_username", config.UserIDClaim)
+	assert.Equal(suite.T(), "m", config.UserIDPrefix)
+	assert.Equal(suite.T(), "resource_access.grooster-test.roles", config.OrganClaimPath)
+	assert.Equal(suite.T(), "test ", config.OrganRolePrefix)
+
+	suite.T().Setenv("OIDC_ISSUER", "https://keycloak.example.org/realms/staging")
+	suite.T().Setenv("OIDC_USER_ID_PREFIX", "")
+	config = ConfigFromEnv()
+	assert.Equal(suite.T(), "https://keycloak.example.org/realms/staging", config.Issuer)
+	assert.Empty(suite.T(), config.UserIDPrefix)
+}
+
+func (suite *TestAuthSuite) organRoles(roles ...string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"resource_access": map[string]interface{}{
+			"grooster-test": map[string]interface{}{"roles": roles},
+		},
+	}
+}
+
+func (suite *TestAuthSuite) memberships() map[string]models.UserOrgan {
+	var created models.User
+	suite.db.Where("gewis_id = ?", 4242).First(&created)
+
+	var memberships []models.UserOrgan
+	suite.db.Where("user_id = ?", created.ID).Find(&memberships)
+
+	byName := make(map[string]models.UserOrgan)
+	for _, membership := range memberships {
+		var organ models.Organ
+		suite.db.First(&organ, membership.OrganID)
+		byName[organ.Name] = membership
+	}
+	return byName
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_SyncsRoles() {
+	token := suite.issuer.token(suite.T(), suite.organRoles("test admin:BAC", "test Board", "test member:Board"))
+	_, err := suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	memberships := suite.memberships()
+	assert.Len(suite.T(), memberships, 2)
+	assert.Equal(suite.T(), models.RoleAdmin, memberships["BAC"].Role)
+	assert.Equal(suite.T(), models.RoleMember, memberships["Board"].Role)
+
+	// Logging in again with other claims updates roles and drops old memberships
+	token = suite.issuer.token(suite.T(), suite.organRoles("test member:BAC", "test owner:Board"))
+	_, err = suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	memberships = suite.memberships()
+	assert.Len(suite.T(), memberships, 2)
+	assert.Equal(suite.T(), models.RoleMember, memberships["BAC"].Role)
+	assert.Equal(suite.T(), models.RoleOwner, memberships["Board"].Role)
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_ManualRoles() {
+	token := suite.issuer.token(suite.T(), suite.organRoles("test BAC", "test Board"))
+	_, err := suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	for _, membership := range suite.memberships() {
+		suite.db.Model(&models.UserOrgan{}).
+			Where("user_id = ? AND organ_id = ?", membership.UserID, membership.OrganID).
+			Updates(map[string]interface{}{"role": models.RoleAdmin, "manual_role": true})
+	}
+
+	suite.service.config.KeepManualRoles = true
+	token = suite.issuer.token(suite.T(), suite.organRoles("test BAC"))
+	_, err = suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	memberships := suite.memberships()
+	assert.Len(suite.T(), memberships, 2)
+	assert.Equal(suite.T(), models.RoleAdmin, memberships["BAC"].Role)
+	assert.Equal(suite.T(), models.RoleAdmin, memberships["Board"].Role)
+
+	suite.service.config.KeepManualRoles = false
+	_, err = suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	memberships = suite.memberships()
+	assert.Len(suite.T(), memberships, 2)
+	assert.Equal(suite.T(), models.RoleMember, memberships["BAC"].Role)
+	assert.False(suite.T(), memberships["BAC"].ManualRole)
+	assert.Equal(suite.T(), models.MembershipAlumni, memberships["Board"].Status)
+	assert.NotNil(suite.T(), memberships["Board"].ActiveUntil)
+
+	// Claiming the organ again makes the alumnus active again
+	token = suite.issuer.token(suite.T(), suite.organRoles("test BAC", "test Board"))
+	_, err = suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	memberships = suite.memberships()
+	assert.Equal(suite.T(), models.MembershipActive, memberships["Board"].Status)
+	assert.Nil(suite.T(), memberships["Board"].ActiveUntil)
+}
+
+func (suite *TestAuthSuite) TestProcessUserInfo_SkipsArchivedOrgans() {
+	now := time.Now()
+	suite.db.Create(&models.Organ{Name: "Dissolved", ArchivedAt: &now})
+
+	token := suite.issuer.token(suite.T(), suite.organRoles("test BAC", "test Dissolved"))
+	_, err := suite.service.ProcessUserInfo(context.Background(), token, "nonce")
+	assert.NoError(suite.T(), err)
+
+	memberships := suite.memberships()
+	assert.Len(suite.T(), memberships, 1)
+	assert.Contains(suite.T(), memberships, "BAC")
+}
+
+func TestAuthService(t *testing.T) {
+	suite.Run(t, new(TestAuthSuite))
+}
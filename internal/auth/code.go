@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+const authCodeTTL = time.Minute
+
+var ErrInvalidAuthCode = errors.New("authorization code is invalid, expired or already used")
+
+// CreateAuthCode returns a single use code for the user, which the frontend
+// exchanges for tokens. The code is put in the redirect URL instead of the
+// tokens themselves, so these do not end up in browser history or logs.
+func (s *service) CreateAuthCode(user *models.User) (string, error) {
+	code, err := s.RandString(32)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Codes are useless after they expire, clean them up as we go
+		if err := tx.Where("expires_at < ?", time.Now()).Delete(&models.AuthCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.AuthCode{
+			UserID:    user.ID,
+			CodeHash:  hashToken(code),
+			ExpiresAt: time.Now().Add(authCodeTTL),
+		}).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return code, nil
+}
+
+// ExchangeAuthCode redeems the code and starts a session for its user.
+func (s *service) ExchangeAuthCode(code string) (*TokenPair, error) {
+	var authCode models.AuthCode
+	err := s.db.Preload("User").Where("code_hash = ?", hashToken(code)).First(&authCode).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAuthCode
+		}
+		return nil, err
+	}
+
+	if authCode.UsedAt != nil || time.Now().After(authCode.ExpiresAt) {
+		return nil, ErrInvalidAuthCode
+	}
+
+	result := s.db.Model(&models.AuthCode{}).
+		Where("id = ? AND used_at IS NULL", authCode.ID).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, ErrInvalidAuthCode
+	}
+
+	return s.CreateSession(authCode.User)
+}
@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"github.com/stretchr/testify/assert"
+	"time"
+)
+
+func (suite *TestAuthSuite) TestExchangeAuthCode_SingleUse() {
+	var member models.User
+	suite.db.First(&member)
+
+	code, err := suite.service.CreateAuthCode(&member)
+	suite.Require().NoError(err)
+
+	var stored models.AuthCode
+	assert.NoError(suite.T(), suite.db.First(&stored).Error)
+	assert.NotEqual(suite.T(), code, stored.CodeHash)
+
+	pair, err := suite.service.ExchangeAuthCode(code)
+	assert.NoError(suite.T(), err)
+	assert.NotEmpty(suite.T(), pair.AccessToken)
+	assert.NotEmpty(suite.T(), pair.RefreshToken)
+
+	_, err = suite.service.ExchangeAuthCode(code)
+	assert.ErrorIs(suite.T(), err, ErrInvalidAuthCode)
+}
+
+func (suite *TestAuthSuite) TestExchangeAuthCode_Invalid() {
+	_, err := suite.service.ExchangeAuthCode("unknown")
+	assert.ErrorIs(suite.T(), err, ErrInvalidAuthCode)
+
+	var member models.User
+	suite.db.First(&member)
+	code, err := suite.service.CreateAuthCode(&member)
+	suite.Require().NoError(err)
+
+	suite.db.Model(&models.AuthCode{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Second))
+	_, err = suite.service.ExchangeAuthCode(code)
+	assert.ErrorIs(suite.T(), err, ErrInvalidAuthCode)
+}
@@ -0,0 +1,134 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const defaultIssuer = "https://auth.gewis.nl/realms/GEWISWG"
+
+// Config describes the identity provider and how its claims map onto users
+// and organs.
+type Config struct {
+	// Issuer is the URL of the OIDC provider
+	Issuer string
+
+	// UserIDClaim holds the external ID of the user, stored as the GEWIS ID
+	UserIDClaim string
+
+	// UserIDPrefix is stripped from the user ID claim before it is parsed as a
+	// number, e.g. the "m" of GEWIS membership numbers
+	UserIDPrefix string
+
+	// NameClaim holds the display name of the user
+	NameClaim string
+
+	// OrganClaimPath is the dot separated path to the list of roles that grant
+	// organ membership
+	OrganClaimPath string
+
+	// OrganRolePrefix marks the roles in that list that name an organ, the rest
+	// of the role is the organ name
+	OrganRolePrefix string
+
+	// KeepManualRoles keeps roles that admins set by hand when the claims
+	// change, instead of letting the identity provider overwrite them
+	KeepManualRoles bool
+
+	// PlatformAdminIDs are the GEWIS IDs of the platform admins
+	PlatformAdminIDs []uint
+
+	// PlatformAdminRole is a role in the organ role list that makes the user a
+	// platform admin, disabled when empty
+	PlatformAdminRole string
+}
+
+// ConfigFromEnv reads the OIDC_* variables. Unset variables fall back to the
+// GEWIS Keycloak realm, where organs are the roles "<env> <organ>" of the
+// client "grooster-<env>" and <env> is RESOURCE_ENV_TYPE.
+func ConfigFromEnv() Config {
+	envType := os.Getenv("RESOURCE_ENV_TYPE")
+
+	return Config{
+		Issuer:          envOrDefault("OIDC_ISSUER", defaultIssuer),
+		UserIDClaim:     envOrDefault("OIDC_USER_ID_CLAIM", "preferred_username"),
+		UserIDPrefix:    prefixOrDefault("OIDC_USER_ID_PREFIX", "m"),
+		NameClaim:       envOrDefault("OIDC_NAME_CLAIM", "name"),
+		OrganClaimPath:  envOrDefault("OIDC_ORGAN_CLAIM_PATH", fmt.Sprintf("resource_access.grooster-%s.roles", envType)),
+		OrganRolePrefix: prefixOrDefault("OIDC_ORGAN_ROLE_PREFIX", envType+" "),
+		KeepManualRoles: boolOrDefault("OIDC_KEEP_MANUAL_ROLES", true),
+
+		PlatformAdminIDs:  idList("PLATFORM_ADMIN_IDS"),
+		PlatformAdminRole: os.Getenv("OIDC_PLATFORM_ADMIN_ROLE"),
+	}
+}
+
+func envOrDefault(key string, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// prefixOrDefault only falls back when the variable is unset, as an empty
+// prefix is a valid setting.
+func prefixOrDefault(key string, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok {
+		return value
+	}
+	return fallback
+}
+
+func boolOrDefault(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// idList reads a comma separated list of IDs, skipping entries that are not
+// numbers.
+func idList(key string) []uint {
+	var ids []uint
+	for _, field := range strings.Split(os.Getenv(key), ",") {
+		id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 32)
+		if err == nil && id > 0 {
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids
+}
+
+// organRoles follows OrganClaimPath through the claims and returns the roles
+// found at its end.
+func (c Config) organRoles(claims map[string]interface{}) ([]string, error) {
+	var current interface{} = claims
+	for _, key := range strings.Split(c.OrganClaimPath, ".") {
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, &ClaimError{Claim: c.OrganClaimPath, Reason: "not an object at " + key}
+		}
+
+		current, ok = object[key]
+		if !ok {
+			return nil, &ClaimError{Claim: c.OrganClaimPath, Reason: "missing " + key}
+		}
+	}
+
+	list, ok := current.([]interface{})
+	if !ok {
+		return nil, &ClaimError{Claim: c.OrganClaimPath, Reason: "not a list"}
+	}
+
+	roles := make([]string, 0, len(list))
+	for _, role := range list {
+		if roleStr, ok := role.(string); ok {
+			roles = append(roles, roleStr)
+		}
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,90 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"os"
+)
+
+// DevUser is a user offered by the local development login.
+type DevUser struct {
+	ID uint `json:"id"`
+
+	Name string `json:"name"`
+
+	GEWISID *uint `json:"gewisId"`
+
+	Organs []DevUserOrgan `json:"organs"`
+} // @name DevUser
+
+type DevUserOrgan struct {
+	OrganID uint `json:"organId"`
+
+	Name string `json:"name"`
+
+	Role models.OrganRole `json:"role"`
+} // @name DevUserOrgan
+
+// devMode reports whether the server runs for local development, without the
+// identity provider.
+func devMode() bool {
+	return os.Getenv("DEV_TYPE") == "local"
+}
+
+// DevUsers lists all users with their organ roles, to pick one to log in as.
+func (s *service) DevUsers() ([]DevUser, error) {
+	var users []models.User
+	if err := s.db.Where("anonymized_at IS NULL AND guest = ?", false).Order("id ASC").Find(&users).Error; err != nil {
+		return nil, err
+	}
+
+	var memberships []struct {
+		models.UserOrgan
+		OrganName string
+	}
+	err := s.db.Model(&models.UserOrgan{}).
+		Select("user_organs.*, organs.name AS organ_name").
+		Joins("JOIN organs ON organs.id = user_organs.organ_id").
+		Scopes(models.ActiveMembers).
+		Order("organs.name ASC").
+		Find(&memberships).Error
+	if err != nil {
+		return nil, err
+	}
+
+	organs := make(map[uint][]DevUserOrgan)
+	for _, membership := range memberships {
+		organs[membership.UserID] = append(organs[membership.UserID], DevUserOrgan{
+			OrganID: membership.OrganID,
+			Name:    membership.OrganName,
+			Role:    membership.Role,
+		})
+	}
+
+	devUsers := make([]DevUser, 0, len(users))
+	for _, u := range users {
+		devUsers = append(devUsers, DevUser{
+			ID:      u.ID,
+			Name:    u.Name,
+			GEWISID: u.GEWISID,
+			Organs:  organs[u.ID],
+		})
+	}
+
+	return devUsers, nil
+}
+
+// DevLogin returns the user to log in as, skipping the identity provider.
+// Guests and anonymised users are refused, like they are by the real login.
+func (s *service) DevLogin(userID uint) (*models.User, error) {
+	var devUser models.User
+	if err := s.db.First(&devUser, userID).Error; err != nil {
+		return nil, err
+	}
+	if devUser.Guest {
+		return nil, ErrGuestUser
+	}
+	if devUser.AnonymizedAt != nil {
+		return nil, ErrUserAnonymized
+	}
+	return &devUser, nil
+}
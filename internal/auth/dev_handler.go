@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"html/template"
+	"net/http"
+)
+
+var devLoginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><title>GRooster local login</title></head>
+<body>
+<h1>Log in as</h1>
+<table>
+{{range .}}
+<tr>
+<td>{{.Name}} ({{.GEWISID}})</td>
+<td>{{range .Organs}}{{.Name}}: {{.Role}}<br>{{end}}</td>
+<td><form method="post"><input type="hidden" name="userId" value="{{.ID}}"><button>Log in</button></form></td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// DevLoginRequest
+// @Description User to log in as
+type DevLoginRequest struct {
+	UserID uint `json:"userId" form:"userId" binding:"required"`
+} // @name DevLoginRequest
+
+// registerDevRoutes adds the local development login. The routes only exist
+// when DEV_TYPE is local.
+func (h *Handler) registerDevRoutes(rg *authz.Router) {
+	rg.GET("/dev/users", authz.Public, h.DevUsers)
+	rg.GET("/dev/login", authz.Public, h.DevLoginPage)
+	rg.POST("/dev/login", authz.Public, h.DevLogin)
+}
+
+// DevUsers
+//
+//	@Summary		List users to log in as
+//	@Description	Only available when DEV_TYPE is local. Lists all users with their organ roles.
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{array}		DevUser
+//	@Failure		500	{object}	map[string]string
+//	@Router			/auth/dev/users [get]
+func (h *Handler) DevUsers(c *gin.Context) {
+	users, err := h.service.DevUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, users)
+}
+
+// DevLoginPage
+//
+//	@Summary		Local login page
+//	@Description	Only available when DEV_TYPE is local. Shows the users to log in as.
+//	@Tags			Auth
+//	@Produce		html
+//	@Success		200	{string}	string
+//	@Router			/auth/dev/login [get]
+func (h *Handler) DevLoginPage(c *gin.Context) {
+	users, err := h.service.DevUsers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusOK)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+	if err := devLoginPage.Execute(c.Writer, users); err != nil {
+		_ = c.Error(err)
+	}
+}
+
+// DevLogin
+//
+//	@Summary		Log in as any user
+//	@Description	Only available when DEV_TYPE is local. A JSON request returns the tokens directly, the form of the login page redirects to FRONTEND_CALLBACK with a code like the real login.
+//	@Tags			Auth
+//	@Accept			json
+//	@Produce		json
+//	@Param			loginRequest	body		DevLoginRequest		true	"User to log in as"
+//	@Success		200				{object}	TokenPair
+//	@Success		303				{string}	string				"redirect"
+//	@Failure		400				{object}	map[string]string
+//	@Failure		404				{object}	map[string]string
+//	@Router			/auth/dev/login [post]
+func (h *Handler) DevLogin(c *gin.Context) {
+	var request DevLoginRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	devUser, err := h.service.DevLogin(request.UserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		case errors.Is(err, ErrGuestUser), errors.Is(err, ErrUserAnonymized):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		}
+		return
+	}
+
+	if c.ContentType() != gin.MIMEJSON {
+		h.redirectWithCode(c, devUser)
+		return
+	}
+
+	pair, err := h.service.CreateSession(devUser)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.setRefreshCookie(c, pair.RefreshToken)
+	c.JSON(http.StatusOK, pair)
+}
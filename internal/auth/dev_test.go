@@ -0,0 +1,146 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"bytes"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func (suite *TestAuthSuite) devRouter() *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	NewAuthHandler(authz.NewRouter(r.Group("/auth"), suite.db, authz.NewRegistry()), &suite.service, nil, nil)
+	return r
+}
+
+func (suite *TestAuthSuite) TestDevUsers_ListsRoles() {
+	var membership models.UserOrgan
+	suite.db.First(&membership)
+	suite.db.Model(&models.UserOrgan{}).
+		Where("user_id = ? AND organ_id = ?", membership.UserID, membership.OrganID).
+		Update("role", models.RoleOwner)
+
+	users, err := suite.service.DevUsers()
+	assert.NoError(suite.T(), err)
+
+	for _, devUser := range users {
+		if devUser.ID != membership.UserID {
+			continue
+		}
+		for _, organ := range devUser.Organs {
+			if organ.OrganID == membership.OrganID {
+				assert.Equal(suite.T(), models.RoleOwner, organ.Role)
+				assert.NotEmpty(suite.T(), organ.Name)
+				return
+			}
+		}
+	}
+	suite.Fail("membership not listed")
+}
+
+func (suite *TestAuthSuite) TestDevRoutes_OnlyLocal() {
+	suite.T().Setenv("DEV_TYPE", "")
+	w := httptest.NewRecorder()
+	suite.devRouter().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/dev/users", nil))
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+}
+
+func (suite *TestAuthSuite) TestDevLogin_AsChosenUser() {
+	suite.T().Setenv("DEV_TYPE", "local")
+	suite.T().Setenv("FRONTEND_CALLBACK", "http://frontend/callback?code=%s")
+	r := suite.devRouter()
+
+	var chosen models.User
+	suite.db.Order("id DESC").First(&chosen)
+
+	body, _ := json.Marshal(DevLoginRequest{UserID: chosen.ID})
+	req := httptest.NewRequest(http.MethodPost, "/auth/dev/login", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	var pair TokenPair
+	assert.NoError(suite.T(), json.Unmarshal(w.Body.Bytes(), &pair))
+	var session models.Session
+	assert.NoError(suite.T(), suite.db.First(&session, suite.sessionID(&pair)).Error)
+	assert.Equal(suite.T(), chosen.ID, session.UserID)
+
+	// The login page form goes through the code exchange like the real login
+	form := url.Values{"userId": {"1"}}
+	req = httptest.NewRequest(http.MethodPost, "/auth/dev/login", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(suite.T(), http.StatusSeeOther, w.Code)
+	assert.True(suite.T(), strings.HasPrefix(w.Header().Get("Location"), "http://frontend/callback?code="))
+}
+
+func (suite *TestAuthSuite) TestDevLogin_RefusesGuestsAndAnonymised() {
+	suite.T().Setenv("DEV_TYPE", "local")
+	r := suite.devRouter()
+
+	guest := models.User{Name: "Guest", Guest: true}
+	suite.Require().NoError(suite.db.Create(&guest).Error)
+	now := time.Now()
+	anonymised := models.User{Name: "Anonymous", AnonymizedAt: &now}
+	suite.Require().NoError(suite.db.Create(&anonymised).Error)
+
+	cases := map[uint]int{
+		guest.ID:      http.StatusBadRequest,
+		anonymised.ID: http.StatusBadRequest,
+		999999:        http.StatusNotFound,
+	}
+	for userID, code := range cases {
+		body, _ := json.Marshal(DevLoginRequest{UserID: userID})
+		req := httptest.NewRequest(http.MethodPost, "/auth/dev/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(suite.T(), code, w.Code, "user %d", userID)
+	}
+}
+
+func (suite *TestAuthSuite) TestRefreshCookie_Attributes() {
+	suite.T().Setenv("DEV_TYPE", "local")
+
+	var chosen models.User
+	suite.db.Where("guest = ? AND anonymized_at IS NULL", false).First(&chosen)
+
+	cookie := func() *http.Cookie {
+		body, _ := json.Marshal(DevLoginRequest{UserID: chosen.ID})
+		req := httptest.NewRequest(http.MethodPost, "/auth/dev/login", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		suite.devRouter().ServeHTTP(w, req)
+		suite.Require().Equal(http.StatusOK, w.Code)
+
+		for _, cookie := range w.Result().Cookies() {
+			if cookie.Name == refreshCookie {
+				return cookie
+			}
+		}
+		suite.FailNow("refresh token cookie not set")
+		return nil
+	}
+
+	// Secure by default, even though the test request is plain HTTP
+	secure := cookie()
+	assert.True(suite.T(), secure.Secure)
+	assert.True(suite.T(), secure.HttpOnly)
+	assert.Equal(suite.T(), http.SameSiteStrictMode, secure.SameSite)
+	assert.Equal(suite.T(), "/auth", secure.Path)
+
+	suite.T().Setenv("COOKIE_SECURE", "false")
+	plain := cookie()
+	assert.False(suite.T(), plain.Secure)
+	assert.Equal(suite.T(), http.SameSiteStrictMode, plain.SameSite)
+}
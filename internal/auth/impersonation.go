@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNotPlatformAdmin = errors.New("only platform admins can impersonate users")
+	ErrImpersonateSelf  = errors.New("you cannot impersonate yourself")
+)
+
+// Impersonate starts a session in which the platform admin acts as the target
+// user. It has no refresh token, so it ends when its access token expires.
+func (s *service) Impersonate(actorID uint, targetID uint) (*TokenPair, error) {
+	if actorID == targetID {
+		return nil, ErrImpersonateSelf
+	}
+
+	isAdmin, err := s.IsPlatformAdmin(actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, ErrNotPlatformAdmin
+	}
+
+	var actor, target models.User
+	if err := s.db.First(&actor, actorID).Error; err != nil {
+		return nil, err
+	}
+	if err := s.db.First(&target, targetID).Error; err != nil {
+		return nil, err
+	}
+
+	var accessToken string
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		session := models.Session{UserID: target.ID, ImpersonatorID: &actor.ID}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		var err error
+		accessToken, err = s.signAccessToken(tx, &target, session.ID, &actor)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{AccessToken: accessToken, ExpiresIn: int(accessTokenTTL.Seconds())}, nil
+}
+
+// RevokeSession revokes a single session, e.g. to end an impersonation.
+func (s *service) RevokeSession(sessionID uint) error {
+	return s.revokeSessions(s.db.Where("id = ?", sessionID)).Error
+}
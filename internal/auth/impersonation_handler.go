@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+// AuditRecorder stores the audit trail of impersonation.
+type AuditRecorder interface {
+	Record(entry *models.AuditLog) error
+}
+
+type ImpersonationHandler struct {
+	service Service
+	audit   AuditRecorder
+}
+
+func NewImpersonationHandler(rg *authz.Router, service Service, audit AuditRecorder) *ImpersonationHandler {
+	h := &ImpersonationHandler{service: service, audit: audit}
+
+	g := rg.Group("/impersonate")
+
+	// Platform admins are checked by the service. Personal access tokens can
+	// neither start nor end an impersonation, only a login session can.
+	g.POST("/:userId", authz.Authenticated.Sensitive(), h.Start)
+	g.DELETE("", authz.Authenticated.Interactive(), h.Stop)
+
+	return h
+}
+
+// Start
+//
+//	@Summary		Impersonate a user
+//	@Security		BearerAuth
+//	@Description	Platform admins only. Returns an access token for acting as the user. Responses to it carry the X-Impersonated-By header, sensitive routes are refused and every request is audited.
+//	@Tags			Auth
+//	@Produce		json
+//	@Param			userId	path		uint	true	"User ID"
+//	@Success		200		{object}	TokenPair
+//	@Failure		400		{object}	map[string]string
+//	@Failure		403		{object}	map[string]string
+//	@Failure		404		{object}	map[string]string
+//	@Router			/impersonate/{userId} [post]
+func (h *ImpersonationHandler) Start(c *gin.Context) {
+	actorID, ok := authz.UserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	targetID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
+		return
+	}
+
+	pair, err := h.service.Impersonate(actorID, uint(targetID))
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrNotPlatformAdmin):
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, ErrImpersonateSelf), errors.Is(err, ErrUserAnonymized), errors.Is(err, ErrGuestUser):
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		default:
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "could not start impersonation"})
+		}
+		return
+	}
+
+	err = h.audit.Record(&models.AuditLog{
+		ActorID: actorID,
+		UserID:  uint(targetID),
+		Method:  c.Request.Method,
+		Route:   c.FullPath(),
+		Path:    c.Request.URL.Path,
+		Status:  http.StatusOK,
+	})
+	if err != nil {
+		log.Error().Err(err).Uint("actor_id", actorID).Msg("Failed to record impersonation")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not start impersonation"})
+		return
+	}
+
+	c.JSON(http.StatusOK, pair)
+}
+
+// Stop
+//
+//	@Summary		Stop impersonating
+//	@Security		BearerAuth
+//	@Description	Revokes the impersonation session of the token
+//	@Tags			Auth
+//	@Success		204
+//	@Failure		400	{object}	map[string]string
+//	@Router			/impersonate [delete]
+func (h *ImpersonationHandler) Stop(c *gin.Context) {
+	if _, impersonating := authz.ImpersonatorID(c); !impersonating {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Not impersonating"})
+		return
+	}
+
+	if err := h.service.RevokeSession(c.GetUint("sessionID")); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "could not stop impersonation"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/audit"
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"GEWIS-Rooster/internal/platform/middleware"
+	"GEWIS-Rooster/internal/token"
+	"GEWIS-Rooster/internal/user"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (suite *TestAuthSuite) impersonationUsers() (models.User, models.User) {
+	var users []models.User
+	suite.db.Order("id ASC").Limit(2).Find(&users)
+	suite.db.Model(&users[0]).Update("platform_admin", true)
+	return users[0], users[1]
+}
+
+func (suite *TestAuthSuite) TestImpersonate_OnlyPlatformAdmins() {
+	admin, target := suite.impersonationUsers()
+
+	_, err := suite.service.Impersonate(target.ID, admin.ID)
+	assert.ErrorIs(suite.T(), err, ErrNotPlatformAdmin)
+
+	_, err = suite.service.Impersonate(admin.ID, admin.ID)
+	assert.ErrorIs(suite.T(), err, ErrImpersonateSelf)
+
+	pair, err := suite.service.Impersonate(admin.ID, target.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), pair.RefreshToken)
+
+	claims := jwt.MapClaims{}
+	_, err = jwt.ParseWithClaims(pair.AccessToken, claims, suite.service.keys.Keyfunc)
+	assert.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), *target.GEWISID, claims["sub"])
+	assert.EqualValues(suite.T(), *admin.GEWISID, claims["act"].(map[string]interface{})["sub"])
+}
+
+func (suite *TestAuthSuite) TestImpersonate_MarkedRestrictedAndAudited() {
+	admin, target := suite.impersonationUsers()
+	pair, err := suite.service.Impersonate(admin.ID, target.ID)
+	suite.Require().NoError(err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	authMiddle := middleware.NewAuthMiddleware(&suite.service, user.NewUserService(suite.db),
+		token.NewTokenService(suite.db), audit.NewAuditService(suite.db), suite.service.keys)
+	protected := r.Group("", authMiddle.AuthMiddlewareCheck())
+	rg := authz.NewRouter(protected, suite.db, authz.NewRegistry())
+	rg.GET("/whoami", authz.Authenticated, func(c *gin.Context) {
+		userID, _ := authz.UserID(c)
+		c.String(http.StatusOK, fmt.Sprint(userID))
+	})
+	rg.POST("/sensitive", authz.Authenticated.Sensitive(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	NewImpersonationHandler(rg, &suite.service, audit.NewAuditService(suite.db))
+
+	request := func(method string, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.Header.Set("Authorization", "Bearer "+pair.AccessToken)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := request(http.MethodGet, "/whoami")
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+	assert.Equal(suite.T(), fmt.Sprint(target.ID), w.Body.String())
+	assert.Equal(suite.T(), fmt.Sprint(admin.ID), w.Header().Get("X-Impersonated-By"))
+
+	w = request(http.MethodPost, "/sensitive")
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = request(http.MethodDelete, "/impersonate")
+	assert.Equal(suite.T(), http.StatusNoContent, w.Code)
+
+	w = request(http.MethodGet, "/whoami")
+	assert.Equal(suite.T(), http.StatusUnauthorized, w.Code)
+
+	var logs []models.AuditLog
+	suite.db.Order("id ASC").Find(&logs)
+	if assert.Len(suite.T(), logs, 3) {
+		assert.Equal(suite.T(), "/whoami", logs[0].Route)
+		assert.Equal(suite.T(), http.StatusForbidden, logs[1].Status)
+		assert.Equal(suite.T(), "/impersonate", logs[2].Route)
+		assert.Equal(suite.T(), admin.ID, logs[2].ActorID)
+		assert.Equal(suite.T(), target.ID, logs[2].UserID)
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/platform/authz"
+	"GEWIS-Rooster/internal/platform/signing"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type JWKSHandler struct {
+	keys *signing.KeySet
+}
+
+func NewJWKSHandler(rg *authz.Router, keys *signing.KeySet) *JWKSHandler {
+	h := &JWKSHandler{keys: keys}
+
+	rg.GET("/.well-known/jwks.json", authz.Public, h.GetJWKS)
+
+	return h
+}
+
+// GetJWKS
+//
+//	@Summary		Get the token verification keys
+//	@Description	Publishes the public keys that verify internal tokens, matched to a token by its kid header
+//	@Tags			Auth
+//	@Produce		json
+//	@Success		200	{object}	signing.JWKS
+//	@Router			/.well-known/jwks.json [get]
+func (h *JWKSHandler) GetJWKS(c *gin.Context) {
+	// Keys only change on restart, but allow rotation without long caching
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
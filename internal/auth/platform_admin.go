@@ -0,0 +1,44 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"slices"
+)
+
+// IsPlatformAdmin reports whether the user is a platform admin. Like the
+// platform admin policy it only reads the platform_admin column, which
+// syncPlatformAdmin keeps up to date on every login.
+func (s *service) IsPlatformAdmin(userID uint) (bool, error) {
+	var admin models.User
+	if err := s.db.Select("id", "platform_admin").First(&admin, userID).Error; err != nil {
+		return false, err
+	}
+	return admin.PlatformAdmin, nil
+}
+
+// syncPlatformAdmin grants or withdraws the platform admin role on login,
+// from PLATFORM_ADMIN_IDS or the configured identity provider role.
+func (s *service) syncPlatformAdmin(user *models.User, claims map[string]interface{}) error {
+	isAdmin := s.listedAdmin(user)
+
+	if !isAdmin && s.config.PlatformAdminRole != "" {
+		roles, err := s.config.organRoles(claims)
+		if err != nil {
+			return err
+		}
+		isAdmin = slices.Contains(roles, s.config.PlatformAdminRole)
+	}
+
+	if user.PlatformAdmin == isAdmin {
+		return nil
+	}
+
+	user.PlatformAdmin = isAdmin
+	return s.db.Model(user).Update("platform_admin", isAdmin).Error
+}
+
+// listedAdmin reports whether the user is listed in PLATFORM_ADMIN_IDS.
+// Anonymised users have no GEWIS ID and are never listed.
+func (s *service) listedAdmin(user *models.User) bool {
+	return user.GEWISID != nil && slices.Contains(s.config.PlatformAdminIDs, *user.GEWISID)
+}
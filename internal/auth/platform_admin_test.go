@@ -0,0 +1,31 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"context"
+	"github.com/stretchr/testify/assert"
+)
+
+func (suite *TestAuthSuite) TestProcessUserInfo_SyncsPlatformAdmin() {
+	suite.service.config.PlatformAdminRole = "test platform-admin"
+	roles := suite.organRoles("test BAC", "test platform-admin")
+
+	_, err := suite.service.ProcessUserInfo(context.Background(), suite.issuer.token(suite.T(), roles), "nonce")
+	assert.NoError(suite.T(), err)
+
+	var signedIn models.User
+	suite.db.Where("gewis_id = ?", 4242).First(&signedIn)
+	assert.True(suite.T(), signedIn.PlatformAdmin)
+	assert.NotContains(suite.T(), suite.memberships(), "platform-admin")
+
+	_, err = suite.service.ProcessUserInfo(context.Background(), suite.issuer.token(suite.T(), suite.organRoles("test BAC")), "nonce")
+	assert.NoError(suite.T(), err)
+	suite.db.First(&signedIn, signedIn.ID)
+	assert.False(suite.T(), signedIn.PlatformAdmin)
+
+	suite.service.config.PlatformAdminIDs = []uint{4242}
+	_, err = suite.service.ProcessUserInfo(context.Background(), suite.issuer.token(suite.T(), suite.organRoles("test BAC")), "nonce")
+	assert.NoError(suite.T(), err)
+	suite.db.First(&signedIn, signedIn.ID)
+	assert.True(suite.T(), signedIn.PlatformAdmin)
+}
@@ -0,0 +1,231 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"time"
+)
+
+const (
+	accessTokenTTL  = 15 * time.Minute
+	refreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("refresh token was already used, the session has been revoked")
+	ErrSessionRevoked      = errors.New("session is revoked")
+	ErrUserAnonymized      = errors.New("user is anonymised")
+	ErrGuestUser           = errors.New("guest users cannot log in")
+)
+
+// TokenPair is handed out on login and refresh. The refresh token is sent in
+// a cookie, so it is left out of the JSON.
+type TokenPair struct {
+	AccessToken string `json:"accessToken"`
+
+	RefreshToken string `json:"-"`
+
+	ExpiresIn int `json:"expiresIn"`
+} // @name TokenPair
+
+// CreateSession starts a new session for the user and returns its first
+// access and refresh token.
+func (s *service) CreateSession(user *models.User) (*TokenPair, error) {
+	var pair *TokenPair
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		session := models.Session{UserID: user.ID}
+		if err := tx.Create(&session).Error; err != nil {
+			return err
+		}
+
+		var err error
+		pair, err = s.issueTokens(tx, user, session.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Refresh exchanges a refresh token for a new token pair. Every refresh token
+// can be used once. When a used token is presented again it has been copied,
+// so the whole session is revoked.
+func (s *service) Refresh(refreshToken string) (*TokenPair, error) {
+	var token models.RefreshToken
+	err := s.db.Preload("Session.User").Where("token_hash = ?", hashToken(refreshToken)).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if token.Session.RevokedAt != nil || time.Now().After(token.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if token.UsedAt != nil {
+		return nil, s.revokeReusedSession(token.SessionID)
+	}
+
+	var pair *TokenPair
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		// Only one request may use the token, a concurrent one counts as reuse
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND used_at IS NULL", token.ID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrRefreshTokenReused
+		}
+
+		var err error
+		pair, err = s.issueTokens(tx, token.Session.User, token.SessionID)
+		return err
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		return nil, s.revokeReusedSession(token.SessionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// Logout revokes the session of the refresh token.
+func (s *service) Logout(refreshToken string) error {
+	var token models.RefreshToken
+	err := s.db.Where("token_hash = ?", hashToken(refreshToken)).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidRefreshToken
+		}
+		return err
+	}
+
+	return s.revokeSessions(s.db.Where("id = ?", token.SessionID)).Error
+}
+
+// RevokeUserSessions revokes every session of the user and returns how many
+// were still active.
+func (s *service) RevokeUserSessions(userID uint) (int64, error) {
+	result := s.revokeSessions(s.db.Where("user_id = ?", userID))
+	return result.RowsAffected, result.Error
+}
+
+// CheckSession returns the session, or ErrSessionRevoked when it does not
+// exist or has been revoked.
+func (s *service) CheckSession(sessionID uint) (*models.Session, error) {
+	var session models.Session
+	err := s.db.First(&session, sessionID).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSessionRevoked
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil {
+		return nil, ErrSessionRevoked
+	}
+
+	return &session, nil
+}
+
+func (s *service) revokeSessions(scope *gorm.DB) *gorm.DB {
+	return scope.Model(&models.Session{}).
+		Where("revoked_at IS NULL").
+		Update("revoked_at", time.Now())
+}
+
+func (s *service) revokeReusedSession(sessionID uint) error {
+	log.Warn().Uint("session_id", sessionID).Msg("Refresh token reused, revoking session")
+
+	if err := s.revokeSessions(s.db.Where("id = ?", sessionID)).Error; err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// issueTokens stores a new refresh token for the session and signs an access
+// token that names it.
+func (s *service) issueTokens(tx *gorm.DB, user *models.User, sessionID uint) (*TokenPair, error) {
+	refreshToken, err := s.RandString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Create(&models.RefreshToken{
+		SessionID: sessionID,
+		TokenHash: hashToken(refreshToken),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, err := s.signAccessToken(tx, user, sessionID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(accessTokenTTL.Seconds()),
+	}, nil
+}
+
+// signAccessToken signs an access token for the user. When an admin is
+// impersonating the user, the act claim names the admin. Tokens name the user
+// by GEWIS ID, so guests and anonymised users cannot get one.
+func (s *service) signAccessToken(tx *gorm.DB, user *models.User, sessionID uint, actor *models.User) (string, error) {
+	if user.Guest {
+		return "", ErrGuestUser
+	}
+	if user.GEWISID == nil {
+		return "", ErrUserAnonymized
+	}
+
+	now := time.Now()
+
+	var userOrgans []models.UserOrgan
+	if err := tx.Where("user_id = ? AND status = ?", user.ID, models.MembershipActive).Find(&userOrgans).Error; err != nil {
+		return "", err
+	}
+
+	roles := make(map[uint]string)
+	for _, uo := range userOrgans {
+		roles[uo.OrganID] = string(uo.Role)
+	}
+
+	claims := jwt.MapClaims{
+		"sub":    *user.GEWISID,
+		"sid":    sessionID,
+		"name":   user.Name,
+		"organs": roles,
+		"iat":    now.Unix(),
+		"exp":    now.Add(accessTokenTTL).Unix(),
+	}
+	if actor != nil {
+		claims["act"] = map[string]interface{}{"sub": actor.GEWISID}
+	}
+
+	return s.keys.Sign(claims)
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
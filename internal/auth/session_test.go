@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"time"
+)
+
+func (suite *TestAuthSuite) newSession() (*models.User, *TokenPair) {
+	var member models.User
+	suite.db.First(&member)
+
+	pair, err := suite.service.CreateSession(&member)
+	suite.Require().NoError(err)
+	return &member, pair
+}
+
+func (suite *TestAuthSuite) sessionID(pair *TokenPair) uint {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(pair.AccessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	suite.Require().NoError(err)
+	return uint(claims["sid"].(float64))
+}
+
+func (suite *TestAuthSuite) checkSession(sessionID uint) error {
+	_, err := suite.service.CheckSession(sessionID)
+	return err
+}
+
+func (suite *TestAuthSuite) TestCreateSession_ShortLivedAccessToken() {
+	_, pair := suite.newSession()
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(pair.AccessToken, claims, func(token *jwt.Token) (interface{}, error) {
+		return []byte("test-secret"), nil
+	})
+	assert.NoError(suite.T(), err)
+
+	exp, err := claims.GetExpirationTime()
+	assert.NoError(suite.T(), err)
+	assert.WithinDuration(suite.T(), time.Now().Add(accessTokenTTL), exp.Time, time.Minute)
+	assert.NoError(suite.T(), suite.checkSession(suite.sessionID(pair)))
+
+	var stored models.RefreshToken
+	assert.NoError(suite.T(), suite.db.First(&stored).Error)
+	assert.NotEqual(suite.T(), pair.RefreshToken, stored.TokenHash)
+}
+
+func (suite *TestAuthSuite) TestCreateSession_RejectsGuest() {
+	guest := models.User{Name: "Guest", Guest: true}
+	suite.Require().NoError(suite.db.Create(&guest).Error)
+
+	_, err := suite.service.CreateSession(&guest)
+	assert.ErrorIs(suite.T(), err, ErrGuestUser)
+}
+
+func (suite *TestAuthSuite) TestRefresh_Rotates() {
+	_, pair := suite.newSession()
+
+	refreshed, err := suite.service.Refresh(pair.RefreshToken)
+	assert.NoError(suite.T(), err)
+	assert.NotEqual(suite.T(), pair.RefreshToken, refreshed.RefreshToken)
+	assert.Equal(suite.T(), suite.sessionID(pair), suite.sessionID(refreshed))
+
+	_, err = suite.service.Refresh(refreshed.RefreshToken)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TestAuthSuite) TestRefresh_ReuseRevokesSession() {
+	_, pair := suite.newSession()
+
+	refreshed, err := suite.service.Refresh(pair.RefreshToken)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.service.Refresh(pair.RefreshToken)
+	assert.ErrorIs(suite.T(), err, ErrRefreshTokenReused)
+
+	// The token handed out by the legitimate refresh is revoked as well
+	_, err = suite.service.Refresh(refreshed.RefreshToken)
+	assert.ErrorIs(suite.T(), err, ErrInvalidRefreshToken)
+	assert.ErrorIs(suite.T(), suite.checkSession(suite.sessionID(pair)), ErrSessionRevoked)
+}
+
+func (suite *TestAuthSuite) TestRefresh_Invalid() {
+	_, err := suite.service.Refresh("unknown")
+	assert.ErrorIs(suite.T(), err, ErrInvalidRefreshToken)
+
+	_, pair := suite.newSession()
+	suite.db.Model(&models.RefreshToken{}).Where("1 = 1").Update("expires_at", time.Now().Add(-time.Minute))
+
+	_, err = suite.service.Refresh(pair.RefreshToken)
+	assert.ErrorIs(suite.T(), err, ErrInvalidRefreshToken)
+}
+
+func (suite *TestAuthSuite) TestLogout_RevokesSession() {
+	_, pair := suite.newSession()
+	_, other := suite.newSession()
+
+	assert.NoError(suite.T(), suite.service.Logout(pair.RefreshToken))
+	assert.ErrorIs(suite.T(), suite.checkSession(suite.sessionID(pair)), ErrSessionRevoked)
+	assert.NoError(suite.T(), suite.checkSession(suite.sessionID(other)))
+
+	_, err := suite.service.Refresh(pair.RefreshToken)
+	assert.ErrorIs(suite.T(), err, ErrInvalidRefreshToken)
+}
+
+func (suite *TestAuthSuite) TestRevokeUserSessions() {
+	member, pair := suite.newSession()
+	_, second := suite.newSession()
+
+	var other models.User
+	suite.db.Where("id <> ?", member.ID).First(&other)
+	otherPair, err := suite.service.CreateSession(&other)
+	suite.Require().NoError(err)
+
+	revoked, err := suite.service.RevokeUserSessions(member.ID)
+	assert.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 2, revoked)
+
+	assert.ErrorIs(suite.T(), suite.checkSession(suite.sessionID(pair)), ErrSessionRevoked)
+	assert.ErrorIs(suite.T(), suite.checkSession(suite.sessionID(second)), ErrSessionRevoked)
+	assert.NoError(suite.T(), suite.checkSession(suite.sessionID(otherPair)))
+}
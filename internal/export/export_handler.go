@@ -1,6 +1,8 @@
 package export
 
 import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
 	"fmt"
 	"github.com/gin-gonic/gin"
 	"net/http"
@@ -11,12 +13,13 @@ type Handler struct {
 	exportService Service
 }
 
-func NewExportHandler(exportService Service, rg *gin.RouterGroup) *Handler {
+func NewExportHandler(exportService Service, rg *authz.Router) *Handler {
 	h := &Handler{exportService: exportService}
 
 	g := rg.Group("/export")
 
-	g.GET("/roster/:id", h.AssignmentToPng)
+	// The export shows the assignments before they are published, so it is admin only
+	g.GET("/roster/:id", authz.Require(authz.ModelParam(&models.Roster{}, "id"), models.PermRosterExport), h.AssignmentToPng)
 
 	return h
 }
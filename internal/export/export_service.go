@@ -46,6 +46,16 @@ func (e *service) AssignmentsToPng(rosterID uint) ([]byte, error) {
 		return nil, err
 	}
 
+	var roster models.Roster
+	if err := e.db.Select("id", "organ_id").First(&roster, rosterID).Error; err != nil {
+		return nil, err
+	}
+
+	settings, err := models.LoadOrganSettings(e.db, roster.OrganID)
+	if err != nil {
+		return nil, err
+	}
+
 	tempDc := gg.NewContext(0, 0)
 	if err := tempDc.LoadFontFace("cmd/src/static/fonts/arial.ttf", PngImage.FontSize); err != nil {
 		log.Err(err).Msg("failed to load font for measurement")
@@ -54,11 +64,7 @@ func (e *service) AssignmentsToPng(rosterID uint) ([]byte, error) {
 	// 2. Calculate the required width for the Users column
 	maxUserWidth := PngImage.ColWidthUsers
 	for _, shift := range savedShifts {
-		names := []string{}
-		for _, u := range shift.Users {
-			names = append(names, u.Name)
-		}
-		userText := strings.Join(names, ", ")
+		userText := userNames(shift.Users)
 
 		// Measure how wide this specific string is in pixels
 		textW, _ := tempDc.MeasureString(userText)
@@ -85,11 +91,15 @@ func (e *service) AssignmentsToPng(rosterID uint) ([]byte, error) {
 	dc.Clear()
 
 	// Header
-	dc.SetHexColor("#f3f4f6")
+	dc.SetHexColor(settings.ExportHeaderColor)
 	dc.DrawRectangle(0, 0, float64(width), PngImage.RowHeight)
 	dc.Fill()
 
-	dc.SetHexColor("#374151")
+	if len(settings.ExportLogo) > 0 {
+		drawLogo(dc, settings.ExportLogo, float64(width))
+	}
+
+	dc.SetHexColor(settings.ExportHeaderTextColor)
 	dc.DrawStringAnchored("SHIFT", PngImage.Padding, PngImage.RowHeight/2, 0, 0.5)
 	dc.DrawStringAnchored("ASSIGNED USERS", PngImage.ColWidthShift+PngImage.Padding, PngImage.RowHeight/2, 0, 0.5)
 
@@ -97,7 +107,7 @@ func (e *service) AssignmentsToPng(rosterID uint) ([]byte, error) {
 		y := float64(i+1) * PngImage.RowHeight
 
 		if i%2 == 0 {
-			dc.SetHexColor("#f9fafb")
+			dc.SetHexColor(settings.ExportStripeColor)
 			dc.DrawRectangle(0, y, float64(width), PngImage.RowHeight)
 			dc.Fill()
 		}
@@ -108,15 +118,11 @@ func (e *service) AssignmentsToPng(rosterID uint) ([]byte, error) {
 		dc.Stroke()
 
 		// Shift Name
-		dc.SetHexColor("#111827")
+		dc.SetHexColor(settings.ExportTextColor)
 		dc.DrawStringAnchored(shift.RosterShift.Name, PngImage.Padding, y+(PngImage.RowHeight/2), 0, 0.5)
 
 		// Users List
-		names := []string{}
-		for _, u := range shift.Users {
-			names = append(names, u.Name)
-		}
-		userText := strings.Join(names, ", ")
+		userText := userNames(shift.Users)
 
 		dc.SetHexColor("#4b5563")
 		dc.DrawStringAnchored(userText, PngImage.ColWidthShift+PngImage.Padding, y+(PngImage.RowHeight/2), 0, 0.5)
@@ -128,3 +134,43 @@ func (e *service) AssignmentsToPng(rosterID uint) ([]byte, error) {
 	}
 	return buf.Bytes(), nil
 }
+
+// drawLogo draws the organ logo at the right of the header row, scaled to fit
+// the row. Logos that cannot be decoded are skipped.
+// userNames lists the names of the assigned users, marking guests so readers
+// can tell external volunteers apart.
+func userNames(users []*models.User) string {
+	names := make([]string, 0, len(users))
+	for _, u := range users {
+		if u.Guest {
+			names = append(names, u.Name+" (guest)")
+			continue
+		}
+		names = append(names, u.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func drawLogo(dc *gg.Context, logo []byte, width float64) {
+	img, err := models.DecodeExportLogo(logo)
+	if err != nil {
+		log.Err(err).Msg("failed to decode export logo")
+		return
+	}
+
+	bounds := img.Bounds()
+	if bounds.Dy() == 0 {
+		return
+	}
+
+	height := PngImage.RowHeight - PngImage.Padding
+	scale := height / float64(bounds.Dy())
+	x := width - PngImage.Padding - float64(bounds.Dx())*scale
+	y := (PngImage.RowHeight - height) / 2
+
+	dc.Push()
+	dc.Translate(x, y)
+	dc.Scale(scale, scale)
+	dc.DrawImage(img, 0, 0)
+	dc.Pop()
+}
@@ -0,0 +1,70 @@
+package guest
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/roster"
+	"time"
+)
+
+type CreateRequest struct {
+	OrganID uint `json:"organId" binding:"required"`
+
+	Name string `json:"name" binding:"required,max=255"`
+
+	Email string `json:"email" binding:"required,email,max=255"`
+} // @name GuestCreateRequest
+
+// Guest is a guest user together with the email address, which is only shown
+// to organ admins.
+type Guest struct {
+	*models.User
+
+	Email string `json:"email"`
+} // @name Guest
+
+type LinkRequest struct {
+	GuestID uint `json:"guestId" binding:"required"`
+
+	RosterID uint `json:"rosterId" binding:"required"`
+} // @name GuestLinkRequest
+
+// Link is a signed link that lets a guest answer a single roster without
+// logging in.
+type Link struct {
+	Token string `json:"token"`
+
+	// URL is the link to send to the guest, only set when GUEST_LINK_URL is configured
+	URL string `json:"url,omitempty"`
+
+	ExpiresAt time.Time `json:"expiresAt"`
+} // @name GuestLink
+
+// LinkShift is a shift of the roster a guest is asked to answer.
+type LinkShift struct {
+	ID uint `json:"id"`
+
+	Name string `json:"name"`
+} // @name GuestLinkShift
+
+// LinkRoster is what a guest sees when opening a link: the roster with its
+// shifts and values and the answers the guest already gave, without the
+// answers of others.
+type LinkRoster struct {
+	GuestName string `json:"guestName"`
+
+	RosterID uint `json:"rosterId"`
+
+	Name string `json:"name"`
+
+	Date time.Time `json:"date"`
+
+	Values []string `json:"values"`
+
+	Shifts []LinkShift `json:"shifts"`
+
+	Answers []*models.RosterAnswer `json:"answers"`
+} // @name GuestLinkRoster
+
+type AnswerRequest struct {
+	Answers []roster.OnBehalfAnswer `json:"answers" binding:"required,min=1,dive"`
+} // @name GuestAnswerRequest
@@ -0,0 +1,274 @@
+package guest
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/organ"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+)
+
+type Handler struct {
+	guestService Service
+}
+
+// NewGuestHandler registers the routes organ admins use to manage guests and
+// hand out links.
+func NewGuestHandler(rg *authz.Router, guestService Service) *Handler {
+	h := &Handler{guestService: guestService}
+
+	g := rg.Group("/guest")
+	g.POST("", authz.Require(authz.OrganBody("organId"), models.PermMemberManage), h.CreateGuest)
+	g.GET("", authz.Require(authz.OrganQuery("organId"), models.PermMemberManage), h.GetGuests)
+	// Anonymising is irreversible, so it needs a login session of the admin
+	g.DELETE("/:id", authz.Require(guestParam("id"), models.PermMemberManage).Sensitive(), h.AnonymizeGuest)
+	g.POST("/link", authz.Require(rosterBody(), models.PermRosterAssign), h.CreateLink)
+
+	return h
+}
+
+// NewGuestLinkHandler registers the routes guests open through their link.
+// They are public, the signed token in the link is the only credential.
+func NewGuestLinkHandler(rg *authz.Router, guestService Service) *Handler {
+	h := &Handler{guestService: guestService}
+
+	g := rg.Group("/guest/answer")
+	g.GET("", authz.Public, h.GetLinkRoster)
+	g.POST("", authz.Public, h.AnswerByLink)
+
+	return h
+}
+
+// guestParam resolves the organ of the guest in a path parameter. Other users
+// are not found, so organ admins cannot anonymise members this way.
+func guestParam(param string) authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		guestID, err := authz.ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
+
+		var membership models.UserOrgan
+		err = db.Joins("JOIN users ON users.id = user_organs.user_id").
+			Where("user_organs.user_id = ? AND users.guest = ?", guestID, true).
+			First(&membership).Error
+		if err != nil {
+			return nil, err
+		}
+		return &authz.Resource{OrganID: membership.OrganID}, nil
+	}
+}
+
+// rosterBody resolves the organ of the roster referenced by the rosterId field
+// in the body.
+func rosterBody() authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		rosterID, err := authz.BodyID(c, "rosterId")
+		if err != nil {
+			return nil, err
+		}
+
+		var roster models.Roster
+		if err := db.First(&roster, rosterID).Error; err != nil {
+			return nil, err
+		}
+		return &authz.Resource{OrganID: roster.OrganID}, nil
+	}
+}
+
+// CreateGuest
+//
+//	@Summary		Add a guest to an organ
+//	@Security		BearerAuth
+//	@Description	Creates a user without a GEWIS account, identified by name and email. Guests can be assigned to shifts and answer rosters through a link, but cannot log in.
+//	@Tags			Guest
+//	@Accept			json
+//	@Produce		json
+//	@Param			createParams	body		GuestCreateRequest	true	"Guest"
+//	@Success		201				{object}	Guest
+//	@Failure		400				{string}	string
+//	@Failure		404				{string}	string
+//	@Failure		409				{string}	string
+//	@Router			/guest [post]
+func (h *Handler) CreateGuest(c *gin.Context) {
+	var params CreateRequest
+	// The policy already read the body for the organ, so bind from the copy
+	if err := c.ShouldBindBodyWith(&params, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request " + err.Error()})
+		return
+	}
+
+	guest, err := h.guestService.CreateGuest(&params)
+	if err != nil {
+		writeGuestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, guest)
+}
+
+// GetGuests
+//
+//	@Summary		List the guests of an organ
+//	@Security		BearerAuth
+//	@Description	Lists the active guests of the organ with their email address.
+//	@Tags			Guest
+//	@Produce		json
+//	@Param			organId	query		uint	true	"Organ ID"
+//	@Success		200		{array}		Guest
+//	@Failure		400		{string}	string
+//	@Router			/guest [get]
+func (h *Handler) GetGuests(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Query("organId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organ ID"})
+		return
+	}
+
+	guests, err := h.guestService.GetGuests(uint(organID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, guests)
+}
+
+// AnonymizeGuest
+//
+//	@Summary		Anonymise a guest
+//	@Security		BearerAuth
+//	@Description	Replaces the name and email address of the guest by a tombstone and makes them alumni. Answers and assignments are kept, so historic rosters stay complete. Guests cannot log in to anonymise themselves, so organ admins do it for them.
+//	@Tags			Guest
+//	@Produce		json
+//	@Param			id	path		uint	true	"Guest ID"
+//	@Success		200	{string}	string
+//	@Failure		400	{string}	string
+//	@Failure		403	{string}	string
+//	@Failure		404	{string}	string
+//	@Failure		409	{string}	string
+//	@Router			/guest/{id} [delete]
+func (h *Handler) AnonymizeGuest(c *gin.Context) {
+	guestID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid guest ID"})
+		return
+	}
+
+	if err := h.guestService.AnonymizeGuest(uint(guestID)); err != nil {
+		writeGuestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Guest anonymised",
+	})
+}
+
+// CreateLink
+//
+//	@Summary		Create an answer link for a guest
+//	@Security		BearerAuth
+//	@Description	Signs a link that lets the guest answer the roster without logging in. The link expires the day after the roster and stops working once the roster is saved. When GUEST_LINK_URL is set the full URL is returned as well.
+//	@Tags			Guest
+//	@Accept			json
+//	@Produce		json
+//	@Param			linkParams	body		GuestLinkRequest	true	"Guest and roster"
+//	@Success		201			{object}	GuestLink
+//	@Failure		400			{string}	string
+//	@Failure		404			{string}	string
+//	@Failure		409			{string}	string
+//	@Router			/guest/link [post]
+func (h *Handler) CreateLink(c *gin.Context) {
+	var params LinkRequest
+	if err := c.ShouldBindBodyWith(&params, binding.JSON); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request " + err.Error()})
+		return
+	}
+
+	link, err := h.guestService.CreateLink(&params)
+	if err != nil {
+		writeGuestError(c, err)
+		return
+	}
+
+	// GUEST_LINK_URL is the page of the frontend, with %s for the token
+	if format := os.Getenv("GUEST_LINK_URL"); format != "" {
+		link.URL = fmt.Sprintf(format, url.QueryEscape(link.Token))
+	}
+
+	c.JSON(http.StatusCreated, link)
+}
+
+// GetLinkRoster
+//
+//	@Summary		Open a guest answer link
+//	@Description	Returns the roster of the link with its shifts, values and the answers the guest already gave.
+//	@Tags			Guest
+//	@Produce		json
+//	@Param			token	query		string	true	"Token of the link"
+//	@Success		200		{object}	GuestLinkRoster
+//	@Failure		401		{string}	string
+//	@Failure		409		{string}	string
+//	@Router			/guest/answer [get]
+func (h *Handler) GetLinkRoster(c *gin.Context) {
+	linkRoster, err := h.guestService.GetLinkRoster(c.Query("token"))
+	if err != nil {
+		writeGuestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, linkRoster)
+}
+
+// AnswerByLink
+//
+//	@Summary		Answer a roster as a guest
+//	@Description	Stores the answers of the guest for the roster of the link. Answers given earlier are updated.
+//	@Tags			Guest
+//	@Accept			json
+//	@Produce		json
+//	@Param			token			query		string				true	"Token of the link"
+//	@Param			answerParams	body		GuestAnswerRequest	true	"Answers of the guest"
+//	@Success		200				{array}		models.RosterAnswer
+//	@Failure		400				{string}	string
+//	@Failure		401				{string}	string
+//	@Failure		409				{string}	string
+//	@Router			/guest/answer [post]
+func (h *Handler) AnswerByLink(c *gin.Context) {
+	var params AnswerRequest
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request " + err.Error()})
+		return
+	}
+
+	answers, err := h.guestService.AnswerByLink(c.Query("token"), &params)
+	if err != nil {
+		writeGuestError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, answers)
+}
+
+func writeGuestError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, ErrInvalidLink):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": ErrInvalidLink.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrGuestExists), errors.Is(err, ErrRosterClosed), errors.Is(err, ErrOrganArchived),
+		errors.Is(err, organ.ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		// Non-guests, former members and answers that do not fit the roster
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
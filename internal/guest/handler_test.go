@@ -0,0 +1,72 @@
+package guest
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+)
+
+func (suite *TestGuestSuite) request(path string, userID uint, body interface{}) *httptest.ResponseRecorder {
+	return suite.requestMethod(http.MethodPost, path, userID, body)
+}
+
+func (suite *TestGuestSuite) requestMethod(method string, path string, userID uint, body interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	api := r.Group("", func(c *gin.Context) {
+		c.Set("userID", userID)
+	})
+	NewGuestHandler(authz.NewRouter(api, suite.db, authz.NewRegistry()), &suite.service)
+
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+// The policies read the organ from the body, the handlers must still be able
+// to bind it afterwards.
+func (suite *TestGuestSuite) TestHandler_BindsBodyAfterPolicy() {
+	var admin models.UserOrgan
+	suite.db.Where("organ_id = ?", 1).First(&admin)
+	suite.db.Model(&models.UserOrgan{}).Where("user_id = ? AND organ_id = ?", admin.UserID, 1).Update("role", models.RoleAdmin)
+
+	w := suite.request("/guest", admin.UserID, CreateRequest{OrganID: 1, Name: "Volunteer", Email: "volunteer@example.com"})
+	suite.Require().Equal(http.StatusCreated, w.Code, w.Body.String())
+
+	var guest Guest
+	suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &guest))
+	assert.Equal(suite.T(), "volunteer@example.com", guest.Email)
+
+	w = suite.request("/guest/link", admin.UserID, LinkRequest{GuestID: guest.ID, RosterID: suite.roster.ID})
+	assert.Equal(suite.T(), http.StatusCreated, w.Code, w.Body.String())
+}
+
+func (suite *TestGuestSuite) TestHandler_AnonymizeGuest() {
+	guest := suite.createGuest()
+
+	var members []models.UserOrgan
+	suite.db.Where("organ_id = ? AND user_id <> ?", 1, guest.ID).Limit(2).Find(&members)
+	admin, member := members[0].UserID, members[1].UserID
+	suite.db.Model(&models.UserOrgan{}).Where("user_id = ? AND organ_id = ?", admin, 1).Update("role", models.RoleAdmin)
+	suite.db.Model(&models.UserOrgan{}).Where("user_id = ? AND organ_id = ?", member, 1).Update("role", models.RoleMember)
+
+	path := fmt.Sprintf("/guest/%d", guest.ID)
+	w := suite.requestMethod(http.MethodDelete, path, member, nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	// Members with an account anonymise themselves, not through this route
+	w = suite.requestMethod(http.MethodDelete, fmt.Sprintf("/guest/%d", member), admin, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.requestMethod(http.MethodDelete, path, admin, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code, w.Body.String())
+}
@@ -0,0 +1,296 @@
+package guest
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/signing"
+	"GEWIS-Rooster/internal/roster"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"gorm.io/gorm"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOrganArchived = errors.New("organ is archived")
+	ErrGuestExists   = errors.New("a guest with this email already exists in this organ")
+	ErrNotGuest      = errors.New("user is not a guest")
+	ErrNotMember     = errors.New("guest is not an active member of the organ of the roster")
+	ErrRosterClosed  = errors.New("roster is saved or has already taken place")
+	ErrInvalidLink   = errors.New("link is invalid or expired")
+)
+
+// linkAudience marks tokens that only grant answering a roster as a guest. The
+// tokens have no subject, so they are never accepted as access tokens.
+const linkAudience = "guest-answer"
+
+// linkGrace keeps a link usable until the end of the day of the roster.
+const linkGrace = 24 * time.Hour
+
+// Answerer stores answers for a member, implemented by the roster service.
+type Answerer interface {
+	AnswerOnBehalf(rosterID uint, params *roster.OnBehalfAnswerRequest, actorID uint) ([]*models.RosterAnswer, error)
+}
+
+// Anonymizer removes the personal data of a user, implemented by the user
+// service.
+type Anonymizer interface {
+	Anonymize(ID uint) error
+}
+
+type Service interface {
+	CreateGuest(params *CreateRequest) (*Guest, error)
+	GetGuests(organID uint) ([]*Guest, error)
+	AnonymizeGuest(guestID uint) error
+	CreateLink(params *LinkRequest) (*Link, error)
+	GetLinkRoster(token string) (*LinkRoster, error)
+	AnswerByLink(token string, params *AnswerRequest) ([]*models.RosterAnswer, error)
+}
+
+type service struct {
+	db         *gorm.DB
+	keys       *signing.KeySet
+	answerer   Answerer
+	anonymizer Anonymizer
+}
+
+func NewGuestService(db *gorm.DB, keys *signing.KeySet, answerer Answerer, anonymizer Anonymizer) Service {
+	return &service{db: db, keys: keys, answerer: answerer, anonymizer: anonymizer}
+}
+
+// CreateGuest adds a guest user to the organ. Guests have no GEWIS ID and are
+// matched on their email address within an organ.
+func (s *service) CreateGuest(params *CreateRequest) (*Guest, error) {
+	email := strings.ToLower(strings.TrimSpace(params.Email))
+
+	var guest models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var organ models.Organ
+		if err := tx.First(&organ, params.OrganID).Error; err != nil {
+			return err
+		}
+		if organ.ArchivedAt != nil {
+			return ErrOrganArchived
+		}
+
+		var existing int64
+		err := tx.Model(&models.User{}).
+			Joins("JOIN user_organs ON user_organs.user_id = users.id").
+			Where("user_organs.organ_id = ? AND users.guest = ? AND users.email = ?", params.OrganID, true, email).
+			Count(&existing).Error
+		if err != nil {
+			return err
+		}
+		if existing > 0 {
+			return ErrGuestExists
+		}
+
+		guest = models.User{
+			Name:  params.Name,
+			Guest: true,
+			Email: &email,
+		}
+		if err := tx.Create(&guest).Error; err != nil {
+			return err
+		}
+
+		// The role is marked manual, guests never log in to have it synced
+		return tx.Create(&models.UserOrgan{
+			UserID:     guest.ID,
+			OrganID:    params.OrganID,
+			Role:       models.RoleMember,
+			ManualRole: true,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Guest{User: &guest, Email: email}, nil
+}
+
+// GetGuests returns the active guests of the organ.
+func (s *service) GetGuests(organID uint) ([]*Guest, error) {
+	var users []*models.User
+	err := s.db.
+		Joins("JOIN user_organs ON user_organs.user_id = users.id").
+		Scopes(models.ActiveMembers).
+		Where("user_organs.organ_id = ? AND users.guest = ?", organID, true).
+		Order("users.name").
+		Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+
+	guests := make([]*Guest, 0, len(users))
+	for _, user := range users {
+		guest := &Guest{User: user}
+		if user.Email != nil {
+			guest.Email = *user.Email
+		}
+		guests = append(guests, guest)
+	}
+
+	return guests, nil
+}
+
+// AnonymizeGuest removes the name and email address of the guest, like users
+// anonymise their own account. Guests cannot log in, so organ admins do it.
+func (s *service) AnonymizeGuest(guestID uint) error {
+	var guest models.User
+	if err := s.db.First(&guest, guestID).Error; err != nil {
+		return err
+	}
+	if !guest.Guest {
+		return ErrNotGuest
+	}
+
+	return s.anonymizer.Anonymize(guestID)
+}
+
+// CreateLink signs a link that lets the guest answer the roster. The link
+// expires the day after the roster.
+func (s *service) CreateLink(params *LinkRequest) (*Link, error) {
+	var roster models.Roster
+	if err := s.db.First(&roster, params.RosterID).Error; err != nil {
+		return nil, err
+	}
+
+	if _, err := s.activeGuest(params.GuestID, &roster); err != nil {
+		return nil, err
+	}
+
+	expiresAt := roster.Date.Add(linkGrace)
+	if roster.Saved || !expiresAt.After(time.Now()) {
+		return nil, ErrRosterClosed
+	}
+
+	token, err := s.keys.Sign(jwt.MapClaims{
+		"aud": linkAudience,
+		"gst": params.GuestID,
+		"rid": roster.ID,
+		"iat": time.Now().Unix(),
+		"exp": expiresAt.Unix(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Link{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// GetLinkRoster returns the roster of the link with the answers of the guest.
+func (s *service) GetLinkRoster(token string) (*LinkRoster, error) {
+	guest, roster, err := s.resolveLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var shifts []models.RosterShift
+	if err := s.db.Where("roster_id = ?", roster.ID).Order("`order`").Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+
+	var answers []*models.RosterAnswer
+	if err := s.db.Where("roster_id = ? AND user_id = ?", roster.ID, guest.ID).Find(&answers).Error; err != nil {
+		return nil, err
+	}
+
+	linkRoster := &LinkRoster{
+		GuestName: guest.Name,
+		RosterID:  roster.ID,
+		Name:      roster.Name,
+		Date:      roster.Date,
+		Values:    roster.Values,
+		Shifts:    make([]LinkShift, 0, len(shifts)),
+		Answers:   answers,
+	}
+	for _, shift := range shifts {
+		linkRoster.Shifts = append(linkRoster.Shifts, LinkShift{ID: shift.ID, Name: shift.Name})
+	}
+
+	return linkRoster, nil
+}
+
+// AnswerByLink stores the answers of the guest for the roster of the link. The
+// guest is recorded as the one who entered them.
+func (s *service) AnswerByLink(token string, params *AnswerRequest) ([]*models.RosterAnswer, error) {
+	guest, linked, err := s.resolveLink(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.answerer.AnswerOnBehalf(linked.ID, &roster.OnBehalfAnswerRequest{
+		UserID:  guest.ID,
+		Answers: params.Answers,
+	}, guest.ID)
+}
+
+// resolveLink verifies the link and returns its guest and roster. A link
+// stops working once the guest leaves the organ or the roster is saved,
+// trashed or over.
+func (s *service) resolveLink(raw string) (*models.User, *models.Roster, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, s.keys.Keyfunc,
+		jwt.WithValidMethods(s.keys.ValidMethods()),
+		jwt.WithAudience(linkAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", ErrInvalidLink, err)
+	}
+
+	guestID, okGuest := claims["gst"].(float64)
+	rosterID, okRoster := claims["rid"].(float64)
+	if !okGuest || !okRoster {
+		return nil, nil, ErrInvalidLink
+	}
+
+	var roster models.Roster
+	if err := s.db.First(&roster, uint(rosterID)).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidLink
+		}
+		return nil, nil, err
+	}
+	if roster.Saved {
+		return nil, nil, ErrRosterClosed
+	}
+
+	guest, err := s.activeGuest(uint(guestID), &roster)
+	if err != nil {
+		if errors.Is(err, ErrNotGuest) || errors.Is(err, ErrNotMember) || errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrInvalidLink
+		}
+		return nil, nil, err
+	}
+
+	return guest, &roster, nil
+}
+
+// activeGuest returns the guest when it is an active member of the organ of
+// the roster.
+func (s *service) activeGuest(guestID uint, roster *models.Roster) (*models.User, error) {
+	var guest models.User
+	if err := s.db.First(&guest, guestID).Error; err != nil {
+		return nil, err
+	}
+	if !guest.Guest {
+		return nil, ErrNotGuest
+	}
+
+	var members int64
+	err := s.db.Model(&models.UserOrgan{}).
+		Scopes(models.ActiveMembers).
+		Where("user_id = ? AND organ_id = ?", guestID, roster.OrganID).
+		Count(&members).Error
+	if err != nil {
+		return nil, err
+	}
+	if members == 0 {
+		return nil, ErrNotMember
+	}
+
+	return &guest, nil
+}
@@ -0,0 +1,189 @@
+package guest
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/signing"
+	"GEWIS-Rooster/internal/roster"
+	"GEWIS-Rooster/internal/user"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+type TestGuestSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	keys    *signing.KeySet
+	service service
+
+	roster models.Roster
+}
+
+func (suite *TestGuestSuite) SetupTest() {
+	db := seeder.Seeder(":memory:")
+	suite.db = db
+
+	suite.T().Setenv("JWT_SECRET", "test-secret")
+	keys, err := signing.LoadFromEnv()
+	suite.Require().NoError(err)
+	suite.keys = keys
+
+	suite.service = service{db: db, keys: keys, answerer: roster.NewRosterService(db, nil, nil), anonymizer: user.NewUserService(db)}
+
+	suite.roster = models.Roster{
+		Name:        "Borrel",
+		OrganID:     1,
+		Date:        time.Now().Add(48 * time.Hour),
+		Values:      models.Values{"Yes", "No"},
+		RosterShift: []models.RosterShift{{Name: "Bar"}, {Name: "Kitchen", Order: 1}},
+	}
+	suite.Require().NoError(db.Create(&suite.roster).Error)
+}
+
+func (suite *TestGuestSuite) createGuest() *Guest {
+	guest, err := suite.service.CreateGuest(&CreateRequest{OrganID: 1, Name: "Volunteer", Email: " Volunteer@Example.com"})
+	suite.Require().NoError(err)
+	return guest
+}
+
+func (suite *TestGuestSuite) TestCreateGuest() {
+	guest := suite.createGuest()
+	assert.True(suite.T(), guest.Guest)
+	assert.Nil(suite.T(), guest.GEWISID)
+	assert.Equal(suite.T(), "volunteer@example.com", guest.Email)
+
+	var membership models.UserOrgan
+	assert.NoError(suite.T(), suite.db.Where("user_id = ? AND organ_id = ?", guest.ID, 1).First(&membership).Error)
+	assert.Equal(suite.T(), models.MembershipActive, membership.Status)
+
+	// The same email may be a guest of another organ, but not twice in one
+	_, err := suite.service.CreateGuest(&CreateRequest{OrganID: 1, Name: "Again", Email: "volunteer@example.com"})
+	assert.ErrorIs(suite.T(), err, ErrGuestExists)
+	_, err = suite.service.CreateGuest(&CreateRequest{OrganID: 2, Name: "Again", Email: "volunteer@example.com"})
+	assert.NoError(suite.T(), err)
+
+	guests, err := suite.service.GetGuests(1)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), guests, 1)
+	assert.Equal(suite.T(), guest.ID, guests[0].ID)
+}
+
+func (suite *TestGuestSuite) TestCreateGuest_ArchivedOrgan() {
+	suite.db.Model(&models.Organ{}).Where("id = ?", 1).Update("archived_at", time.Now())
+
+	_, err := suite.service.CreateGuest(&CreateRequest{OrganID: 1, Name: "Volunteer", Email: "volunteer@example.com"})
+	assert.ErrorIs(suite.T(), err, ErrOrganArchived)
+}
+
+func (suite *TestGuestSuite) TestCreateLink_OnlyGuestsOfTheOrgan() {
+	var member models.User
+	suite.db.Where("guest = ?", false).First(&member)
+	_, err := suite.service.CreateLink(&LinkRequest{GuestID: member.ID, RosterID: suite.roster.ID})
+	assert.ErrorIs(suite.T(), err, ErrNotGuest)
+
+	other, err := suite.service.CreateGuest(&CreateRequest{OrganID: 2, Name: "Other", Email: "other@example.com"})
+	suite.Require().NoError(err)
+	_, err = suite.service.CreateLink(&LinkRequest{GuestID: other.ID, RosterID: suite.roster.ID})
+	assert.ErrorIs(suite.T(), err, ErrNotMember)
+
+	guest := suite.createGuest()
+	suite.db.Model(&suite.roster).Update("date", time.Now().Add(-48*time.Hour))
+	_, err = suite.service.CreateLink(&LinkRequest{GuestID: guest.ID, RosterID: suite.roster.ID})
+	assert.ErrorIs(suite.T(), err, ErrRosterClosed)
+}
+
+func (suite *TestGuestSuite) TestAnswerByLink() {
+	guest := suite.createGuest()
+
+	link, err := suite.service.CreateLink(&LinkRequest{GuestID: guest.ID, RosterID: suite.roster.ID})
+	suite.Require().NoError(err)
+	assert.WithinDuration(suite.T(), suite.roster.Date.Add(linkGrace), link.ExpiresAt, time.Second)
+
+	linkRoster, err := suite.service.GetLinkRoster(link.Token)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Volunteer", linkRoster.GuestName)
+	assert.Len(suite.T(), linkRoster.Shifts, 2)
+	assert.Empty(suite.T(), linkRoster.Answers)
+
+	answers, err := suite.service.AnswerByLink(link.Token, &AnswerRequest{
+		Answers: []roster.OnBehalfAnswer{{RosterShiftID: linkRoster.Shifts[0].ID, Value: "Yes"}},
+	})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), answers, 1)
+	assert.Equal(suite.T(), guest.ID, answers[0].UserID)
+
+	var change models.RosterAnswerChange
+	assert.NoError(suite.T(), suite.db.Where("roster_answer_id = ?", answers[0].ID).First(&change).Error)
+	assert.Equal(suite.T(), models.SourceMember, change.Source)
+
+	linkRoster, err = suite.service.GetLinkRoster(link.Token)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), linkRoster.Answers, 1)
+
+	// Saving the roster closes the link
+	suite.db.Model(&suite.roster).Update("saved", true)
+	_, err = suite.service.AnswerByLink(link.Token, &AnswerRequest{
+		Answers: []roster.OnBehalfAnswer{{RosterShiftID: linkRoster.Shifts[0].ID, Value: "No"}},
+	})
+	assert.ErrorIs(suite.T(), err, ErrRosterClosed)
+}
+
+func (suite *TestGuestSuite) TestAnonymizeGuest() {
+	guest := suite.createGuest()
+
+	assert.NoError(suite.T(), suite.service.AnonymizeGuest(guest.ID))
+
+	var stored models.User
+	suite.Require().NoError(suite.db.First(&stored, guest.ID).Error)
+	assert.NotEqual(suite.T(), "Volunteer", stored.Name)
+	assert.Nil(suite.T(), stored.Email)
+	assert.NotNil(suite.T(), stored.AnonymizedAt)
+
+	guests, err := suite.service.GetGuests(1)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), guests)
+
+	var member models.User
+	suite.db.Where("guest = ?", false).First(&member)
+	assert.ErrorIs(suite.T(), suite.service.AnonymizeGuest(member.ID), ErrNotGuest)
+}
+
+func (suite *TestGuestSuite) TestResolveLink_Invalid() {
+	guest := suite.createGuest()
+
+	// Tokens signed for another audience, such as access tokens, are refused
+	accessToken, err := suite.keys.Sign(jwt.MapClaims{
+		"sub": 1,
+		"gst": guest.ID,
+		"rid": suite.roster.ID,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.GetLinkRoster(accessToken)
+	assert.ErrorIs(suite.T(), err, ErrInvalidLink)
+
+	expired, err := suite.keys.Sign(jwt.MapClaims{
+		"aud": linkAudience,
+		"gst": guest.ID,
+		"rid": suite.roster.ID,
+		"exp": time.Now().Add(-time.Hour).Unix(),
+	})
+	suite.Require().NoError(err)
+	_, err = suite.service.GetLinkRoster(expired)
+	assert.ErrorIs(suite.T(), err, ErrInvalidLink)
+
+	// The link stops working once the guest leaves the organ
+	link, err := suite.service.CreateLink(&LinkRequest{GuestID: guest.ID, RosterID: suite.roster.ID})
+	suite.Require().NoError(err)
+	suite.db.Model(&models.UserOrgan{}).Where("user_id = ?", guest.ID).Update("status", models.MembershipAlumni)
+	_, err = suite.service.GetLinkRoster(link.Token)
+	assert.ErrorIs(suite.T(), err, ErrInvalidLink)
+}
+
+func TestGuestService(t *testing.T) {
+	suite.Run(t, new(TestGuestSuite))
+}
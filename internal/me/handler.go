@@ -0,0 +1,89 @@
+package me
+
+import (
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+)
+
+type Handler struct {
+	meService Service
+}
+
+func NewMeHandler(rg *authz.Router, meService Service) *Handler {
+	h := &Handler{meService: meService}
+
+	rg.GET("/me", authz.Authenticated, h.GetDashboard)
+	// Only the user themselves may download their personal data, not an
+	// impersonating admin nor a personal access token
+	rg.GET("/me/export", authz.Authenticated.Sensitive(), h.Export)
+
+	return h
+}
+
+// GetDashboard
+//
+//	@Summary      Get the dashboard of the authenticated user
+//	@Security     BearerAuth
+//	@Description  Returns the user, their organs with role and username, open rosters awaiting their answer, upcoming assigned shifts and pending actions over all organs in one call.
+//	@Tags         Me
+//	@Produce      json
+//	@Success      200            {object}  me.Dashboard
+//	@Failure      401            {string}  string
+//	@Failure      403            {string}  string  "Token limited to some organs"
+//	@Failure      404            {string}  string
+//	@Router       /me [get]
+func (h *Handler) GetDashboard(c *gin.Context) {
+	userID, ok := authz.UserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	dashboard, err := h.meService.GetDashboard(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, dashboard)
+}
+
+// Export
+//
+//	@Summary      Export your personal data
+//	@Security     BearerAuth
+//	@Description  Downloads everything stored about the authenticated user as a JSON file.
+//	@Tags         Me
+//	@Produce      json
+//	@Success      200            {object}  me.DataExport
+//	@Failure      401            {string}  string
+//	@Failure      403            {string}  string
+//	@Failure      404            {string}  string
+//	@Router       /me/export [get]
+func (h *Handler) Export(c *gin.Context) {
+	userID, ok := authz.UserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	export, err := h.meService.Export(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.Header("Content-Disposition", "attachment; filename=grooster-data.json")
+	c.JSON(http.StatusOK, export)
+}
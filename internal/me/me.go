@@ -0,0 +1,127 @@
+package me
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"time"
+)
+
+// Dashboard
+// @Description Everything the landing page shows for the authenticated user, across all of their organs.
+type Dashboard struct {
+	User *models.User `json:"user"`
+
+	// Organs are the organs the user is an active member of
+	Organs []*Membership `json:"organs"`
+
+	// AwaitingAnswer are open rosters for which the user has not answered every shift
+	AwaitingAnswer []*RosterSummary `json:"awaitingAnswer"`
+
+	// UpcomingShifts are the visible saved shifts the user is assigned to, from today on
+	UpcomingShifts []*AssignedShift `json:"upcomingShifts"`
+
+	PendingActions []*PendingAction `json:"pendingActions"`
+} // @name MeDashboard
+
+// Membership is an active membership with the permissions its role grants.
+type Membership struct {
+	OrganID uint `json:"organId"`
+
+	OrganName string `json:"organName"`
+
+	Role models.OrganRole `json:"role"`
+
+	// Username is the name of the user within the organ
+	Username string `json:"username"`
+
+	Permissions models.PermissionSet `json:"permissions" gorm:"-"`
+} // @name MeMembership
+
+type RosterSummary struct {
+	RosterID uint `json:"rosterId"`
+
+	Name string `json:"name"`
+
+	OrganID uint `json:"organId"`
+
+	Date time.Time `json:"date"`
+
+	Shifts int `json:"shifts"`
+
+	Answered int `json:"answered"`
+} // @name MeRosterSummary
+
+type AssignedShift struct {
+	SavedShiftID uint `json:"savedShiftId"`
+
+	RosterID uint `json:"rosterId"`
+
+	RosterName string `json:"rosterName"`
+
+	OrganID uint `json:"organId"`
+
+	ShiftName string `json:"shiftName"`
+
+	Date time.Time `json:"date"`
+} // @name MeAssignedShift
+
+// ActionType names something the user can do on a roster.
+// @name ActionType
+type ActionType string
+
+const (
+	// ActionClaimShift is an open shift of a self sign-up roster the user can claim.
+	ActionClaimShift ActionType = "claim_shift"
+	// ActionSaveRoster is an open roster the user can fill and save.
+	ActionSaveRoster ActionType = "save_roster"
+	// ActionPublishRoster is a saved roster the user can publish to the members.
+	ActionPublishRoster ActionType = "publish_roster"
+)
+
+type PendingAction struct {
+	Type ActionType `json:"type"`
+
+	OrganID uint `json:"organId"`
+
+	RosterID uint `json:"rosterId"`
+
+	RosterName string `json:"rosterName"`
+
+	// SavedShiftID is set for claim_shift actions
+	SavedShiftID *uint `json:"savedShiftId,omitempty"`
+
+	Date time.Time `json:"date"`
+} // @name MePendingAction
+
+// DataExport
+// @Description Everything stored about the authenticated user. Secrets such as token hashes are left out.
+type DataExport struct {
+	ExportedAt time.Time `json:"exportedAt"`
+
+	User *models.User `json:"user"`
+
+	Memberships []*models.UserOrgan `json:"memberships"`
+
+	Answers []*models.RosterAnswer `json:"answers"`
+
+	// AnswerChanges are changes to answers of the user, or made by the user
+	AnswerChanges []*models.RosterAnswerChange `json:"answerChanges"`
+
+	// Shifts are the saved shifts the user is assigned to
+	Shifts []*models.SavedShift `json:"shifts"`
+
+	Waitlist []*models.SavedShiftWaitlistEntry `json:"waitlist"`
+
+	ShiftPreferences []*models.RosterTemplateShiftPreference `json:"shiftPreferences"`
+
+	ShiftGroupPriorities []*models.ShiftGroupPriority `json:"shiftGroupPriorities"`
+
+	Notifications []*models.Notification `json:"notifications"`
+
+	Sessions []*models.Session `json:"sessions"`
+
+	AccessTokens []*models.PersonalAccessToken `json:"accessTokens"`
+
+	// AuditLogs are the actions of platform admins impersonating the user, or
+	// of the user impersonating others
+	AuditLogs []*models.AuditLog `json:"auditLogs"`
+} // @name MeDataExport
@@ -0,0 +1,263 @@
+package me
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+type Service interface {
+	GetDashboard(userID uint) (*Dashboard, error)
+	Export(userID uint) (*DataExport, error)
+}
+
+type service struct {
+	db *gorm.DB
+}
+
+func NewMeService(db *gorm.DB) Service {
+	return &service{db: db}
+}
+
+// GetDashboard collects the memberships, open rosters, upcoming shifts and
+// pending actions of the user over all organs they are an active member of.
+// Rosters count as upcoming from the start of today in the organ's timezone.
+func (s *service) GetDashboard(userID uint) (*Dashboard, error) {
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+
+	dashboard := &Dashboard{
+		User:           &user,
+		Organs:         []*Membership{},
+		AwaitingAnswer: []*RosterSummary{},
+		UpcomingShifts: []*AssignedShift{},
+		PendingActions: []*PendingAction{},
+	}
+
+	memberships, err := s.getMemberships(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(memberships) == 0 {
+		return dashboard, nil
+	}
+	dashboard.Organs = memberships
+
+	permissions := make(map[uint]models.PermissionSet, len(memberships))
+	today := make(map[uint]time.Time, len(memberships))
+	organIDs := make([]uint, 0, len(memberships))
+	earliest := time.Now()
+	for _, membership := range memberships {
+		settings, err := models.LoadOrganSettings(s.db, membership.OrganID)
+		if err != nil {
+			return nil, err
+		}
+		permissions[membership.OrganID] = membership.Permissions
+		today[membership.OrganID] = settings.Today()
+		organIDs = append(organIDs, membership.OrganID)
+		if today[membership.OrganID].Before(earliest) {
+			earliest = today[membership.OrganID]
+		}
+	}
+
+	var rosters []*models.Roster
+	if err := s.db.Preload("RosterShift").
+		Where("organ_id IN ? AND date >= ?", organIDs, earliest).
+		Order("date ASC, id ASC").
+		Find(&rosters).Error; err != nil {
+		return nil, err
+	}
+
+	var savedRosters []*models.Roster
+	for _, roster := range rosters {
+		if roster.Date.Before(today[roster.OrganID]) {
+			continue
+		}
+
+		if roster.Saved {
+			savedRosters = append(savedRosters, roster)
+			if !roster.Published && roster.Mode == models.ModeAssigned && permissions[roster.OrganID].Has(models.PermRosterAssign) {
+				dashboard.PendingActions = append(dashboard.PendingActions, rosterAction(ActionPublishRoster, roster))
+			}
+			continue
+		}
+
+		if permissions[roster.OrganID].Has(models.PermRosterAssign) {
+			dashboard.PendingActions = append(dashboard.PendingActions, rosterAction(ActionSaveRoster, roster))
+		}
+
+		var answered int64
+		if err := s.db.Model(&models.RosterAnswer{}).
+			Where("roster_id = ? AND user_id = ?", roster.ID, userID).
+			Count(&answered).Error; err != nil {
+			return nil, err
+		}
+		if int(answered) < len(roster.RosterShift) {
+			dashboard.AwaitingAnswer = append(dashboard.AwaitingAnswer, &RosterSummary{
+				RosterID: roster.ID,
+				Name:     roster.Name,
+				OrganID:  roster.OrganID,
+				Date:     roster.Date,
+				Shifts:   len(roster.RosterShift),
+				Answered: int(answered),
+			})
+		}
+	}
+
+	if err := s.addSavedShifts(dashboard, userID, savedRosters, permissions); err != nil {
+		return nil, err
+	}
+
+	return dashboard, nil
+}
+
+// Export collects everything stored about the user. Rows that also concern
+// others, such as saved shifts, are exported without the other users.
+func (s *service) Export(userID uint) (*DataExport, error) {
+	export := &DataExport{ExportedAt: time.Now()}
+
+	var user models.User
+	if err := s.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	export.User = &user
+
+	byUser := func() *gorm.DB { return s.db.Where("user_id = ?", userID).Order("id ASC") }
+	queries := []struct {
+		dest  interface{}
+		query *gorm.DB
+	}{
+		{&export.Memberships, s.db.Where("user_id = ?", userID).Order("organ_id ASC")},
+		{&export.Answers, byUser()},
+		{&export.AnswerChanges, s.db.Where("user_id = ? OR actor_id = ?", userID, userID).Order("id ASC")},
+		{&export.Shifts, s.db.Preload("RosterShift").
+			Joins("JOIN user_shift_saved ON user_shift_saved.saved_shift_id = saved_shifts.id").
+			Where("user_shift_saved.user_id = ?", userID).
+			Order("saved_shifts.id ASC")},
+		{&export.Waitlist, byUser()},
+		{&export.ShiftPreferences, byUser()},
+		{&export.ShiftGroupPriorities, byUser()},
+		{&export.Notifications, byUser()},
+		{&export.Sessions, s.db.Where("user_id = ? OR impersonator_id = ?", userID, userID).Order("id ASC")},
+		{&export.AccessTokens, s.db.Preload("Organs").Where("user_id = ?", userID).Order("id ASC")},
+		{&export.AuditLogs, s.db.Where("user_id = ? OR actor_id = ?", userID, userID).Order("id ASC")},
+	}
+	for _, q := range queries {
+		if err := q.query.Find(q.dest).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return export, nil
+}
+
+// getMemberships returns the active memberships of the user in organs that
+// are not archived, with the permissions of their role.
+func (s *service) getMemberships(userID uint) ([]*Membership, error) {
+	var memberships []*Membership
+	if err := s.db.Table("user_organs").
+		Select("user_organs.organ_id, organs.name AS organ_name, user_organs.role, user_organs.username").
+		Joins("JOIN organs ON organs.id = user_organs.organ_id").
+		Where("user_organs.user_id = ? AND organs.archived_at IS NULL", userID).
+		Scopes(models.ActiveMembers).
+		Order("organs.name ASC").
+		Scan(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	for _, membership := range memberships {
+		permissions, err := authz.RolePermissions(s.db, membership.OrganID, membership.Role)
+		if err != nil {
+			return nil, err
+		}
+		membership.Permissions = permissions
+	}
+
+	return memberships, nil
+}
+
+// addSavedShifts adds the shifts of saved rosters the user is assigned to, and
+// the open shifts of self sign-up rosters they can claim. Assignments of
+// unpublished rosters are not visible to members yet, so they are left out.
+func (s *service) addSavedShifts(dashboard *Dashboard, userID uint, rosters []*models.Roster, permissions map[uint]models.PermissionSet) error {
+	if len(rosters) == 0 {
+		return nil
+	}
+
+	byID := make(map[uint]*models.Roster, len(rosters))
+	rosterIDs := make([]uint, 0, len(rosters))
+	for _, roster := range rosters {
+		byID[roster.ID] = roster
+		rosterIDs = append(rosterIDs, roster.ID)
+	}
+
+	var shifts []*models.SavedShift
+	if err := s.db.Preload("Users").Preload("RosterShift").Preload("Waitlist").
+		Where("roster_id IN ?", rosterIDs).
+		Find(&shifts).Error; err != nil {
+		return err
+	}
+
+	// Keep the order of the rosters, then the order of the shifts within them
+	slices.SortStableFunc(shifts, func(a, b *models.SavedShift) int {
+		if a.RosterID != b.RosterID {
+			return slices.Index(rosterIDs, a.RosterID) - slices.Index(rosterIDs, b.RosterID)
+		}
+		return int(shiftOrder(a)) - int(shiftOrder(b))
+	})
+
+	for _, shift := range shifts {
+		roster := byID[shift.RosterID]
+		assigned := slices.ContainsFunc(shift.Users, func(u *models.User) bool { return u.ID == userID })
+
+		if assigned && (roster.Published || roster.Mode == models.ModeSelfSignup) {
+			dashboard.UpcomingShifts = append(dashboard.UpcomingShifts, &AssignedShift{
+				SavedShiftID: shift.ID,
+				RosterID:     roster.ID,
+				RosterName:   roster.Name,
+				OrganID:      roster.OrganID,
+				ShiftName:    shiftName(shift),
+				Date:         roster.Date,
+			})
+			continue
+		}
+
+		waitlisted := slices.ContainsFunc(shift.Waitlist, func(e *models.SavedShiftWaitlistEntry) bool { return e.UserID == userID })
+		if roster.Mode == models.ModeSelfSignup && !assigned && !waitlisted &&
+			uint(len(shift.Users)) < shift.Capacity && permissions[roster.OrganID].Has(models.PermShiftClaim) {
+			action := rosterAction(ActionClaimShift, roster)
+			action.SavedShiftID = &shift.ID
+			dashboard.PendingActions = append(dashboard.PendingActions, action)
+		}
+	}
+
+	return nil
+}
+
+func rosterAction(actionType ActionType, roster *models.Roster) *PendingAction {
+	return &PendingAction{
+		Type:       actionType,
+		OrganID:    roster.OrganID,
+		RosterID:   roster.ID,
+		RosterName: roster.Name,
+		Date:       roster.Date,
+	}
+}
+
+func shiftName(shift *models.SavedShift) string {
+	if shift.RosterShift == nil {
+		return ""
+	}
+	return shift.RosterShift.Name
+}
+
+func shiftOrder(shift *models.SavedShift) uint {
+	if shift.RosterShift == nil {
+		return 0
+	}
+	return shift.RosterShift.Order
+}
@@ -0,0 +1,168 @@
+package me
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"testing"
+	"time"
+)
+
+type TestMeSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	service service
+	user    models.User
+}
+
+func (suite *TestMeSuite) SetupTest() {
+	db := seeder.Seeder(":memory:")
+	suite.db = db
+	suite.service = service{db: db}
+
+	suite.user = models.User{Name: "Dashboard", GEWISID: ptr(uint(9100))}
+	db.Create(&suite.user)
+	db.Create(&models.UserOrgan{UserID: suite.user.ID, OrganID: 1, Username: "dash", Role: models.RoleMember})
+}
+
+func (suite *TestMeSuite) createRoster(roster models.Roster, shifts ...string) *models.Roster {
+	roster.OrganID = 1
+	for i, name := range shifts {
+		roster.RosterShift = append(roster.RosterShift, models.RosterShift{Name: name, Order: uint(i)})
+	}
+	suite.NoError(suite.db.Create(&roster).Error)
+	return &roster
+}
+
+func (suite *TestMeSuite) saveShift(roster *models.Roster, capacity uint, users ...*models.User) *models.SavedShift {
+	saved := models.SavedShift{RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Capacity: capacity, Users: users}
+	suite.NoError(suite.db.Create(&saved).Error)
+	return &saved
+}
+
+func (suite *TestMeSuite) TestGetDashboard_Memberships() {
+	dashboard, err := suite.service.GetDashboard(suite.user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.user.ID, dashboard.User.ID)
+	assert.Len(suite.T(), dashboard.Organs, 1)
+	assert.Equal(suite.T(), "dash", dashboard.Organs[0].Username)
+	assert.Equal(suite.T(), models.RoleMember, dashboard.Organs[0].Role)
+	assert.Equal(suite.T(), models.BuiltinRoles[models.RoleMember], dashboard.Organs[0].Permissions)
+
+	// Former members no longer see the organ
+	suite.db.Model(&models.UserOrgan{}).Where("user_id = ?", suite.user.ID).Update("status", models.MembershipAlumni)
+	dashboard, err = suite.service.GetDashboard(suite.user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), dashboard.Organs)
+	assert.Empty(suite.T(), dashboard.AwaitingAnswer)
+
+	_, err = suite.service.GetDashboard(999)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+}
+
+func (suite *TestMeSuite) TestGetDashboard_AwaitingAnswer() {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	open := suite.createRoster(models.Roster{Name: "Open", Date: tomorrow}, "Bar", "Kitchen")
+	answered := suite.createRoster(models.Roster{Name: "Answered", Date: tomorrow}, "Bar")
+	past := suite.createRoster(models.Roster{Name: "Past", Date: time.Now().Add(-72 * time.Hour)}, "Bar")
+
+	suite.db.Create(&models.RosterAnswer{UserID: suite.user.ID, RosterID: open.ID, RosterShiftID: open.RosterShift[0].ID, Value: "J"})
+	suite.db.Create(&models.RosterAnswer{UserID: suite.user.ID, RosterID: answered.ID, RosterShiftID: answered.RosterShift[0].ID, Value: "J"})
+
+	dashboard, err := suite.service.GetDashboard(suite.user.ID)
+	assert.NoError(suite.T(), err)
+
+	awaiting := map[uint]*RosterSummary{}
+	for _, roster := range dashboard.AwaitingAnswer {
+		awaiting[roster.RosterID] = roster
+	}
+	assert.Contains(suite.T(), awaiting, open.ID)
+	assert.Equal(suite.T(), 2, awaiting[open.ID].Shifts)
+	assert.Equal(suite.T(), 1, awaiting[open.ID].Answered)
+	assert.NotContains(suite.T(), awaiting, answered.ID)
+	assert.NotContains(suite.T(), awaiting, past.ID)
+
+	// Members cannot save rosters
+	for _, action := range dashboard.PendingActions {
+		assert.NotEqual(suite.T(), ActionSaveRoster, action.Type)
+	}
+}
+
+func (suite *TestMeSuite) TestGetDashboard_UpcomingShiftsAndActions() {
+	tomorrow := time.Now().Add(24 * time.Hour)
+	published := suite.createRoster(models.Roster{Name: "Published", Date: tomorrow, Saved: true, Published: true}, "Bar")
+	hidden := suite.createRoster(models.Roster{Name: "Hidden", Date: tomorrow, Saved: true}, "Bar")
+	signup := suite.createRoster(models.Roster{Name: "Signup", Date: tomorrow, Saved: true, Mode: models.ModeSelfSignup}, "Bar")
+
+	assignedShift := suite.saveShift(published, 0, &suite.user)
+	suite.saveShift(hidden, 0, &suite.user)
+	openShift := suite.saveShift(signup, 2)
+
+	dashboard, err := suite.service.GetDashboard(suite.user.ID)
+	assert.NoError(suite.T(), err)
+
+	assert.Len(suite.T(), dashboard.UpcomingShifts, 1)
+	assert.Equal(suite.T(), assignedShift.ID, dashboard.UpcomingShifts[0].SavedShiftID)
+	assert.Equal(suite.T(), "Bar", dashboard.UpcomingShifts[0].ShiftName)
+
+	var claim *PendingAction
+	for _, action := range dashboard.PendingActions {
+		assert.NotEqual(suite.T(), ActionPublishRoster, action.Type)
+		if action.Type == ActionClaimShift {
+			claim = action
+		}
+	}
+	if assert.NotNil(suite.T(), claim) {
+		assert.Equal(suite.T(), openShift.ID, *claim.SavedShiftID)
+	}
+
+	// Admins are asked to publish the hidden roster
+	suite.db.Model(&models.UserOrgan{}).Where("user_id = ?", suite.user.ID).Update("role", models.RoleAdmin)
+	dashboard, err = suite.service.GetDashboard(suite.user.ID)
+	assert.NoError(suite.T(), err)
+
+	var publish []uint
+	for _, action := range dashboard.PendingActions {
+		if action.Type == ActionPublishRoster {
+			publish = append(publish, action.RosterID)
+		}
+	}
+	assert.Contains(suite.T(), publish, hidden.ID)
+	assert.NotContains(suite.T(), publish, published.ID)
+}
+
+func (suite *TestMeSuite) TestExport_OnlyOwnData() {
+	roster := suite.createRoster(models.Roster{Name: "Exported", Date: time.Now()}, "Bar")
+	suite.db.Create(&models.RosterAnswer{UserID: suite.user.ID, RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Value: "J"})
+
+	var other models.User
+	suite.db.Where("id <> ?", suite.user.ID).First(&other)
+	shift := suite.saveShift(roster, 0, &suite.user, &other)
+	suite.db.Create(&models.PersonalAccessToken{UserID: suite.user.ID, Name: "script", TokenHash: "secret"})
+
+	export, err := suite.service.Export(suite.user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.user.ID, export.User.ID)
+	assert.Len(suite.T(), export.Memberships, 1)
+	assert.Len(suite.T(), export.Answers, 1)
+	assert.Len(suite.T(), export.AccessTokens, 1)
+
+	// Saved shifts are exported without the other members on them
+	if assert.Len(suite.T(), export.Shifts, 1) {
+		assert.Equal(suite.T(), shift.ID, export.Shifts[0].ID)
+		assert.Empty(suite.T(), export.Shifts[0].Users)
+	}
+
+	_, err = suite.service.Export(999)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+func TestMeService(t *testing.T) {
+	suite.Run(t, new(TestMeSuite))
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+)
+
+// TokenScope limits what a personal access token may do.
+// @name TokenScope
+type TokenScope string
+
+const (
+	TokenScopeRead  TokenScope = "read"
+	TokenScopeWrite TokenScope = "write"
+)
+
+// PersonalAccessToken
+// @Description A token for scripts and bots acting as a user. Only the SHA-256 hash of the token is stored.
+type PersonalAccessToken struct {
+	BaseModel
+
+	UserID uint `json:"userId" gorm:"index"`
+
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+
+	Name string `json:"name" gorm:"type:varchar(100)"`
+
+	// Prefix is the start of the token, so users can tell their tokens apart
+	Prefix string `json:"prefix" gorm:"type:varchar(12)"`
+
+	TokenHash string `json:"-" gorm:"type:char(64);uniqueIndex"`
+
+	Scope TokenScope `json:"scope" gorm:"type:varchar(10)"`
+
+	// Organs the token is limited to, all organs of the user when empty
+	Organs []Organ `json:"organs" gorm:"many2many:personal_access_token_organs;constraint:OnDelete:CASCADE;"`
+
+	ExpiresAt *time.Time `json:"expiresAt"`
+
+	LastUsedAt *time.Time `json:"lastUsedAt"`
+
+	RevokedAt *time.Time `json:"revokedAt"`
+} // @name PersonalAccessToken
@@ -0,0 +1,24 @@
+package models
+
+// AuditLog
+// @Description An action taken by a platform admin while impersonating a user.
+type AuditLog struct {
+	BaseModel
+
+	// ActorID is the admin that took the action
+	ActorID uint `json:"actorId" gorm:"index"`
+
+	// UserID is the user the admin acted as
+	UserID uint `json:"userId" gorm:"index"`
+
+	SessionID *uint `json:"sessionId" gorm:"default:null"`
+
+	Method string `json:"method" gorm:"type:varchar(10)"`
+
+	// Route is the route pattern, Path the requested path
+	Route string `json:"route" gorm:"type:varchar(255)"`
+
+	Path string `json:"path" gorm:"type:varchar(255)"`
+
+	Status int `json:"status"`
+} // @name AuditLog
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// NotificationType tells the frontend what a notification is about.
+// @name NotificationType
+type NotificationType string
+
+const (
+	NotificationAnswersEntered NotificationType = "answers_entered"
+)
+
+// Notification
+// @Description A message for a user about something that happened on their behalf.
+type Notification struct {
+	BaseModel
+
+	UserID uint `json:"userId" gorm:"index"`
+
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+
+	Type NotificationType `json:"type" gorm:"type:varchar(50)"`
+
+	Message string `json:"message" gorm:"type:varchar(255)"`
+
+	RosterID *uint `json:"rosterId" gorm:"default:null"`
+
+	ReadAt *time.Time `json:"readAt"`
+} // @name Notification
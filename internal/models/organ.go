@@ -1,5 +1,10 @@
 package models
 
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
 // Organ
 // @Description An organ that users can be part of.
 type Organ struct {
@@ -8,6 +13,10 @@ type Organ struct {
 	Name string `json:"name" gorm:"uniqueIndex"`
 
 	Users []*User `json:"users" gorm:"many2many:user_organs;"`
+
+	// ArchivedAt is set for dissolved organs. The login sync no longer adds
+	// members to archived organs.
+	ArchivedAt *time.Time `json:"archivedAt"`
 } // @name Organ
 
 type OrganRole string
@@ -24,6 +33,22 @@ var RoleWeights = map[OrganRole]int{
 	RoleOwner:  3,
 }
 
+// MembershipStatus tells whether a member currently takes part in an organ.
+// @name MembershipStatus
+type MembershipStatus string
+
+const (
+	// MembershipActive members have access to the organ and are rostered.
+	MembershipActive MembershipStatus = "active"
+	// MembershipInactive members are away for a while, e.g. abroad, and are
+	// not rostered until they become active again.
+	MembershipInactive MembershipStatus = "inactive"
+	// MembershipAlumni members have left the organ. They are kept for history.
+	MembershipAlumni MembershipStatus = "alumni"
+)
+
+// UserOrgan
+// @Description A membership of a user in an organ. Memberships are never deleted, members who leave keep their row with another status so historic assignments keep their context.
 type UserOrgan struct {
 	UserID uint `json:"userId"  gorm:"primaryKey"`
 
@@ -31,5 +56,52 @@ type UserOrgan struct {
 
 	Username string `json:"username" gorm:"size:25"`
 
-	Role OrganRole `json:"role" gorm:"type:varchar(20);default:'member'"`
+	Role OrganRole `json:"role" gorm:"type:varchar(50);default:'member'"`
+
+	// ManualRole is set when an admin changed the role by hand, so the login
+	// sync with the identity provider can keep it
+	ManualRole bool `json:"manualRole" gorm:"default:false"`
+
+	Status MembershipStatus `json:"status" gorm:"type:varchar(20);default:'active';index"`
+
+	ActiveFrom *time.Time `json:"activeFrom"`
+
+	// ActiveUntil is set when the member stopped being active
+	ActiveUntil *time.Time `json:"activeUntil"`
 } // @name UserOrgan
+
+// BeforeCreate marks new memberships as active from now.
+func (uo *UserOrgan) BeforeCreate(tx *gorm.DB) error {
+	if uo.Status == "" {
+		uo.Status = MembershipActive
+	}
+	if uo.ActiveFrom == nil && uo.Status == MembershipActive {
+		now := time.Now()
+		uo.ActiveFrom = &now
+	}
+	return nil
+}
+
+// StatusChange returns the column updates that move the membership to status.
+// Becoming active starts a new active period, leaving the active status ends it.
+func (uo *UserOrgan) StatusChange(status MembershipStatus) map[string]interface{} {
+	updates := map[string]interface{}{"status": status}
+	if status == uo.Status {
+		return updates
+	}
+
+	now := time.Now()
+	switch {
+	case status == MembershipActive:
+		updates["active_from"] = now
+		updates["active_until"] = nil
+	case uo.Status == MembershipActive:
+		updates["active_until"] = now
+	}
+	return updates
+}
+
+// ActiveMembers limits a query on user_organs to active memberships.
+func ActiveMembers(db *gorm.DB) *gorm.DB {
+	return db.Where("user_organs.status = ?", MembershipActive)
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"image"
+	"image/png"
+	"time"
+)
+
+// MaxLogoDimension caps the width and height of an export logo. The header
+// draws it a few dozen pixels high, and decoding allocates the full image.
+const MaxLogoDimension = 1024
+
+// OrderingRule decides in which order members are suggested for a saved shift.
+// @name OrderingRule
+type OrderingRule string
+
+const (
+	// OrderingPriority puts members with a higher shift group priority first,
+	// then those assigned least recently.
+	OrderingPriority OrderingRule = "priority"
+	// OrderingLeastRecent puts members assigned least recently first.
+	OrderingLeastRecent OrderingRule = "least_recent"
+	// OrderingFewestShifts puts members with the fewest assignments first.
+	OrderingFewestShifts OrderingRule = "fewest_shifts"
+)
+
+// OrganSettings
+// @Description Defaults of an organ for new rosters, the assignment ordering and the export.
+type OrganSettings struct {
+	OrganID uint `json:"organId" gorm:"primaryKey;autoIncrement:false"`
+
+	Organ *Organ `json:"-" gorm:"foreignKey:OrganID;constraint:OnDelete:CASCADE;"`
+
+	// AnswerValues are the values members can answer on new rosters
+	AnswerValues Values `json:"answerValues" gorm:"serializer:json"`
+
+	// DefaultTemplateID is used for new rosters that do not name a template
+	DefaultTemplateID *uint `json:"defaultTemplateId"`
+
+	DefaultTemplate *RosterTemplate `json:"-" gorm:"foreignKey:DefaultTemplateID;constraint:OnDelete:SET NULL;"`
+
+	// Timezone is the IANA name of the zone roster dates are compared in
+	Timezone string `json:"timezone" gorm:"type:varchar(64)"`
+
+	OrderingRule OrderingRule `json:"orderingRule" gorm:"type:varchar(20)"`
+
+	ExportHeaderColor string `json:"exportHeaderColor" gorm:"type:varchar(7)"`
+
+	ExportHeaderTextColor string `json:"exportHeaderTextColor" gorm:"type:varchar(7)"`
+
+	ExportStripeColor string `json:"exportStripeColor" gorm:"type:varchar(7)"`
+
+	ExportTextColor string `json:"exportTextColor" gorm:"type:varchar(7)"`
+
+	// ExportLogo is a PNG drawn in the header of exports, base64 encoded in JSON
+	ExportLogo []byte `json:"exportLogo" gorm:"type:mediumblob"`
+
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+} // @name OrganSettings
+
+// DefaultOrganSettings returns the settings of organs that did not change them.
+func DefaultOrganSettings(organID uint) *OrganSettings {
+	return &OrganSettings{
+		OrganID:               organID,
+		AnswerValues:          Values{"J", "X", "L", "N"},
+		Timezone:              "Europe/Amsterdam",
+		OrderingRule:          OrderingPriority,
+		ExportHeaderColor:     "#f3f4f6",
+		ExportHeaderTextColor: "#374151",
+		ExportStripeColor:     "#f9fafb",
+		ExportTextColor:       "#111827",
+	}
+}
+
+// LoadOrganSettings returns the settings of the organ, or the defaults when
+// the organ has none.
+func LoadOrganSettings(db *gorm.DB, organID uint) (*OrganSettings, error) {
+	var settings OrganSettings
+	err := db.Where("organ_id = ?", organID).First(&settings).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return DefaultOrganSettings(organID), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// DecodeExportLogo decodes the export logo. The size in the PNG header is
+// checked first, so a small file declaring huge dimensions is refused before
+// its pixels are allocated.
+func DecodeExportLogo(logo []byte) (image.Image, error) {
+	config, err := png.DecodeConfig(bytes.NewReader(logo))
+	if err != nil {
+		return nil, err
+	}
+	if config.Width > MaxLogoDimension || config.Height > MaxLogoDimension {
+		return nil, fmt.Errorf("logo of %dx%d exceeds %dx%d pixels", config.Width, config.Height, MaxLogoDimension, MaxLogoDimension)
+	}
+
+	return png.Decode(bytes.NewReader(logo))
+}
+
+// Location returns the timezone of the organ, falling back to UTC when the
+// zone is unknown on this system.
+func (s *OrganSettings) Location() *time.Location {
+	location, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+// Today returns the start of the current day in the timezone of the organ.
+// Roster dates before it are in the past for the organ.
+func (s *OrganSettings) Today() time.Time {
+	now := time.Now().In(s.Location())
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+}
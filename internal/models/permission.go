@@ -0,0 +1,107 @@
+package models
+
+import "slices"
+
+// Permission is a named action within an organ. Roles are sets of permissions.
+// @name Permission
+type Permission string
+
+const (
+	// PermRosterView allows viewing rosters, templates, shift groups and members.
+	PermRosterView Permission = "roster.view"
+	// PermShiftClaim allows claiming open shifts of self sign-up rosters.
+	PermShiftClaim Permission = "shift.claim"
+	// PermRosterCreate allows creating, editing and deleting rosters and their shifts.
+	PermRosterCreate Permission = "roster.create"
+	// PermRosterAssign allows filling and saving rosters, editing saved shifts
+	// and answers of others, and seeing unpublished schedules and answers.
+	PermRosterAssign Permission = "roster.assign"
+	// PermRosterExport allows exporting saved rosters.
+	PermRosterExport Permission = "roster.export"
+	// PermTemplateEdit allows editing templates, shift groups and the shift
+	// preferences of others.
+	PermTemplateEdit Permission = "template.edit"
+	// PermMemberManage allows editing the settings of other members and signing
+	// them out.
+	PermMemberManage Permission = "member.manage"
+	// PermMemberRole allows changing member roles and defining organ roles.
+	PermMemberRole Permission = "member.role"
+	// PermOrganSettings allows viewing and changing the organ settings.
+	PermOrganSettings Permission = "organ.settings"
+	// PermOrganManage allows renaming and archiving the organ.
+	PermOrganManage Permission = "organ.manage"
+)
+
+// Permissions lists every known permission.
+var Permissions = []Permission{
+	PermRosterView,
+	PermShiftClaim,
+	PermRosterCreate,
+	PermRosterAssign,
+	PermRosterExport,
+	PermTemplateEdit,
+	PermMemberManage,
+	PermMemberRole,
+	PermOrganSettings,
+	PermOrganManage,
+}
+
+// PermissionSet is stored as a JSON array.
+type PermissionSet []Permission
+
+// Has reports whether the set contains the permission.
+func (s PermissionSet) Has(permission Permission) bool {
+	return slices.Contains(s, permission)
+}
+
+// SubsetOf reports whether other contains every permission of the set.
+func (s PermissionSet) SubsetOf(other PermissionSet) bool {
+	for _, permission := range s {
+		if !other.Has(permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// Valid reports whether every permission in the set is known.
+func (s PermissionSet) Valid() bool {
+	for _, permission := range s {
+		if !slices.Contains(Permissions, permission) {
+			return false
+		}
+	}
+	return true
+}
+
+// BuiltinRoles are the roles every organ has. Organs can define more roles in
+// OrganRoleDefinition, but cannot redefine these.
+var BuiltinRoles = map[OrganRole]PermissionSet{
+	RoleMember: {PermRosterView, PermShiftClaim},
+	RoleAdmin: {
+		PermRosterView,
+		PermShiftClaim,
+		PermRosterCreate,
+		PermRosterAssign,
+		PermRosterExport,
+		PermTemplateEdit,
+		PermMemberManage,
+		PermMemberRole,
+		PermOrganSettings,
+	},
+	RoleOwner: Permissions,
+}
+
+// OrganRoleDefinition
+// @Description A custom role of an organ, defined as a set of permissions.
+type OrganRoleDefinition struct {
+	BaseModel
+
+	OrganID uint `json:"organId" gorm:"uniqueIndex:idx_organ_role_definitions_name"`
+
+	Organ *Organ `json:"-" gorm:"foreignKey:OrganID;constraint:OnDelete:CASCADE;"`
+
+	Name OrganRole `json:"name" gorm:"type:varchar(50);uniqueIndex:idx_organ_role_definitions_name"`
+
+	Permissions PermissionSet `json:"permissions" gorm:"serializer:json"`
+} // @name OrganRoleDefinition
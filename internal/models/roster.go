@@ -1,11 +1,23 @@
 package models
 
 import (
+	"gorm.io/gorm"
 	"time"
 )
 
 type Values []string
 
+// RosterMode determines how the saved shifts of a roster get filled.
+// @name RosterMode
+type RosterMode string
+
+const (
+	// ModeAssigned lets admins assign members to the saved shifts.
+	ModeAssigned RosterMode = "assigned"
+	// ModeSelfSignup lets members claim open saved shifts themselves.
+	ModeSelfSignup RosterMode = "self_signup"
+)
+
 type Roster struct {
 	BaseModel
 
@@ -28,6 +40,19 @@ type Roster struct {
 	Saved bool `json:"saved" gorm:"default:false"`
 
 	TemplateID *uint `json:"templateId" gorm:"foreignKey:TemplateID"`
+
+	Mode RosterMode `json:"mode" gorm:"type:varchar(20);default:'assigned'"`
+
+	WaitlistEnabled bool `json:"waitlistEnabled" gorm:"default:false"`
+
+	// ShiftCapacity is the capacity each shift gets when the roster is saved
+	ShiftCapacity uint `json:"shiftCapacity" gorm:"default:1"`
+
+	// Published makes the assignments visible to members of the organ
+	Published bool `json:"published" gorm:"default:false"`
+
+	// DeletedAt is set while the roster is in the trash
+	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 } // @name Roster
 
 type RosterShift struct {
@@ -60,8 +85,47 @@ type RosterAnswer struct {
 	RosterShift *RosterShift `json:"-" gorm:"foreignKey:RosterShiftID;constraint:OnDelete:CASCADE;"`
 
 	Value string `json:"value"`
+
+	// EnteredByID is set when an admin entered the answer on behalf of the member
+	EnteredByID *uint `json:"enteredById" gorm:"default:null"`
+
+	EnteredBy *User `json:"enteredBy,omitempty" gorm:"foreignKey:EnteredByID;constraint:OnDelete:SET NULL;"`
 } // @name RosterAnswer
 
+// AnswerSource tells who caused a change to a roster answer.
+// @name AnswerSource
+type AnswerSource string
+
+const (
+	SourceMember   AnswerSource = "member"
+	SourceAdmin    AnswerSource = "admin"
+	SourceTemplate AnswerSource = "template"
+)
+
+// RosterAnswerChange records a single create or update of a roster answer.
+// OldValue is nil when the answer was created.
+type RosterAnswerChange struct {
+	BaseModel
+
+	RosterAnswerID uint `json:"rosterAnswerId" gorm:"index"`
+
+	RosterID uint `json:"rosterId" gorm:"index"`
+
+	Roster *Roster `json:"-" gorm:"foreignKey:RosterID;constraint:OnDelete:CASCADE;"`
+
+	RosterShiftID uint `json:"rosterShiftId"`
+
+	UserID uint `json:"userId" gorm:"index"`
+
+	ActorID *uint `json:"actorId"`
+
+	OldValue *string `json:"oldValue"`
+
+	NewValue string `json:"newValue"`
+
+	Source AnswerSource `json:"source" gorm:"type:varchar(20)"`
+} // @name RosterAnswerChange
+
 type SavedShift struct {
 	BaseModel
 
@@ -72,8 +136,27 @@ type SavedShift struct {
 	RosterShift *RosterShift `json:"rosterShift" gorm:"foreignKey:RosterShiftID;constraint:OnDelete:CASCADE;"`
 
 	Users []*User `json:"users" gorm:"many2many:user_shift_saved;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+
+	// Capacity is the number of members that can claim this shift in self sign-up mode
+	Capacity uint `json:"capacity" gorm:"default:0"`
+
+	Waitlist []*SavedShiftWaitlistEntry `json:"waitlist" gorm:"foreignKey:SavedShiftID;constraint:OnDelete:CASCADE;"`
 } // @name SavedShift
 
+// SavedShiftWaitlistEntry is a member waiting for a slot on a full saved shift.
+// Entries are promoted in order of creation.
+type SavedShiftWaitlistEntry struct {
+	BaseModel
+
+	SavedShiftID uint `json:"savedShiftId" gorm:"uniqueIndex:idx_waitlist_user"`
+
+	SavedShift *SavedShift `json:"-" gorm:"foreignKey:SavedShiftID;constraint:OnDelete:CASCADE;"`
+
+	UserID uint `json:"userId" gorm:"uniqueIndex:idx_waitlist_user"`
+
+	User *User `json:"user" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+} // @name SavedShiftWaitlistEntry
+
 type SavedShiftOrdering struct {
 	ShiftName string `json:"shiftName"`
 
@@ -88,6 +171,9 @@ type RosterTemplate struct {
 	Name string `json:"name" gorm:"type:varchar(255)"`
 
 	Shifts []RosterTemplateShift `json:"shifts" gorm:"foreignKey:TemplateID;constraint:OnDelete:CASCADE;"`
+
+	// DeletedAt is set while the template is in the trash
+	DeletedAt gorm.DeletedAt `json:"deletedAt" gorm:"index"`
 } // @name RosterTemplate
 
 type RosterTemplateShift struct {
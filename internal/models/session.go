@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+)
+
+// Session
+// @Description A login of a user. Access tokens name their session, so revoking it signs the user out.
+type Session struct {
+	BaseModel
+
+	UserID uint `json:"userId" gorm:"index"`
+
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+
+	// ImpersonatorID is the platform admin acting as the user in this session
+	ImpersonatorID *uint `json:"impersonatorId" gorm:"default:null"`
+
+	Impersonator *User `json:"-" gorm:"foreignKey:ImpersonatorID;constraint:OnDelete:CASCADE;"`
+
+	RevokedAt *time.Time `json:"revokedAt"`
+} // @name Session
+
+// RefreshToken is a single use token that renews the access token of a
+// session. Only the SHA-256 hash of the token is stored.
+type RefreshToken struct {
+	BaseModel
+
+	SessionID uint `json:"-" gorm:"index"`
+
+	Session *Session `json:"-" gorm:"foreignKey:SessionID;constraint:OnDelete:CASCADE;"`
+
+	TokenHash string `json:"-" gorm:"type:char(64);uniqueIndex"`
+
+	ExpiresAt time.Time `json:"-"`
+
+	UsedAt *time.Time `json:"-"`
+}
+
+// AuthCode is a short-lived, single use code that the frontend exchanges for
+// the tokens of a new session after login. Only the SHA-256 hash of the code
+// is stored.
+type AuthCode struct {
+	BaseModel
+
+	UserID uint `json:"-" gorm:"index"`
+
+	User *User `json:"-" gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE;"`
+
+	CodeHash string `json:"-" gorm:"type:char(64);uniqueIndex"`
+
+	ExpiresAt time.Time `json:"-"`
+
+	UsedAt *time.Time `json:"-"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 // User model
 // This model defines a user which can input date into a roster
 type User struct {
@@ -7,9 +9,25 @@ type User struct {
 
 	Name string `json:"name" gorm:"type:varchar(255)"`
 
-	GEWISID uint `json:"gewis_id" gorm:"uniqueIndex:idx_name"`
+	// GEWISID is cleared when the user is anonymised, guests have none
+	GEWISID *uint `json:"gewis_id" gorm:"uniqueIndex:idx_name"`
+
+	// Guest users are external volunteers added by organ admins. They cannot
+	// log in, so the login sync never changes their memberships.
+	Guest bool `json:"guest" gorm:"default:false"`
+
+	// Email is how organ admins reach a guest. It is only exposed to them.
+	Email *string `json:"-" gorm:"type:varchar(255)"`
 
 	Organs []Organ `json:"organs" gorm:"many2many:user_organs;"`
 
+	// PlatformAdmin grants access to every organ, it is set on login from
+	// PLATFORM_ADMIN_IDS or the identity provider
+	PlatformAdmin bool `json:"platformAdmin" gorm:"default:false"`
+
+	// AnonymizedAt is set once the personal data of the user is replaced by a
+	// tombstone. The user is kept so historic rosters stay complete.
+	AnonymizedAt *time.Time `json:"anonymizedAt"`
+
 	Shifts []*SavedShift `gorm:"many2many:user_shift_saved;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
 } // @name User
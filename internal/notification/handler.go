@@ -0,0 +1,100 @@
+package notification
+
+import (
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	notificationService Service
+}
+
+func NewNotificationHandler(rg *authz.Router, notificationService Service) *Handler {
+	h := &Handler{notificationService: notificationService}
+
+	g := rg.Group("/notification")
+
+	// Notifications are scoped to the authenticated user by the service
+	g.GET("", authz.Authenticated, h.GetNotifications)
+	g.PATCH("/:id/read", authz.Authenticated, h.MarkRead)
+
+	return h
+}
+
+// GetNotifications
+//
+//	@Summary		Get the notifications of the current user
+//	@Security		BearerAuth
+//	@Description	Lists the notifications of the authenticated user, newest first
+//	@Tags			Notification
+//	@Accept			json
+//	@Produce		json
+//	@Param			unread	query		bool	false	"Only unread (true) or read (false) notifications"
+//	@Success		200		{array}		models.Notification
+//	@Failure		400		{object}	map[string]string
+//	@ID				getNotifications
+//	@Router			/notification [get]
+func (h *Handler) GetNotifications(c *gin.Context) {
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var filters FilterParams
+	if err := c.ShouldBindQuery(&filters); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	notifications, err := h.notificationService.Get(userID.(uint), &filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notifications)
+}
+
+// MarkRead
+//
+//	@Summary	Mark a notification as read
+//	@Security	BearerAuth
+//	@Tags		Notification
+//	@Accept		json
+//	@Produce	json
+//	@Param		id	path		uint	true	"Notification ID"
+//	@Success	200	{object}	models.Notification
+//	@Failure	400	{object}	map[string]string
+//	@Failure	404	{object}	map[string]string
+//	@ID			markNotificationRead
+//	@Router		/notification/{id}/read [patch]
+func (h *Handler) MarkRead(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid notification ID"})
+		return
+	}
+
+	userID, ok := c.Get("userID")
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(uint(id), userID.(uint))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, notification)
+}
@@ -0,0 +1,5 @@
+package notification
+
+type FilterParams struct {
+	Unread *bool `form:"unread"`
+} // @name NotificationFilterParams
@@ -0,0 +1,63 @@
+package notification
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"gorm.io/gorm"
+	"time"
+)
+
+type Service interface {
+	Notify(notification *models.Notification) error
+	Get(userID uint, filters *FilterParams) ([]*models.Notification, error)
+	MarkRead(ID uint, userID uint) (*models.Notification, error)
+}
+
+type service struct {
+	db *gorm.DB
+}
+
+func NewNotificationService(db *gorm.DB) Service {
+	return &service{db: db}
+}
+
+func (s *service) Notify(notification *models.Notification) error {
+	return s.db.Create(notification).Error
+}
+
+func (s *service) Get(userID uint, filters *FilterParams) ([]*models.Notification, error) {
+	db := s.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+
+	if filters != nil && filters.Unread != nil {
+		if *filters.Unread {
+			db = db.Where("read_at IS NULL")
+		} else {
+			db = db.Where("read_at IS NOT NULL")
+		}
+	}
+
+	var notifications []*models.Notification
+	if err := db.Order("created_at DESC, id DESC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+
+	return notifications, nil
+}
+
+// MarkRead marks a notification of the given user as read. Notifications of
+// other users are reported as not found.
+func (s *service) MarkRead(ID uint, userID uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.Where("id = ? AND user_id = ?", ID, userID).First(&notification).Error; err != nil {
+		return nil, err
+	}
+
+	if notification.ReadAt == nil {
+		now := time.Now()
+		if err := s.db.Model(&notification).Update("read_at", now).Error; err != nil {
+			return nil, err
+		}
+		notification.ReadAt = &now
+	}
+
+	return &notification, nil
+}
@@ -3,6 +3,7 @@ package organ
 import (
 	"GEWIS-Rooster/internal/models"
 	_ "GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
@@ -10,33 +11,64 @@ import (
 	"strconv"
 )
 
+// SessionRevoker signs a user out of all their sessions.
+type SessionRevoker interface {
+	RevokeUserSessions(userID uint) (int64, error)
+}
+
 type Handler struct {
 	organService Service
+	sessions     SessionRevoker
 }
 
-func NewOrganHandler(rg *gin.RouterGroup, organService Service, db *gorm.DB) *Handler {
-	h := &Handler{organService: organService}
+func NewOrganHandler(rg *authz.Router, organService Service, sessions SessionRevoker) *Handler {
+	h := &Handler{organService: organService, sessions: sessions}
 
 	g := rg.Group("/organ")
 
-	g.GET("/:id", h.GetMembersSettings)
-	g.GET("/:id/member/:userId", h.GetMemberSettings)
-	g.PATCH("/:id/member/:userId", h.UpdateMemberSettings)
+	g.GET("/:id", authz.Require(authz.OrganParam("id"), models.PermRosterView), h.GetMembersSettings)
+	g.GET("/:id/member/:userId", authz.Require(authz.OrganParam("id"), models.PermRosterView), h.GetMemberSettings)
+	// Members may change their own settings, settings of others require member.manage
+	g.PATCH("/:id/member/:userId", authz.Require(memberParam(), models.PermMemberManage), h.UpdateMemberSettings)
 
-	g.PATCH("/:id/member/:userId/role", requireRosterOrganMemberRoleParam(db, models.RoleAdmin), h.UpdateMemberRole)
+	g.PATCH("/:id/member/:userId/role", authz.Require(authz.OrganParam("id"), models.PermMemberRole).Sensitive(), h.UpdateMemberRole)
+	g.DELETE("/:id/member/:userId/sessions", authz.Require(authz.OrganParam("id"), models.PermMemberManage).Sensitive(), h.RevokeMemberSessions)
+
+	h.registerOrganRoutes(g)
+	h.registerRoleRoutes(g)
+	h.registerSettingsRoutes(g)
 
 	return h
 }
 
+// memberParam resolves the membership in the id and userId path parameters,
+// owned by the member it belongs to.
+func memberParam() authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		resource, err := authz.OrganParam("id")(c, db)
+		if err != nil {
+			return nil, err
+		}
+
+		userID, err := authz.ParseID(c.Param("userId"), "userId")
+		if err != nil {
+			return nil, err
+		}
+		resource.OwnerID = &userID
+		return resource, nil
+	}
+}
+
 // GetMembersSettings
 //
 //	@Summary      Get settings for all members within an organ
 //	@Security     BearerAuth
-//	@Description  Get organ-specific settings like nickname/username for all its members
+//	@Description  Get organ-specific settings like nickname/username for all its members. Only active members are listed unless another status, or all, is asked for.
 //	@Tags         Organ
 //	@Accept       json
 //	@Produce      json
 //	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        status         query     string                              false "Membership status"  Enums(active, inactive, alumni, all)
 //	@Success      200            {array}  models.UserOrgan
 //	@Failure      400            {string}  string
 //	@Failure 404 {string} string
@@ -49,7 +81,18 @@ func (o *Handler) GetMembersSettings(c *gin.Context) {
 		return
 	}
 
-	settings, err := o.organService.GetMembersSettings(uint(organID))
+	var status *models.MembershipStatus
+	switch query := c.DefaultQuery("status", string(models.MembershipActive)); query {
+	case "all":
+	case string(models.MembershipActive), string(models.MembershipInactive), string(models.MembershipAlumni):
+		membershipStatus := models.MembershipStatus(query)
+		status = &membershipStatus
+	default:
+		c.JSON(http.StatusBadRequest, "Invalid membership status")
+		return
+	}
+
+	settings, err := o.organService.GetMembersSettings(uint(organID), status)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Could not find Organ"})
@@ -157,7 +200,9 @@ func (o *Handler) UpdateMemberSettings(c *gin.Context) {
 //	@Param        updateParams   body      organ.UpdateMemberRoleParams   true  "Settings input"
 //	@Success      200            {object}  models.UserOrgan
 //	@Failure      400            {string}  string
+//	@Failure      403            {string}  string
 //	@Failure 	  404			 {string}  string
+//	@Failure      409            {string}  string
 //	@Router       /organ/{id}/member/{userId}/role [patch]
 func (o *Handler) UpdateMemberRole(c *gin.Context) {
 	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
@@ -178,11 +223,77 @@ func (o *Handler) UpdateMemberRole(c *gin.Context) {
 		return
 	}
 
-	result, err := o.organService.UpdateMemberRole(uint(organID), uint(userID), params)
+	member, err := o.organService.GetMemberSettings(uint(organID), uint(userID))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, err.Error())
+		writeOrganError(c, err)
+		return
+	}
+
+	if !o.requireOwnerFor(c, uint(organID), member.Role, params.Role) {
+		return
+	}
+
+	result, err := o.organService.UpdateMemberRole(uint(organID), uint(userID), params, authz.Permissions(c))
+	if err != nil {
+		writeOrganError(c, err)
 		return
 	}
 
 	c.JSON(http.StatusOK, result)
 }
+
+// RevokeMemberSessions
+//
+//	@Summary      Sign a member out everywhere
+//	@Security     BearerAuth
+//	@Description  Revoke all sessions of a member of the organ, e.g. when they leave or lose a device
+//	@Tags         Organ
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        userId         path      uint                                true  "User ID"
+//	@Success      200            {object}  map[string]int64
+//	@Failure      400            {string}  string
+//	@Failure      403            {string}  string
+//	@Failure      404            {string}  string
+//	@Router       /organ/{id}/member/{userId}/sessions [delete]
+func (o *Handler) RevokeMemberSessions(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid User ID")
+		return
+	}
+
+	// Admins may only sign out members of their own organ
+	member, err := o.organService.GetMemberSettings(uint(organID), uint(userID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Could not find member"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	// Signing someone out ends their sessions in every organ, so owners are
+	// only signed out by owners and platform admins by platform admins
+	if !o.requireOwnerFor(c, uint(organID), member.Role) {
+		return
+	}
+	if !o.requirePlatformAdminFor(c, uint(userID)) {
+		return
+	}
+
+	revoked, err := o.sessions.RevokeUserSessions(uint(userID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"revoked": revoked})
+}
@@ -0,0 +1,303 @@
+package organ
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+func (o *Handler) registerOrganRoutes(g *authz.Router) {
+	// Organs are usually created by the login sync, other organs are created by platform admins
+	g.POST("", authz.PlatformAdmin, o.CreateOrgan)
+	g.PATCH("/:id", authz.Require(authz.OrganParam("id"), models.PermOrganManage), o.UpdateOrgan)
+
+	g.POST("/:id/member", authz.Require(authz.OrganParam("id"), models.PermMemberManage), o.AddMember)
+	// Members may leave an organ themselves, removing others requires member.manage
+	g.DELETE("/:id/member/:userId", authz.Require(memberParam(), models.PermMemberManage).Sensitive(), o.RemoveMember)
+	g.PATCH("/:id/member/:userId/status", authz.Require(authz.OrganParam("id"), models.PermMemberManage), o.UpdateMemberStatus)
+}
+
+// CreateOrgan
+//
+//	@Summary      Create an organ
+//	@Security     BearerAuth
+//	@Description  Creates an organ with a first owner. Only platform admins can create organs.
+//	@Tags         Organ
+//	@Accept       json
+//	@Produce      json
+//	@Param        params         body      organ.CreateOrganParams             true  "Organ input"
+//	@Success      201            {object}  models.Organ
+//	@Failure      400            {string}  string
+//	@Failure      409            {string}  string
+//	@Router       /organ [post]
+func (o *Handler) CreateOrgan(c *gin.Context) {
+	var params CreateOrganParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	organ, err := o.organService.CreateOrgan(params)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Owner does not exist"})
+			return
+		}
+		writeOrganError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, organ)
+}
+
+// UpdateOrgan
+//
+//	@Summary      Rename, archive or restore an organ
+//	@Security     BearerAuth
+//	@Description  Renames the organ or archives a dissolved organ. The login sync does not add members to archived organs.
+//	@Tags         Organ
+//	@Accept       json
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        params         body      organ.UpdateOrganParams             true  "Organ input"
+//	@Success      200            {object}  models.Organ
+//	@Failure      400            {string}  string
+//	@Failure      404            {string}  string
+//	@Failure      409            {string}  string
+//	@Router       /organ/{id} [patch]
+func (o *Handler) UpdateOrgan(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	var params UpdateOrganParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	organ, err := o.organService.UpdateOrgan(uint(organID), params)
+	if err != nil {
+		writeOrganError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, organ)
+}
+
+// AddMember
+//
+//	@Summary      Add a member to an organ
+//	@Security     BearerAuth
+//	@Description  Adds a user by ID, or by GEWIS ID for users that have not logged in yet. Only owners can add owners.
+//	@Tags         Organ
+//	@Accept       json
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        params         body      organ.AddMemberParams               true  "Member input"
+//	@Success      201            {object}  models.UserOrgan
+//	@Failure      400            {string}  string
+//	@Failure      403            {string}  string
+//	@Failure      404            {string}  string
+//	@Failure      409            {string}  string
+//	@Router       /organ/{id}/member [post]
+func (o *Handler) AddMember(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	var params AddMemberParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !o.requireOwnerFor(c, uint(organID), params.Role) {
+		return
+	}
+
+	membership, err := o.organService.AddMember(uint(organID), params, authz.Permissions(c))
+	if err != nil {
+		writeOrganError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, membership)
+}
+
+// RemoveMember
+//
+//	@Summary      Remove a member from an organ
+//	@Security     BearerAuth
+//	@Description  Makes the member an alumnus of the organ, keeping their history. Only owners can remove owners, and the last owner cannot be removed.
+//	@Tags         Organ
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        userId         path      uint                                true  "User ID"
+//	@Success      204
+//	@Failure      400            {string}  string
+//	@Failure      403            {string}  string
+//	@Failure      404            {string}  string
+//	@Failure      409            {string}  string
+//	@Router       /organ/{id}/member/{userId} [delete]
+func (o *Handler) RemoveMember(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid User ID")
+		return
+	}
+
+	member, err := o.organService.GetMemberSettings(uint(organID), uint(userID))
+	if err != nil {
+		writeOrganError(c, err)
+		return
+	}
+
+	if !o.requireOwnerFor(c, uint(organID), member.Role) {
+		return
+	}
+
+	if err := o.organService.RemoveMember(uint(organID), uint(userID)); err != nil {
+		writeOrganError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// UpdateMemberStatus
+//
+//	@Summary      Change the membership status of a member
+//	@Security     BearerAuth
+//	@Description  Marks a member as active, inactive or alumni. Only active members have access to the organ and are included in new rosters and orderings. Only owners can change the status of owners, and the last active owner must stay active.
+//	@Tags         Organ
+//	@Accept       json
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        userId         path      uint                                true  "User ID"
+//	@Param        params         body      organ.UpdateMemberStatusParams      true  "Status input"
+//	@Success      200            {object}  models.UserOrgan
+//	@Failure      400            {string}  string
+//	@Failure      403            {string}  string
+//	@Failure      404            {string}  string
+//	@Failure      409            {string}  string
+//	@Router       /organ/{id}/member/{userId}/status [patch]
+func (o *Handler) UpdateMemberStatus(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	userID, err := strconv.ParseUint(c.Param("userId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid User ID")
+		return
+	}
+
+	var params UpdateMemberStatusParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	member, err := o.organService.GetMemberSettings(uint(organID), uint(userID))
+	if err != nil {
+		writeOrganError(c, err)
+		return
+	}
+
+	if !o.requireOwnerFor(c, uint(organID), member.Role) {
+		return
+	}
+
+	result, err := o.organService.SetMemberStatus(uint(organID), uint(userID), params.Status)
+	if err != nil {
+		writeOrganError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// requireOwner responds with 403 unless the caller is an owner of the organ.
+// Only owners may hand out or take away the owner role. Custom roles that
+// grant organ.manage count as owner.
+func requireOwner(c *gin.Context) bool {
+	if authz.HasPermission(c, models.PermOrganManage) {
+		return true
+	}
+
+	c.JSON(http.StatusForbidden, gin.H{"error": "Only owners can change owners"})
+	return false
+}
+
+// requireOwnerFor calls requireOwner when any of the roles is owner-level,
+// i.e. grants organ.manage like the owner role does.
+func (o *Handler) requireOwnerFor(c *gin.Context, organID uint, roles ...models.OrganRole) bool {
+	for _, role := range roles {
+		permissions, err := o.organService.RolePermissions(organID, role)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+			return false
+		}
+		if permissions.Has(models.PermOrganManage) {
+			return requireOwner(c)
+		}
+	}
+	return true
+}
+
+// requirePlatformAdminFor refuses the request when the target user is a
+// platform admin and the caller is not.
+func (o *Handler) requirePlatformAdminFor(c *gin.Context, targetID uint) bool {
+	targetAdmin, err := o.organService.IsPlatformAdmin(targetID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+	if !targetAdmin {
+		return true
+	}
+
+	callerID, _ := authz.UserID(c)
+	callerAdmin, err := o.organService.IsPlatformAdmin(callerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return false
+	}
+	if !callerAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only platform admins can do this to a platform admin"})
+		return false
+	}
+	return true
+}
+
+func writeOrganError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Could not find Organ or User"})
+	case errors.Is(err, ErrOrganExists), errors.Is(err, ErrAlreadyMember), errors.Is(err, ErrLastOwner):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrOrganArchived), errors.Is(err, ErrUnknownRole),
+		errors.Is(err, ErrMemberRequired), errors.Is(err, ErrNameRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrRoleEscalation):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+}
@@ -0,0 +1,161 @@
+package organ
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+func (o *Handler) registerRoleRoutes(g *authz.Router) {
+	g.GET("/:id/roles", authz.Require(authz.OrganParam("id"), models.PermRosterView), o.GetRoles)
+	g.POST("/:id/roles", authz.Require(authz.OrganParam("id"), models.PermMemberRole).Sensitive(), o.CreateRole)
+	g.PUT("/:id/roles/:role", authz.Require(authz.OrganParam("id"), models.PermMemberRole).Sensitive(), o.UpdateRole)
+	g.DELETE("/:id/roles/:role", authz.Require(authz.OrganParam("id"), models.PermMemberRole).Sensitive(), o.DeleteRole)
+}
+
+// GetRoles
+//
+//	@Summary      List the roles of an organ
+//	@Security     BearerAuth
+//	@Description  Lists the built-in roles and the roles defined by the organ, with their permissions
+//	@Tags         Organ
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Success      200            {array}   organ.Role
+//	@Failure      400            {string}  string
+//	@Router       /organ/{id}/roles [get]
+func (o *Handler) GetRoles(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	roles, err := o.organService.GetRoles(uint(organID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+		return
+	}
+
+	c.JSON(http.StatusOK, roles)
+}
+
+// CreateRole
+//
+//	@Summary      Define a role for an organ
+//	@Security     BearerAuth
+//	@Description  Defines a custom role as a set of permissions, e.g. a planner who can assign rosters but not edit templates
+//	@Tags         Organ
+//	@Accept       json
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        params         body      organ.RoleDefinitionParams          true  "Role definition"
+//	@Success      201            {object}  models.OrganRoleDefinition
+//	@Failure      400            {string}  string
+//	@Failure      409            {string}  string
+//	@Router       /organ/{id}/roles [post]
+func (o *Handler) CreateRole(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	var params RoleDefinitionParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	role, err := o.organService.CreateRole(uint(organID), params, authz.Permissions(c))
+	if err != nil {
+		writeRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, role)
+}
+
+// UpdateRole
+//
+//	@Summary      Change the permissions of an organ role
+//	@Security     BearerAuth
+//	@Description  Replaces the permissions of a custom role. Built-in roles cannot be changed.
+//	@Tags         Organ
+//	@Accept       json
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        role           path      string                              true  "Role name"
+//	@Param        params         body      organ.UpdateRoleDefinitionParams    true  "Permissions"
+//	@Success      200            {object}  models.OrganRoleDefinition
+//	@Failure      400            {string}  string
+//	@Failure      404            {string}  string
+//	@Router       /organ/{id}/roles/{role} [put]
+func (o *Handler) UpdateRole(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	var params UpdateRoleDefinitionParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	role, err := o.organService.UpdateRole(uint(organID), models.OrganRole(c.Param("role")), params, authz.Permissions(c))
+	if err != nil {
+		writeRoleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, role)
+}
+
+// DeleteRole
+//
+//	@Summary      Delete an organ role
+//	@Security     BearerAuth
+//	@Description  Deletes a custom role. Members holding the role must be given another role first.
+//	@Tags         Organ
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        role           path      string                              true  "Role name"
+//	@Success      204
+//	@Failure      400            {string}  string
+//	@Failure      404            {string}  string
+//	@Failure      409            {string}  string
+//	@Router       /organ/{id}/roles/{role} [delete]
+func (o *Handler) DeleteRole(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	if err := o.organService.DeleteRole(uint(organID), models.OrganRole(c.Param("role"))); err != nil {
+		writeRoleError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func writeRoleError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Role not found"})
+	case errors.Is(err, ErrRoleExists), errors.Is(err, ErrRoleInUse):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrBuiltinRole), errors.Is(err, ErrUnknownPermission):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrRoleEscalation):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+}
@@ -0,0 +1,92 @@
+package organ
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+func (o *Handler) registerSettingsRoutes(g *authz.Router) {
+	g.GET("/:id/settings", authz.Require(authz.OrganParam("id"), models.PermOrganSettings), o.GetSettings)
+	g.PATCH("/:id/settings", authz.Require(authz.OrganParam("id"), models.PermOrganSettings), o.UpdateSettings)
+}
+
+// GetSettings
+//
+//	@Summary      Get the settings of an organ
+//	@Security     BearerAuth
+//	@Description  Get the defaults of the organ for new rosters, the assignment ordering and the export
+//	@Tags         Organ
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Success      200            {object}  models.OrganSettings
+//	@Failure      400            {string}  string
+//	@Failure      404            {string}  string
+//	@Router       /organ/{id}/settings [get]
+func (o *Handler) GetSettings(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	settings, err := o.organService.GetSettings(uint(organID))
+	if err != nil {
+		writeSettingsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// UpdateSettings
+//
+//	@Summary      Update the settings of an organ
+//	@Security     BearerAuth
+//	@Description  Change the answer values, default template, timezone, ordering rule or export branding of the organ
+//	@Tags         Organ
+//	@Accept       json
+//	@Produce      json
+//	@Param        id             path      uint                                true  "Organ ID"
+//	@Param        params         body      organ.UpdateSettingsParams          true  "Settings input"
+//	@Success      200            {object}  models.OrganSettings
+//	@Failure      400            {string}  string
+//	@Failure      404            {string}  string
+//	@Router       /organ/{id}/settings [patch]
+func (o *Handler) UpdateSettings(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, "Invalid Organ ID")
+		return
+	}
+
+	var params UpdateSettingsParams
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	settings, err := o.organService.UpdateSettings(uint(organID), params)
+	if err != nil {
+		writeSettingsError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+func writeSettingsError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Could not find Organ"})
+	case errors.Is(err, ErrInvalidTimezone), errors.Is(err, ErrInvalidTemplate),
+		errors.Is(err, ErrInvalidLogo), errors.Is(err, ErrDuplicateValues):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Database error"})
+	}
+}
@@ -7,5 +7,77 @@ type UpdateMemberSettingsParams struct {
 } // @name UpdateMemberSettingsParams
 
 type UpdateMemberRoleParams struct {
-	Role models.OrganRole `json:"role" binding:"required,oneof=admin member owner"`
+	Role models.OrganRole `json:"role" binding:"required,max=50"`
 } // @name UpdateMemberRoleParams
+
+type RoleDefinitionParams struct {
+	Name models.OrganRole `json:"name" binding:"required,max=50"`
+
+	Permissions models.PermissionSet `json:"permissions" binding:"required"`
+} // @name RoleDefinitionParams
+
+type UpdateRoleDefinitionParams struct {
+	Permissions models.PermissionSet `json:"permissions" binding:"required"`
+} // @name UpdateRoleDefinitionParams
+
+// Role is a role members of an organ can have, either built in or defined by
+// the organ.
+type Role struct {
+	Name models.OrganRole `json:"name"`
+
+	Permissions models.PermissionSet `json:"permissions"`
+
+	Builtin bool `json:"builtin"`
+} // @name OrganRoleResponse
+
+type CreateOrganParams struct {
+	Name string `json:"name" binding:"required,max=255"`
+
+	// OwnerID is the user that becomes the first owner of the organ
+	OwnerID uint `json:"ownerId" binding:"required"`
+} // @name CreateOrganParams
+
+type UpdateOrganParams struct {
+	Name *string `json:"name" binding:"omitempty,min=1,max=255"`
+
+	Archived *bool `json:"archived"`
+} // @name UpdateOrganParams
+
+// AddMemberParams adds an existing user by UserID, or a user by GEWIS ID who
+// may not have logged in yet. Such users are created with Name and pick up
+// the membership on their first login.
+type AddMemberParams struct {
+	UserID *uint `json:"userId"`
+
+	GEWISID *uint `json:"gewisId"`
+
+	Name string `json:"name" binding:"max=255"`
+
+	Role models.OrganRole `json:"role" binding:"max=50"`
+} // @name AddMemberParams
+
+type UpdateMemberStatusParams struct {
+	Status models.MembershipStatus `json:"status" binding:"required,oneof=active inactive alumni"`
+} // @name UpdateMemberStatusParams
+
+// UpdateSettingsParams changes the given organ settings. A DefaultTemplateID
+// of 0 and an empty ExportLogo clear them.
+type UpdateSettingsParams struct {
+	AnswerValues *models.Values `json:"answerValues" binding:"omitempty,min=1,dive,required,max=10"`
+
+	DefaultTemplateID *uint `json:"defaultTemplateId"`
+
+	Timezone *string `json:"timezone"`
+
+	OrderingRule *models.OrderingRule `json:"orderingRule" binding:"omitempty,oneof=priority least_recent fewest_shifts"`
+
+	ExportHeaderColor *string `json:"exportHeaderColor" binding:"omitempty,hexcolor,max=7"`
+
+	ExportHeaderTextColor *string `json:"exportHeaderTextColor" binding:"omitempty,hexcolor,max=7"`
+
+	ExportStripeColor *string `json:"exportStripeColor" binding:"omitempty,hexcolor,max=7"`
+
+	ExportTextColor *string `json:"exportTextColor" binding:"omitempty,hexcolor,max=7"`
+
+	ExportLogo *[]byte `json:"exportLogo"`
+} // @name UpdateOrganSettingsParams
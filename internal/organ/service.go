@@ -7,10 +7,14 @@ import (
 )
 
 type Service interface {
-	GetMembersSettings(organID uint) ([]*models.UserOrgan, error)
+	GetMembersSettings(organID uint, status *models.MembershipStatus) ([]*models.UserOrgan, error)
 	GetMemberSettings(organID uint, userID uint) (*models.UserOrgan, error)
 	UpdateMemberSettings(organID uint, userID uint, params *UpdateMemberSettingsParams) (*models.UserOrgan, error)
-	UpdateMemberRole(organID uint, userID uint, params UpdateMemberRoleParams) (*models.UserOrgan, error)
+	UpdateMemberRole(organID uint, userID uint, params UpdateMemberRoleParams, granted models.PermissionSet) (*models.UserOrgan, error)
+	IsPlatformAdmin(userID uint) (bool, error)
+	RoleManager
+	OrganManager
+	SettingsManager
 }
 
 type service struct {
@@ -21,9 +25,16 @@ func NewOrganService(db *gorm.DB) Service {
 	return &service{db: db}
 }
 
-func (o *service) GetMembersSettings(organID uint) ([]*models.UserOrgan, error) {
+// GetMembersSettings returns the memberships of the organ, limited to the
+// given status unless it is nil.
+func (o *service) GetMembersSettings(organID uint, status *models.MembershipStatus) ([]*models.UserOrgan, error) {
+	db := o.db.Where("organ_id = ?", organID)
+	if status != nil {
+		db = db.Where("status = ?", *status)
+	}
+
 	var membersSettings []*models.UserOrgan
-	err := o.db.Where("organ_id = ?", organID).Find(&membersSettings).Error
+	err := db.Find(&membersSettings).Error
 
 	if err != nil {
 		return nil, err
@@ -46,6 +57,16 @@ func (o *service) GetMemberSettings(organID uint, userID uint) (*models.UserOrga
 	return &userSettings, nil
 }
 
+// IsPlatformAdmin reports whether the user is a platform admin, who has owner
+// access to every organ.
+func (o *service) IsPlatformAdmin(userID uint) (bool, error) {
+	var user models.User
+	if err := o.db.Select("id", "platform_admin").First(&user, userID).Error; err != nil {
+		return false, err
+	}
+	return user.PlatformAdmin, nil
+}
+
 func (o *service) UpdateMemberSettings(organID uint, userID uint, params *UpdateMemberSettingsParams) (*models.UserOrgan, error) {
 	updates := make(map[string]interface{})
 
@@ -83,22 +104,40 @@ func (o *service) UpdateMemberSettings(organID uint, userID uint, params *Update
 	return &updatedRecord, nil
 }
 
-func (o *service) UpdateMemberRole(organID uint, userID uint, params UpdateMemberRoleParams) (*models.UserOrgan, error) {
-	update := make(map[string]interface{})
-
-	update["role"] = params.Role
-
-	err := o.db.Model(&models.UserOrgan{}).
-		Where("organ_id = ? AND user_id = ?", organID, userID).
-		Updates(update).Error
-
-	if err != nil {
+// UpdateMemberRole gives the member another role, which may not grant more
+// than the caller holds, given by granted.
+func (o *service) UpdateMemberRole(organID uint, userID uint, params UpdateMemberRoleParams, granted models.PermissionSet) (*models.UserOrgan, error) {
+	if err := o.checkAssignable(organID, params.Role, granted); err != nil {
 		return nil, err
 	}
 
 	var updatedRecord models.UserOrgan
-	err = o.db.Where("organ_id = ? AND user_id = ?", organID, userID).
-		First(&updatedRecord).Error
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organ_id = ? AND user_id = ?", organID, userID).First(&updatedRecord).Error; err != nil {
+			return err
+		}
+
+		if updatedRecord.Role == models.RoleOwner && updatedRecord.Status == models.MembershipActive && params.Role != models.RoleOwner {
+			if err := CheckOtherOwners(tx, organID); err != nil {
+				return err
+			}
+		}
+
+		update := make(map[string]interface{})
+
+		update["role"] = params.Role
+		update["manual_role"] = true
+
+		err := tx.Model(&models.UserOrgan{}).
+			Where("organ_id = ? AND user_id = ?", organID, userID).
+			Updates(update).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Where("organ_id = ? AND user_id = ?", organID, userID).
+			First(&updatedRecord).Error
+	})
 
 	if err != nil {
 		return nil, err
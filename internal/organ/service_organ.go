@@ -0,0 +1,248 @@
+package organ
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"gorm.io/gorm"
+	"time"
+)
+
+var (
+	ErrOrganExists    = errors.New("an organ with this name already exists")
+	ErrOrganArchived  = errors.New("organ is archived")
+	ErrAlreadyMember  = errors.New("user is already a member of this organ")
+	ErrLastOwner      = errors.New("an organ must keep at least one owner")
+	ErrMemberRequired = errors.New("either userId or gewisId is required")
+	ErrNameRequired   = errors.New("name is required for users that have not logged in yet")
+)
+
+type OrganManager interface {
+	CreateOrgan(params CreateOrganParams) (*models.Organ, error)
+	UpdateOrgan(organID uint, params UpdateOrganParams) (*models.Organ, error)
+	AddMember(organID uint, params AddMemberParams, granted models.PermissionSet) (*models.UserOrgan, error)
+	RemoveMember(organID uint, userID uint) error
+	SetMemberStatus(organID uint, userID uint, status models.MembershipStatus) (*models.UserOrgan, error)
+}
+
+// CreateOrgan creates an organ with a first owner. The membership is marked as
+// set by hand, so the login sync keeps it.
+func (o *service) CreateOrgan(params CreateOrganParams) (*models.Organ, error) {
+	organ := models.Organ{Name: params.Name}
+
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		if err := checkOrganName(tx, params.Name, 0); err != nil {
+			return err
+		}
+
+		if err := tx.First(&models.User{}, params.OwnerID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&organ).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UserOrgan{
+			UserID:     params.OwnerID,
+			OrganID:    organ.ID,
+			Role:       models.RoleOwner,
+			ManualRole: true,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &organ, nil
+}
+
+// UpdateOrgan renames, archives or restores an organ.
+func (o *service) UpdateOrgan(organID uint, params UpdateOrganParams) (*models.Organ, error) {
+	var organ models.Organ
+
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&organ, organID).Error; err != nil {
+			return err
+		}
+
+		if params.Name != nil && *params.Name != organ.Name {
+			if err := checkOrganName(tx, *params.Name, organID); err != nil {
+				return err
+			}
+			organ.Name = *params.Name
+		}
+
+		if params.Archived != nil {
+			switch {
+			case *params.Archived && organ.ArchivedAt == nil:
+				now := time.Now()
+				organ.ArchivedAt = &now
+			case !*params.Archived:
+				organ.ArchivedAt = nil
+			}
+		}
+
+		return tx.Save(&organ).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &organ, nil
+}
+
+// AddMember adds a user to the organ, creating users that have not logged in
+// yet by their GEWIS ID. They are matched to that user on their first login.
+// The role may not grant more than the caller holds, given by granted.
+func (o *service) AddMember(organID uint, params AddMemberParams, granted models.PermissionSet) (*models.UserOrgan, error) {
+	role := params.Role
+	if role == "" {
+		role = models.RoleMember
+	}
+
+	if err := o.checkAssignable(organID, role, granted); err != nil {
+		return nil, err
+	}
+
+	membership := models.UserOrgan{OrganID: organID, Role: role, ManualRole: true}
+
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		var organ models.Organ
+		if err := tx.First(&organ, organID).Error; err != nil {
+			return err
+		}
+		if organ.ArchivedAt != nil {
+			return ErrOrganArchived
+		}
+
+		user, err := findOrCreateMember(tx, params)
+		if err != nil {
+			return err
+		}
+		membership.UserID = user.ID
+
+		var existing models.UserOrgan
+		err = tx.Where("organ_id = ? AND user_id = ?", organID, user.ID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return tx.Create(&membership).Error
+		}
+		if err != nil {
+			return err
+		}
+		if existing.Status == models.MembershipActive {
+			return ErrAlreadyMember
+		}
+
+		// Former members rejoin with their history kept
+		updates := existing.StatusChange(models.MembershipActive)
+		updates["role"] = role
+		updates["manual_role"] = true
+		if err := tx.Model(&models.UserOrgan{}).
+			Where("organ_id = ? AND user_id = ?", organID, user.ID).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Where("organ_id = ? AND user_id = ?", organID, user.ID).First(&membership).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// RemoveMember makes the user an alumnus of the organ. The membership is kept
+// so historic assignments keep their context. The last owner cannot leave.
+func (o *service) RemoveMember(organID uint, userID uint) error {
+	_, err := o.SetMemberStatus(organID, userID, models.MembershipAlumni)
+	return err
+}
+
+// SetMemberStatus changes the membership status. Only active members have
+// access to the organ and are included in new rosters, so the last active
+// owner must stay active.
+func (o *service) SetMemberStatus(organID uint, userID uint, status models.MembershipStatus) (*models.UserOrgan, error) {
+	var membership models.UserOrgan
+
+	err := o.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("organ_id = ? AND user_id = ?", organID, userID).First(&membership).Error; err != nil {
+			return err
+		}
+
+		if membership.Role == models.RoleOwner && membership.Status == models.MembershipActive && status != models.MembershipActive {
+			if err := CheckOtherOwners(tx, organID); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&models.UserOrgan{}).
+			Where("organ_id = ? AND user_id = ?", organID, userID).
+			Updates(membership.StatusChange(status)).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("organ_id = ? AND user_id = ?", organID, userID).First(&membership).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &membership, nil
+}
+
+// checkOrganName returns ErrOrganExists when another organ has the name.
+func checkOrganName(tx *gorm.DB, name string, organID uint) error {
+	var count int64
+	if err := tx.Model(&models.Organ{}).
+		Where("name = ? AND id <> ?", name, organID).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrOrganExists
+	}
+	return nil
+}
+
+// CheckOtherOwners returns ErrLastOwner unless the organ has more than one
+// active owner, so one of them can step down or leave.
+func CheckOtherOwners(tx *gorm.DB, organID uint) error {
+	var owners int64
+	if err := tx.Model(&models.UserOrgan{}).
+		Where("organ_id = ? AND role = ?", organID, models.RoleOwner).
+		Scopes(models.ActiveMembers).
+		Count(&owners).Error; err != nil {
+		return err
+	}
+	if owners <= 1 {
+		return ErrLastOwner
+	}
+	return nil
+}
+
+func findOrCreateMember(tx *gorm.DB, params AddMemberParams) (*models.User, error) {
+	var user models.User
+
+	switch {
+	case params.UserID != nil:
+		if err := tx.First(&user, *params.UserID).Error; err != nil {
+			return nil, err
+		}
+	case params.GEWISID != nil:
+		err := tx.Where("gewis_id = ?", *params.GEWISID).First(&user).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if params.Name == "" {
+				return nil, ErrNameRequired
+			}
+			user = models.User{Name: params.Name, GEWISID: params.GEWISID}
+			err = tx.Create(&user).Error
+		}
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, ErrMemberRequired
+	}
+
+	return &user, nil
+}
@@ -0,0 +1,170 @@
+package organ
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownRole       = errors.New("role is not defined in this organ")
+	ErrBuiltinRole       = errors.New("built-in roles cannot be changed")
+	ErrRoleExists        = errors.New("role already exists in this organ")
+	ErrRoleInUse         = errors.New("role is still assigned to members")
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrRoleEscalation    = errors.New("a role cannot grant permissions you do not have")
+)
+
+type RoleManager interface {
+	GetRoles(organID uint) ([]Role, error)
+	RolePermissions(organID uint, role models.OrganRole) (models.PermissionSet, error)
+	CreateRole(organID uint, params RoleDefinitionParams, granted models.PermissionSet) (*models.OrganRoleDefinition, error)
+	UpdateRole(organID uint, name models.OrganRole, params UpdateRoleDefinitionParams, granted models.PermissionSet) (*models.OrganRoleDefinition, error)
+	DeleteRole(organID uint, name models.OrganRole) error
+}
+
+// GetRoles returns the built-in roles followed by the roles the organ defined.
+func (o *service) GetRoles(organID uint) ([]Role, error) {
+	roles := []Role{
+		{Name: models.RoleOwner, Permissions: models.BuiltinRoles[models.RoleOwner], Builtin: true},
+		{Name: models.RoleAdmin, Permissions: models.BuiltinRoles[models.RoleAdmin], Builtin: true},
+		{Name: models.RoleMember, Permissions: models.BuiltinRoles[models.RoleMember], Builtin: true},
+	}
+
+	var definitions []models.OrganRoleDefinition
+	if err := o.db.Where("organ_id = ?", organID).Order("name ASC").Find(&definitions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, definition := range definitions {
+		roles = append(roles, Role{Name: definition.Name, Permissions: definition.Permissions})
+	}
+	return roles, nil
+}
+
+// RolePermissions returns the permissions of a built-in or custom role.
+func (o *service) RolePermissions(organID uint, role models.OrganRole) (models.PermissionSet, error) {
+	return authz.RolePermissions(o.db, organID, role)
+}
+
+// CreateRole defines a custom role. The role may only grant permissions the
+// caller holds, given by granted.
+func (o *service) CreateRole(organID uint, params RoleDefinitionParams, granted models.PermissionSet) (*models.OrganRoleDefinition, error) {
+	if _, builtin := models.BuiltinRoles[params.Name]; builtin {
+		return nil, ErrBuiltinRole
+	}
+	if !params.Permissions.Valid() {
+		return nil, ErrUnknownPermission
+	}
+	if !params.Permissions.SubsetOf(granted) {
+		return nil, ErrRoleEscalation
+	}
+
+	var existing int64
+	if err := o.db.Model(&models.OrganRoleDefinition{}).
+		Where("organ_id = ? AND name = ?", organID, params.Name).
+		Count(&existing).Error; err != nil {
+		return nil, err
+	}
+	if existing > 0 {
+		return nil, ErrRoleExists
+	}
+
+	definition := models.OrganRoleDefinition{
+		OrganID:     organID,
+		Name:        params.Name,
+		Permissions: params.Permissions,
+	}
+	if err := o.db.Create(&definition).Error; err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// UpdateRole replaces the permissions of a custom role. Both the current and
+// the new permissions must be held by the caller, so roles with more power
+// than the caller can neither be created nor changed.
+func (o *service) UpdateRole(organID uint, name models.OrganRole, params UpdateRoleDefinitionParams, granted models.PermissionSet) (*models.OrganRoleDefinition, error) {
+	if _, builtin := models.BuiltinRoles[name]; builtin {
+		return nil, ErrBuiltinRole
+	}
+	if !params.Permissions.Valid() {
+		return nil, ErrUnknownPermission
+	}
+
+	var definition models.OrganRoleDefinition
+	if err := o.db.Where("organ_id = ? AND name = ?", organID, name).First(&definition).Error; err != nil {
+		return nil, err
+	}
+	if !params.Permissions.SubsetOf(granted) || !definition.Permissions.SubsetOf(granted) {
+		return nil, ErrRoleEscalation
+	}
+
+	definition.Permissions = params.Permissions
+	if err := o.db.Save(&definition).Error; err != nil {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+// DeleteRole removes a role the organ defined. Members must be given another
+// role first, so nobody silently loses their permissions.
+func (o *service) DeleteRole(organID uint, name models.OrganRole) error {
+	if _, builtin := models.BuiltinRoles[name]; builtin {
+		return ErrBuiltinRole
+	}
+
+	return o.db.Transaction(func(tx *gorm.DB) error {
+		var definition models.OrganRoleDefinition
+		if err := tx.Where("organ_id = ? AND name = ?", organID, name).First(&definition).Error; err != nil {
+			return err
+		}
+
+		var members int64
+		if err := tx.Model(&models.UserOrgan{}).
+			Where("organ_id = ? AND role = ?", organID, name).
+			Count(&members).Error; err != nil {
+			return err
+		}
+		if members > 0 {
+			return ErrRoleInUse
+		}
+
+		return tx.Delete(&definition).Error
+	})
+}
+
+// checkAssignable returns ErrUnknownRole for roles the organ does not have and
+// ErrRoleEscalation for roles that grant more than the caller holds.
+func (o *service) checkAssignable(organID uint, role models.OrganRole, granted models.PermissionSet) error {
+	exists, err := o.roleExists(organID, role)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return ErrUnknownRole
+	}
+
+	permissions, err := o.RolePermissions(organID, role)
+	if err != nil {
+		return err
+	}
+	if !permissions.SubsetOf(granted) {
+		return ErrRoleEscalation
+	}
+	return nil
+}
+
+// roleExists reports whether the role is built in or defined by the organ.
+func (o *service) roleExists(organID uint, role models.OrganRole) (bool, error) {
+	if _, builtin := models.BuiltinRoles[role]; builtin {
+		return true, nil
+	}
+
+	var count int64
+	err := o.db.Model(&models.OrganRoleDefinition{}).
+		Where("organ_id = ? AND name = ?", organID, role).
+		Count(&count).Error
+	return count > 0, err
+}
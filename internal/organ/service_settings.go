@@ -0,0 +1,110 @@
+package organ
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"gorm.io/gorm/clause"
+	"slices"
+	"time"
+)
+
+// maxLogoSize keeps export logos small, they are loaded on every export.
+const maxLogoSize = 256 << 10
+
+var (
+	ErrInvalidTimezone = errors.New("unknown timezone")
+	ErrInvalidTemplate = errors.New("default template does not belong to this organ")
+	ErrInvalidLogo     = errors.New("export logo must be a PNG of at most 256 KiB and 1024x1024 pixels")
+	ErrDuplicateValues = errors.New("answer values must be unique")
+)
+
+type SettingsManager interface {
+	GetSettings(organID uint) (*models.OrganSettings, error)
+	UpdateSettings(organID uint, params UpdateSettingsParams) (*models.OrganSettings, error)
+}
+
+// GetSettings returns the settings of the organ, or the defaults when they
+// were never changed.
+func (o *service) GetSettings(organID uint) (*models.OrganSettings, error) {
+	if err := o.db.First(&models.Organ{}, organID).Error; err != nil {
+		return nil, err
+	}
+
+	return models.LoadOrganSettings(o.db, organID)
+}
+
+func (o *service) UpdateSettings(organID uint, params UpdateSettingsParams) (*models.OrganSettings, error) {
+	settings, err := o.GetSettings(organID)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.AnswerValues != nil {
+		values := slices.Clone(*params.AnswerValues)
+		slices.Sort(values)
+		if len(slices.Compact(values)) != len(*params.AnswerValues) {
+			return nil, ErrDuplicateValues
+		}
+		settings.AnswerValues = *params.AnswerValues
+	}
+
+	if params.DefaultTemplateID != nil {
+		if *params.DefaultTemplateID == 0 {
+			settings.DefaultTemplateID = nil
+		} else {
+			var template models.RosterTemplate
+			if err := o.db.First(&template, *params.DefaultTemplateID).Error; err != nil || template.OrganID != organID {
+				return nil, ErrInvalidTemplate
+			}
+			settings.DefaultTemplateID = params.DefaultTemplateID
+		}
+	}
+
+	if params.Timezone != nil {
+		if _, err := time.LoadLocation(*params.Timezone); err != nil || *params.Timezone == "" {
+			return nil, ErrInvalidTimezone
+		}
+		settings.Timezone = *params.Timezone
+	}
+
+	if params.OrderingRule != nil {
+		settings.OrderingRule = *params.OrderingRule
+	}
+	if params.ExportHeaderColor != nil {
+		settings.ExportHeaderColor = *params.ExportHeaderColor
+	}
+	if params.ExportHeaderTextColor != nil {
+		settings.ExportHeaderTextColor = *params.ExportHeaderTextColor
+	}
+	if params.ExportStripeColor != nil {
+		settings.ExportStripeColor = *params.ExportStripeColor
+	}
+	if params.ExportTextColor != nil {
+		settings.ExportTextColor = *params.ExportTextColor
+	}
+
+	if params.ExportLogo != nil {
+		logo := *params.ExportLogo
+		if len(logo) > 0 {
+			if len(logo) > maxLogoSize {
+				return nil, ErrInvalidLogo
+			}
+			if _, err := models.DecodeExportLogo(logo); err != nil {
+				return nil, ErrInvalidLogo
+			}
+		} else {
+			logo = nil
+		}
+		settings.ExportLogo = logo
+	}
+
+	err = o.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "organ_id"}},
+		UpdateAll: true,
+	}).Create(settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
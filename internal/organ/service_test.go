@@ -0,0 +1,287 @@
+package organ
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"bytes"
+	"encoding/binary"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"hash/crc32"
+	"image"
+	"image/png"
+	"testing"
+)
+
+// ownerPermissions is what the caller holds in most tests.
+var ownerPermissions = models.BuiltinRoles[models.RoleOwner]
+
+type TestOrganSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	service service
+}
+
+func (suite *TestOrganSuite) SetupTest() {
+	db := seeder.Seeder(":memory:")
+	suite.db = db
+	suite.service = service{db: db}
+
+	// Start every test with a single owner in organ 1
+	db.Model(&models.UserOrgan{}).Where("organ_id = ?", 1).Update("role", models.RoleMember)
+	db.Model(&models.UserOrgan{}).Where("organ_id = ? AND user_id = ?", 1, suite.owner()).Update("role", models.RoleOwner)
+}
+
+func (suite *TestOrganSuite) owner() uint {
+	var membership models.UserOrgan
+	suite.db.Where("organ_id = ?", 1).Order("user_id ASC").First(&membership)
+	return membership.UserID
+}
+
+func (suite *TestOrganSuite) TestCreateOrgan_AddsOwner() {
+	organ, err := suite.service.CreateOrgan(CreateOrganParams{Name: "Kascommissie", OwnerID: suite.owner()})
+	assert.NoError(suite.T(), err)
+
+	membership, err := suite.service.GetMemberSettings(organ.ID, suite.owner())
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.RoleOwner, membership.Role)
+	assert.True(suite.T(), membership.ManualRole)
+
+	_, err = suite.service.CreateOrgan(CreateOrganParams{Name: "Kascommissie", OwnerID: suite.owner()})
+	assert.ErrorIs(suite.T(), err, ErrOrganExists)
+}
+
+func (suite *TestOrganSuite) TestUpdateOrgan_RenameAndArchive() {
+	name := "Renamed"
+	archived := true
+	organ, err := suite.service.UpdateOrgan(1, UpdateOrganParams{Name: &name, Archived: &archived})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), "Renamed", organ.Name)
+	assert.NotNil(suite.T(), organ.ArchivedAt)
+
+	_, err = suite.service.AddMember(1, AddMemberParams{GEWISID: ptr(uint(9001)), Name: "New"}, ownerPermissions)
+	assert.ErrorIs(suite.T(), err, ErrOrganArchived)
+
+	archived = false
+	organ, err = suite.service.UpdateOrgan(1, UpdateOrganParams{Archived: &archived})
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), organ.ArchivedAt)
+}
+
+func (suite *TestOrganSuite) TestAddMember_CreatesUserByGEWISID() {
+	_, err := suite.service.AddMember(1, AddMemberParams{GEWISID: ptr(uint(9001))}, ownerPermissions)
+	assert.ErrorIs(suite.T(), err, ErrNameRequired)
+
+	membership, err := suite.service.AddMember(1, AddMemberParams{GEWISID: ptr(uint(9001)), Name: "New"}, ownerPermissions)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.RoleMember, membership.Role)
+
+	var user models.User
+	assert.NoError(suite.T(), suite.db.Where("gewis_id = ?", 9001).First(&user).Error)
+	assert.Equal(suite.T(), user.ID, membership.UserID)
+
+	_, err = suite.service.AddMember(1, AddMemberParams{UserID: &user.ID}, ownerPermissions)
+	assert.ErrorIs(suite.T(), err, ErrAlreadyMember)
+
+	_, err = suite.service.AddMember(1, AddMemberParams{GEWISID: ptr(uint(9002)), Name: "Other", Role: "planner"}, ownerPermissions)
+	assert.ErrorIs(suite.T(), err, ErrUnknownRole)
+}
+
+func (suite *TestOrganSuite) TestLastOwner() {
+	owner := suite.owner()
+
+	assert.ErrorIs(suite.T(), suite.service.RemoveMember(1, owner), ErrLastOwner)
+	_, err := suite.service.UpdateMemberRole(1, owner, UpdateMemberRoleParams{Role: models.RoleAdmin}, ownerPermissions)
+	assert.ErrorIs(suite.T(), err, ErrLastOwner)
+
+	var other models.UserOrgan
+	suite.db.Where("organ_id = ? AND user_id <> ?", 1, owner).First(&other)
+	_, err = suite.service.UpdateMemberRole(1, other.UserID, UpdateMemberRoleParams{Role: models.RoleOwner}, ownerPermissions)
+	assert.NoError(suite.T(), err)
+
+	// With a second owner the first one can leave
+	assert.NoError(suite.T(), suite.service.RemoveMember(1, owner))
+	membership, err := suite.service.GetMemberSettings(1, owner)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MembershipAlumni, membership.Status)
+
+	// The remaining owner is now the last active one
+	_, err = suite.service.SetMemberStatus(1, other.UserID, models.MembershipInactive)
+	assert.ErrorIs(suite.T(), err, ErrLastOwner)
+}
+
+func (suite *TestOrganSuite) TestMemberStatus_History() {
+	var member models.UserOrgan
+	suite.db.Where("organ_id = ? AND role = ?", 1, models.RoleMember).First(&member)
+
+	membership, err := suite.service.SetMemberStatus(1, member.UserID, models.MembershipInactive)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MembershipInactive, membership.Status)
+	assert.NotNil(suite.T(), membership.ActiveUntil)
+
+	active := models.MembershipActive
+	members, err := suite.service.GetMembersSettings(1, &active)
+	assert.NoError(suite.T(), err)
+	for _, m := range members {
+		assert.NotEqual(suite.T(), member.UserID, m.UserID)
+	}
+
+	all, err := suite.service.GetMembersSettings(1, nil)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), all, len(members)+1)
+
+	// Adding a former member again reactivates the membership
+	membership, err = suite.service.AddMember(1, AddMemberParams{UserID: &member.UserID}, ownerPermissions)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.MembershipActive, membership.Status)
+	assert.Nil(suite.T(), membership.ActiveUntil)
+	assert.NotNil(suite.T(), membership.ActiveFrom)
+}
+
+func (suite *TestOrganSuite) TestRoles_CustomRoleLifecycle() {
+	_, err := suite.service.CreateRole(1, RoleDefinitionParams{Name: models.RoleAdmin, Permissions: models.PermissionSet{}}, ownerPermissions)
+	assert.ErrorIs(suite.T(), err, ErrBuiltinRole)
+
+	_, err = suite.service.CreateRole(1, RoleDefinitionParams{Name: "planner", Permissions: models.PermissionSet{"roster.everything"}}, ownerPermissions)
+	assert.ErrorIs(suite.T(), err, ErrUnknownPermission)
+
+	_, err = suite.service.CreateRole(1, RoleDefinitionParams{
+		Name:        "planner",
+		Permissions: models.PermissionSet{models.PermRosterView, models.PermRosterAssign},
+	}, ownerPermissions)
+	assert.NoError(suite.T(), err)
+
+	roles, err := suite.service.GetRoles(1)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), roles, 4)
+
+	var member models.UserOrgan
+	suite.db.Where("organ_id = ? AND role = ?", 1, models.RoleMember).First(&member)
+	_, err = suite.service.UpdateMemberRole(1, member.UserID, UpdateMemberRoleParams{Role: "planner"}, ownerPermissions)
+	assert.NoError(suite.T(), err)
+
+	assert.ErrorIs(suite.T(), suite.service.DeleteRole(1, "planner"), ErrRoleInUse)
+
+	_, err = suite.service.UpdateMemberRole(1, member.UserID, UpdateMemberRoleParams{Role: models.RoleMember}, ownerPermissions)
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.service.DeleteRole(1, "planner"))
+}
+
+func (suite *TestOrganSuite) TestRoles_NoEscalation() {
+	admin := models.BuiltinRoles[models.RoleAdmin]
+
+	// Admins cannot define a role with powers they lack, e.g. organ.manage
+	_, err := suite.service.CreateRole(1, RoleDefinitionParams{
+		Name:        "chair",
+		Permissions: models.PermissionSet{models.PermRosterView, models.PermOrganManage},
+	}, admin)
+	assert.ErrorIs(suite.T(), err, ErrRoleEscalation)
+
+	_, err = suite.service.CreateRole(1, RoleDefinitionParams{Name: "planner", Permissions: models.PermissionSet{models.PermRosterView}}, admin)
+	assert.NoError(suite.T(), err)
+	_, err = suite.service.UpdateRole(1, "planner", UpdateRoleDefinitionParams{Permissions: models.PermissionSet{models.PermOrganManage}}, admin)
+	assert.ErrorIs(suite.T(), err, ErrRoleEscalation)
+
+	// Nor assign such a role, to themselves or anyone else
+	_, err = suite.service.CreateRole(1, RoleDefinitionParams{Name: "chair", Permissions: models.PermissionSet{models.PermOrganManage}}, ownerPermissions)
+	suite.Require().NoError(err)
+	_, err = suite.service.UpdateRole(1, "chair", UpdateRoleDefinitionParams{Permissions: models.PermissionSet{models.PermRosterView}}, admin)
+	assert.ErrorIs(suite.T(), err, ErrRoleEscalation)
+
+	var member models.UserOrgan
+	suite.db.Where("organ_id = ? AND role = ?", 1, models.RoleMember).First(&member)
+	_, err = suite.service.UpdateMemberRole(1, member.UserID, UpdateMemberRoleParams{Role: "chair"}, admin)
+	assert.ErrorIs(suite.T(), err, ErrRoleEscalation)
+	_, err = suite.service.AddMember(1, AddMemberParams{GEWISID: ptr(uint(9003)), Name: "New", Role: "chair"}, admin)
+	assert.ErrorIs(suite.T(), err, ErrRoleEscalation)
+	_, err = suite.service.UpdateMemberRole(1, member.UserID, UpdateMemberRoleParams{Role: models.RoleOwner}, admin)
+	assert.ErrorIs(suite.T(), err, ErrRoleEscalation)
+
+	_, err = suite.service.UpdateMemberRole(1, member.UserID, UpdateMemberRoleParams{Role: "chair"}, ownerPermissions)
+	assert.NoError(suite.T(), err)
+}
+
+func (suite *TestOrganSuite) TestSettings_DefaultsAndUpdate() {
+	settings, err := suite.service.GetSettings(1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.DefaultOrganSettings(1), settings)
+
+	var template models.RosterTemplate
+	suite.db.Where("organ_id = ?", 1).First(&template)
+
+	settings, err = suite.service.UpdateSettings(1, UpdateSettingsParams{
+		AnswerValues:      &models.Values{"Y", "N"},
+		DefaultTemplateID: &template.ID,
+		Timezone:          ptr("America/New_York"),
+		OrderingRule:      ptr(models.OrderingFewestShifts),
+	})
+	assert.NoError(suite.T(), err)
+
+	stored, err := models.LoadOrganSettings(suite.db, 1)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.Values{"Y", "N"}, stored.AnswerValues)
+	assert.Equal(suite.T(), &template.ID, stored.DefaultTemplateID)
+	assert.Equal(suite.T(), "America/New_York", stored.Timezone)
+	assert.Equal(suite.T(), models.OrderingFewestShifts, stored.OrderingRule)
+	assert.Equal(suite.T(), settings.ExportHeaderColor, stored.ExportHeaderColor)
+
+	// A second update keeps what it does not change
+	_, err = suite.service.UpdateSettings(1, UpdateSettingsParams{DefaultTemplateID: ptr(uint(0))})
+	assert.NoError(suite.T(), err)
+	stored, _ = models.LoadOrganSettings(suite.db, 1)
+	assert.Nil(suite.T(), stored.DefaultTemplateID)
+	assert.Equal(suite.T(), "America/New_York", stored.Timezone)
+}
+
+func (suite *TestOrganSuite) TestSettings_Invalid() {
+	_, err := suite.service.UpdateSettings(1, UpdateSettingsParams{Timezone: ptr("Mars/Olympus_Mons")})
+	assert.ErrorIs(suite.T(), err, ErrInvalidTimezone)
+
+	_, err = suite.service.UpdateSettings(1, UpdateSettingsParams{AnswerValues: &models.Values{"J", "J"}})
+	assert.ErrorIs(suite.T(), err, ErrDuplicateValues)
+
+	_, err = suite.service.UpdateSettings(1, UpdateSettingsParams{ExportLogo: &[]byte{'n', 'o', 'p', 'e'}})
+	assert.ErrorIs(suite.T(), err, ErrInvalidLogo)
+
+	// A tiny file may declare huge dimensions, refused before decoding
+	huge := pngDeclaring(suite.T(), 100000, 100000)
+	_, err = suite.service.UpdateSettings(1, UpdateSettingsParams{ExportLogo: &huge})
+	assert.ErrorIs(suite.T(), err, ErrInvalidLogo)
+
+	logo := pngDeclaring(suite.T(), 1, 1)
+	_, err = suite.service.UpdateSettings(1, UpdateSettingsParams{ExportLogo: &logo})
+	assert.NoError(suite.T(), err)
+
+	var template models.RosterTemplate
+	if suite.db.Where("organ_id <> ?", 1).First(&template).Error == nil {
+		_, err = suite.service.UpdateSettings(1, UpdateSettingsParams{DefaultTemplateID: &template.ID})
+		assert.ErrorIs(suite.T(), err, ErrInvalidTemplate)
+	}
+
+	_, err = suite.service.GetSettings(999)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
+
+// pngDeclaring returns a 1x1 PNG whose header claims width x height pixels.
+func pngDeclaring(t *testing.T, width, height uint32) []byte {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatal(err)
+	}
+
+	// The IHDR chunk follows the 8 byte signature: length, type, data, CRC
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:20], width)
+	binary.BigEndian.PutUint32(data[20:24], height)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestOrganService(t *testing.T) {
+	suite.Run(t, new(TestOrganSuite))
+}
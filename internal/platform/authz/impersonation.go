@@ -0,0 +1,34 @@
+package authz
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+const impersonatorKey = "impersonatorID"
+
+// SetImpersonator marks the request as made by a platform admin acting as the
+// authenticated user.
+func SetImpersonator(c *gin.Context, actorID uint) {
+	c.Set(impersonatorKey, actorID)
+}
+
+// ImpersonatorID returns the admin acting as the authenticated user, if the
+// request is made while impersonating.
+func ImpersonatorID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get(impersonatorKey)
+	if !exists {
+		return 0, false
+	}
+
+	actorID, ok := val.(uint)
+	return actorID, ok
+}
+
+// Sensitive returns the policy for a route that may not be called while
+// impersonating, such as changing roles or managing tokens. Sensitive routes
+// are interactive as well.
+func (p Policy) Sensitive() Policy {
+	p.sensitive = true
+	p.interactive = true
+	return p
+}
@@ -0,0 +1,377 @@
+package authz
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"gorm.io/gorm"
+	"net/http"
+	"reflect"
+	"strconv"
+)
+
+// ErrInvalidReference is returned by resolvers when the request does not
+// contain a usable reference to the resource, e.g. a missing path parameter.
+var ErrInvalidReference = errors.New("invalid resource reference")
+
+const (
+	platformAdminKey = "platformAdmin"
+	permissionsKey   = "organPermissions"
+)
+
+// Resource is the organ scoped object a request acts on.
+type Resource struct {
+	OrganID uint
+
+	// OwnerID is set for records that belong to a single user, such as answers
+	// and preferences. Members of the organ may always access their own records.
+	OwnerID *uint
+}
+
+// Resolver finds the resource a request acts on. It returns gorm's
+// ErrRecordNotFound when the resource does not exist and ErrInvalidReference
+// when the request does not point to a resource.
+type Resolver func(c *gin.Context, db *gorm.DB) (*Resource, error)
+
+// Policy states who may call a route. Routes with a resolver require the role
+// of the authenticated user in the organ of the resolved resource to grant
+// Permission. Routes without a resolver only require a logged-in user, unless
+// the policy is Public or Self.
+type Policy struct {
+	Resolver Resolver
+
+	Permission models.Permission
+
+	public bool
+
+	platformAdmin bool
+
+	sensitive bool
+
+	interactive bool
+
+	// self is the path parameter holding the user a self policy applies to
+	self string
+}
+
+var (
+	// Public is the policy of routes that can be called without logging in.
+	Public = Policy{public: true}
+
+	// Authenticated is the policy of routes that any logged-in user may call,
+	// for example because the handler only returns the user's own data. That
+	// data spans all organs of the user, so tokens limited to some organs are
+	// refused.
+	Authenticated = Policy{}
+
+	// PlatformAdmin is the policy of routes that manage the whole platform.
+	// Personal access tokens are refused, as their organ scope cannot limit
+	// routes that span every organ.
+	PlatformAdmin = Policy{platformAdmin: true, sensitive: true, interactive: true}
+)
+
+// Self returns a policy for routes acting on a user account, which only the
+// user in the path parameter may call.
+func Self(param string) Policy {
+	return Policy{self: param}
+}
+
+// Require returns a policy that requires permission in the organ of the
+// resource found by resolver.
+func Require(resolver Resolver, permission models.Permission) Policy {
+	return Policy{Resolver: resolver, Permission: permission}
+}
+
+// Handler returns the middleware that enforces the policy.
+func (p Policy) Handler(db *gorm.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if p.public {
+			c.Next()
+			return
+		}
+
+		userID, ok := UserID(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+			return
+		}
+
+		if _, impersonating := ImpersonatorID(c); impersonating && p.sensitive {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Not allowed while impersonating"})
+			return
+		}
+
+		if _, viaToken := GetTokenScope(c); viaToken && p.interactive {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Personal access tokens cannot do this"})
+			return
+		}
+
+		if scope, ok := GetTokenScope(c); ok && !scope.allowsMethod(c.Request.Method) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This token is read-only"})
+			return
+		}
+
+		if p.platformAdmin && !IsPlatformAdmin(c, db) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Only platform admins can do this"})
+			return
+		}
+
+		if p.self != "" {
+			targetID, err := ParseID(c.Param(p.self), p.self)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+
+			if targetID != userID {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You can only change your own account"})
+				return
+			}
+		}
+
+		if p.Resolver == nil {
+			if scope, ok := GetTokenScope(c); ok && len(scope.OrganIDs) > 0 {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This token is limited to some organs"})
+				return
+			}
+			c.Next()
+			return
+		}
+
+		resource, err := p.Resolver(c, db)
+		if err != nil {
+			switch {
+			case errors.Is(err, ErrInvalidReference):
+				c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Resource not found"})
+			default:
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+			}
+			return
+		}
+
+		permission := p.Permission
+		if resource.OwnerID != nil && *resource.OwnerID == userID {
+			permission = ""
+		}
+
+		CheckAccess(c, db, resource.OrganID, permission)
+	}
+}
+
+// CheckAccess aborts the request unless the authenticated user is an active
+// member of the organ whose role grants permission, and the personal access token it
+// used, if any, covers the organ. An empty permission only requires
+// membership. Platform admins pass as owners of every organ. The role and its
+// permissions are stored on the context so handlers can use them to tailor
+// their response, e.g. to hide unpublished data from members.
+func CheckAccess(c *gin.Context, db *gorm.DB, organID uint, permission models.Permission) {
+	userID, exists := UserID(c)
+	if !exists {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	if scope, ok := GetTokenScope(c); ok && !scope.allowsOrgan(organID) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "This token does not grant access to this organ"})
+		return
+	}
+
+	if IsPlatformAdmin(c, db) {
+		c.Set("organRole", models.RoleOwner)
+		c.Set(permissionsKey, models.BuiltinRoles[models.RoleOwner])
+		c.Next()
+		return
+	}
+
+	var userOrgan models.UserOrgan
+
+	if err := db.Where("user_id = ? AND organ_id = ?", userID, organID).Scopes(models.ActiveMembers).First(&userOrgan).Error; err != nil {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not a member of this organ"})
+		return
+	}
+
+	permissions, err := RolePermissions(db, organID, userOrgan.Role)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to check permissions"})
+		return
+	}
+
+	if permission != "" && !permissions.Has(permission) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient organ permissions"})
+		return
+	}
+
+	c.Set("organRole", userOrgan.Role)
+	c.Set(permissionsKey, permissions)
+
+	c.Next()
+}
+
+// UserID returns the ID of the user set by the auth middleware.
+func UserID(c *gin.Context) (uint, bool) {
+	val, exists := c.Get("userID")
+	if !exists {
+		return 0, false
+	}
+
+	userID, ok := val.(uint)
+	return userID, ok
+}
+
+// IsPlatformAdmin reports whether the authenticated user is a platform admin.
+// The platform_admin column is the only source, the login sync sets it from
+// PLATFORM_ADMIN_IDS and the identity provider. The answer is cached on the
+// context.
+func IsPlatformAdmin(c *gin.Context, db *gorm.DB) bool {
+	if val, exists := c.Get(platformAdminKey); exists {
+		return val.(bool)
+	}
+
+	userID, ok := UserID(c)
+	if !ok {
+		return false
+	}
+
+	var admin models.User
+	isAdmin := db.Select("id", "platform_admin").First(&admin, userID).Error == nil && admin.PlatformAdmin
+	c.Set(platformAdminKey, isAdmin)
+	return isAdmin
+}
+
+// RolePermissions returns the permissions of a role in an organ: the built-in
+// defaults for owner, admin and member, or the organ's own definition. Roles
+// the organ does not define grant nothing.
+func RolePermissions(db *gorm.DB, organID uint, role models.OrganRole) (models.PermissionSet, error) {
+	if permissions, ok := models.BuiltinRoles[role]; ok {
+		return permissions, nil
+	}
+
+	var definition models.OrganRoleDefinition
+	err := db.Where("organ_id = ? AND name = ?", organID, role).First(&definition).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.PermissionSet{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return definition.Permissions, nil
+}
+
+// OrganRole returns the role stored by CheckAccess.
+func OrganRole(c *gin.Context) (models.OrganRole, bool) {
+	val, exists := c.Get("organRole")
+	if !exists {
+		return "", false
+	}
+
+	role, ok := val.(models.OrganRole)
+	return role, ok
+}
+
+// Permissions returns the permissions of the role stored by CheckAccess.
+func Permissions(c *gin.Context) models.PermissionSet {
+	val, exists := c.Get(permissionsKey)
+	if !exists {
+		return nil
+	}
+
+	permissions, _ := val.(models.PermissionSet)
+	return permissions
+}
+
+// HasPermission reports whether the role stored by CheckAccess grants permission.
+func HasPermission(c *gin.Context, permission models.Permission) bool {
+	return Permissions(c).Has(permission)
+}
+
+// OrganParam resolves the organ directly from a path parameter.
+func OrganParam(param string) Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*Resource, error) {
+		organID, err := ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
+		return &Resource{OrganID: organID}, nil
+	}
+}
+
+// OrganQuery resolves the organ directly from a query parameter.
+func OrganQuery(query string) Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*Resource, error) {
+		organID, err := ParseID(c.Query(query), query)
+		if err != nil {
+			return nil, err
+		}
+		return &Resource{OrganID: organID}, nil
+	}
+}
+
+// OrganBody resolves the organ directly from a field of the JSON body.
+func OrganBody(field string) Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*Resource, error) {
+		organID, err := BodyID(c, field)
+		if err != nil {
+			return nil, err
+		}
+		return &Resource{OrganID: organID}, nil
+	}
+}
+
+// ModelParam resolves the organ of a model with an organ_id column, looked up
+// by the ID in a path parameter.
+func ModelParam(model interface{}, param string) Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*Resource, error) {
+		id, err := ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
+
+		var row struct {
+			OrganID uint
+		}
+		if err := db.Model(model).Select("organ_id").Where("id = ?", id).Take(&row).Error; err != nil {
+			return nil, err
+		}
+		return &Resource{OrganID: row.OrganID}, nil
+	}
+}
+
+// ParseID parses the ID of a resource reference, returning ErrInvalidReference
+// when it is missing or malformed.
+func ParseID(value string, name string) (uint, error) {
+	if value == "" {
+		return 0, fmt.Errorf("%w: %s is required", ErrInvalidReference, name)
+	}
+
+	id, err := strconv.ParseUint(value, 10, 32)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: invalid %s", ErrInvalidReference, name)
+	}
+	return uint(id), nil
+}
+
+// BodyID reads an ID field from the JSON body. The body stays available to the
+// handler. The field is decoded into a struct tagged like the handler's own
+// request, so duplicate and case variant keys resolve to the same value the
+// handler binds.
+func BodyID(c *gin.Context, field string) (uint, error) {
+	body := reflect.New(reflect.StructOf([]reflect.StructField{{
+		Name: "ID",
+		Type: reflect.TypeOf((*float64)(nil)),
+		Tag:  reflect.StructTag(fmt.Sprintf("json:%q", field)),
+	}}))
+	if err := c.ShouldBindBodyWith(body.Interface(), binding.JSON); err != nil {
+		return 0, fmt.Errorf("%w: valid %s is required in body", ErrInvalidReference, field)
+	}
+
+	id, _ := body.Elem().Field(0).Interface().(*float64)
+	if id == nil || *id < 1 || *id != float64(uint32(*id)) {
+		return 0, fmt.Errorf("%w: valid %s is required in body", ErrInvalidReference, field)
+	}
+
+	return uint(*id), nil
+}
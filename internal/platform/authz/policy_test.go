@@ -0,0 +1,138 @@
+package authz
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPlatformAdmin(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := seeder.Seeder(":memory:")
+
+	var users []models.User
+	db.Order("id ASC").Limit(2).Find(&users)
+	admin, member := users[0], users[1]
+	db.Model(&admin).Update("platform_admin", true)
+	db.Where("user_id = ?", admin.ID).Delete(&models.UserOrgan{})
+	db.Model(&models.UserOrgan{}).Where("user_id = ?", member.ID).Update("role", models.RoleMember)
+
+	r := gin.New()
+	api := r.Group("", func(c *gin.Context) {
+		if c.GetHeader("X-Admin") != "" {
+			c.Set("userID", admin.ID)
+		} else {
+			c.Set("userID", member.ID)
+		}
+	})
+	router := NewRouter(api, db, NewRegistry())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.PATCH("/organ/:id", Require(OrganParam("id"), models.PermMemberRole), ok)
+	router.GET("/admin", PlatformAdmin, ok)
+
+	cases := []struct {
+		method string
+		path   string
+		admin  bool
+		want   int
+	}{
+		{http.MethodPatch, "/organ/1", false, http.StatusForbidden},
+		{http.MethodPatch, "/organ/1", true, http.StatusOK},
+		{http.MethodGet, "/admin", false, http.StatusForbidden},
+		{http.MethodGet, "/admin", true, http.StatusOK},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		if tc.admin {
+			req.Header.Set("X-Admin", "1")
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code, "%s %s as admin=%v", tc.method, tc.path, tc.admin)
+	}
+}
+
+func TestCustomRolePermissions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := seeder.Seeder(":memory:")
+
+	var user models.User
+	db.Order("id ASC").First(&user)
+	db.Model(&models.UserOrgan{}).Where("user_id = ?", user.ID).Update("role", "planner")
+	db.Create(&models.OrganRoleDefinition{
+		OrganID:     1,
+		Name:        "planner",
+		Permissions: models.PermissionSet{models.PermRosterView, models.PermRosterAssign},
+	})
+
+	r := gin.New()
+	api := r.Group("", func(c *gin.Context) { c.Set("userID", user.ID) })
+	router := NewRouter(api, db, NewRegistry())
+	router.GET("/organ/:id/:permission", Authenticated, func(c *gin.Context) {
+		CheckAccess(c, db, 1, models.Permission(c.Param("permission")))
+	}, func(c *gin.Context) {
+		assert.True(t, HasPermission(c, models.PermRosterAssign))
+		c.Status(http.StatusOK)
+	})
+
+	cases := []struct {
+		path string
+		want int
+	}{
+		{"/organ/1/roster.view", http.StatusOK},
+		{"/organ/1/roster.assign", http.StatusOK},
+		{"/organ/1/template.edit", http.StatusForbidden},
+		{"/organ/1/member.role", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		assert.Equal(t, tc.want, w.Code, tc.path)
+	}
+
+	// A role the organ does not define grants nothing
+	db.Model(&models.UserOrgan{}).Where("user_id = ?", user.ID).Update("role", "retired")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/organ/1/roster.view", nil))
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestBodyID_MatchesHandlerBinding(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// Case variant keys must resolve to the organ the handler writes to
+	bodies := []string{
+		`{"organId":1,"ORGANID":5}`,
+		`{"ORGANID":5,"organId":1}`,
+		`{"organid":2,"organId":3,"OrganId":4}`,
+	}
+	for _, body := range bodies {
+		for i := 0; i < 50; i++ {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+			id, err := BodyID(c, "organId")
+			assert.NoError(t, err)
+
+			var params struct {
+				OrganID uint `json:"organId"`
+			}
+			assert.NoError(t, c.ShouldBindBodyWith(&params, binding.JSON))
+			assert.Equal(t, params.OrganID, id, body)
+		}
+	}
+
+	for _, body := range []string{`{}`, `{"organId":"1"}`, `{"organId":0}`, `{"organId":1.5}`} {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+
+		_, err := BodyID(c, "organId")
+		assert.ErrorIs(t, err, ErrInvalidReference, body)
+	}
+}
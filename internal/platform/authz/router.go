@@ -0,0 +1,102 @@
+package authz
+
+import (
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// Registry records the policy of every route registered through a Router.
+type Registry struct {
+	mu       sync.RWMutex
+	policies map[string]Policy
+}
+
+func NewRegistry() *Registry {
+	return &Registry{policies: make(map[string]Policy)}
+}
+
+// Lookup returns the policy of a route by its method and full path as listed by
+// gin's Routes.
+func (r *Registry) Lookup(method string, fullPath string) (Policy, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	policy, ok := r.policies[routeKey(method, fullPath)]
+	return policy, ok
+}
+
+func (r *Registry) add(method string, fullPath string, policy Policy) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.policies[routeKey(method, fullPath)] = policy
+}
+
+func routeKey(method string, fullPath string) string {
+	return method + " " + fullPath
+}
+
+// Router wraps a gin router group so every route has to state its policy. The
+// policy is enforced in front of the handlers and recorded in the registry.
+type Router struct {
+	group    *gin.RouterGroup
+	db       *gorm.DB
+	registry *Registry
+}
+
+func NewRouter(rg *gin.RouterGroup, db *gorm.DB, registry *Registry) *Router {
+	return &Router{group: rg, db: db, registry: registry}
+}
+
+// Group creates a sub router for routes sharing a path prefix.
+func (r *Router) Group(relativePath string) *Router {
+	return &Router{group: r.group.Group(relativePath), db: r.db, registry: r.registry}
+}
+
+// BasePath returns the path prefix of the router.
+func (r *Router) BasePath() string {
+	return r.group.BasePath()
+}
+
+func (r *Router) Handle(method string, relativePath string, policy Policy, handlers ...gin.HandlerFunc) {
+	r.registry.add(method, joinPaths(r.group.BasePath(), relativePath), policy)
+	r.group.Handle(method, relativePath, append([]gin.HandlerFunc{policy.Handler(r.db)}, handlers...)...)
+}
+
+func (r *Router) GET(relativePath string, policy Policy, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodGet, relativePath, policy, handlers...)
+}
+
+func (r *Router) POST(relativePath string, policy Policy, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPost, relativePath, policy, handlers...)
+}
+
+func (r *Router) PUT(relativePath string, policy Policy, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPut, relativePath, policy, handlers...)
+}
+
+func (r *Router) PATCH(relativePath string, policy Policy, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodPatch, relativePath, policy, handlers...)
+}
+
+func (r *Router) DELETE(relativePath string, policy Policy, handlers ...gin.HandlerFunc) {
+	r.Handle(http.MethodDelete, relativePath, policy, handlers...)
+}
+
+// joinPaths mirrors how gin joins group and route paths, so registry keys match
+// the paths listed by gin's Routes.
+func joinPaths(absolutePath string, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+
+	finalPath := path.Join(absolutePath, relativePath)
+	if strings.HasSuffix(relativePath, "/") && !strings.HasSuffix(finalPath, "/") {
+		return finalPath + "/"
+	}
+	return finalPath
+}
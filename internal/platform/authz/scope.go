@@ -0,0 +1,54 @@
+package authz
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"slices"
+)
+
+const tokenScopeKey = "tokenScope"
+
+// TokenScope limits a request authenticated with a personal access token. The
+// auth middleware stores it on the context, the policies enforce it.
+type TokenScope struct {
+	// OrganIDs lists the organs the token may access, all organs of the user
+	// when empty
+	OrganIDs []uint
+
+	ReadOnly bool
+}
+
+// SetTokenScope marks the request as made with a personal access token.
+func SetTokenScope(c *gin.Context, scope TokenScope) {
+	c.Set(tokenScopeKey, scope)
+}
+
+// GetTokenScope returns the scope of the personal access token the request was
+// made with, if any.
+func GetTokenScope(c *gin.Context) (TokenScope, bool) {
+	val, exists := c.Get(tokenScopeKey)
+	if !exists {
+		return TokenScope{}, false
+	}
+
+	scope, ok := val.(TokenScope)
+	return scope, ok
+}
+
+// Interactive returns the policy for a route that needs a login session, so it
+// cannot be called with a personal access token of any scope.
+func (p Policy) Interactive() Policy {
+	p.interactive = true
+	return p
+}
+
+func (s TokenScope) allowsMethod(method string) bool {
+	if !s.ReadOnly {
+		return true
+	}
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func (s TokenScope) allowsOrgan(organID uint) bool {
+	return len(s.OrganIDs) == 0 || slices.Contains(s.OrganIDs, organID)
+}
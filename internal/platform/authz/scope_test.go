@@ -0,0 +1,71 @@
+package authz
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestTokenScope(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	db := seeder.Seeder(":memory:")
+
+	var member models.User
+	db.First(&member)
+	db.Model(&models.UserOrgan{}).Where("user_id = ?", member.ID).Update("role", models.RoleAdmin)
+
+	scopes := map[string]TokenScope{
+		"read":    {ReadOnly: true},
+		"write":   {},
+		"organ-2": {OrganIDs: []uint{2}},
+	}
+
+	r := gin.New()
+	api := r.Group("", func(c *gin.Context) {
+		c.Set("userID", member.ID)
+		if scope, ok := scopes[c.GetHeader("X-Scope")]; ok {
+			SetTokenScope(c, scope)
+		}
+	})
+	router := NewRouter(api, db, NewRegistry())
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	router.GET("/organ/:id", Require(OrganParam("id"), models.PermRosterView), ok)
+	router.POST("/organ/:id", Require(OrganParam("id"), models.PermRosterCreate), ok)
+	router.POST("/session", Authenticated.Interactive(), ok)
+	router.POST("/sensitive", Authenticated.Sensitive(), ok)
+	router.GET("/me", Authenticated, ok)
+
+	cases := []struct {
+		method string
+		path   string
+		scope  string
+		want   int
+	}{
+		{http.MethodGet, "/organ/1", "", http.StatusOK},
+		{http.MethodPost, "/organ/1", "", http.StatusOK},
+		{http.MethodGet, "/organ/1", "read", http.StatusOK},
+		{http.MethodPost, "/organ/1", "read", http.StatusForbidden},
+		{http.MethodPost, "/organ/1", "write", http.StatusOK},
+		{http.MethodGet, "/organ/1", "organ-2", http.StatusForbidden},
+		// Interactive and sensitive routes refuse every token, whatever its scope
+		{http.MethodPost, "/session", "", http.StatusOK},
+		{http.MethodPost, "/session", "write", http.StatusForbidden},
+		{http.MethodPost, "/sensitive", "", http.StatusOK},
+		{http.MethodPost, "/sensitive", "write", http.StatusForbidden},
+		{http.MethodPost, "/sensitive", "organ-2", http.StatusForbidden},
+		// Routes that span all organs of the user refuse tokens limited to some
+		{http.MethodGet, "/me", "read", http.StatusOK},
+		{http.MethodGet, "/me", "organ-2", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(tc.method, tc.path, nil)
+		req.Header.Set("X-Scope", tc.scope)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.want, w.Code, "%s %s with scope %q", tc.method, tc.path, tc.scope)
+	}
+}
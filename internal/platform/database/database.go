@@ -52,11 +52,22 @@ func ConnectDB(name string) *gorm.DB {
 			&models.Roster{},
 			&models.RosterShift{},
 			&models.RosterAnswer{},
+			&models.RosterAnswerChange{},
 			&models.SavedShift{},
+			&models.SavedShiftWaitlistEntry{},
 			&models.RosterTemplate{},
 			&models.RosterTemplateShift{},
 			&models.RosterTemplateShiftPreference{},
 			&models.ShiftGroup{},
+			&models.ShiftGroupPriority{},
+			&models.Notification{},
+			&models.Session{},
+			&models.RefreshToken{},
+			&models.AuthCode{},
+			&models.PersonalAccessToken{},
+			&models.AuditLog{},
+			&models.OrganRoleDefinition{},
+			&models.OrganSettings{},
 		); err != nil {
 			panic(err)
 		}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS `saved_shift_waitlist_entries`;
+
+ALTER TABLE `saved_shifts` DROP COLUMN `capacity`;
+
+ALTER TABLE `rosters`
+    DROP CHECK chk_rosters_mode,
+    DROP COLUMN `waitlist_enabled`,
+    DROP COLUMN `mode`;
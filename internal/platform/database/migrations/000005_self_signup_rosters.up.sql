@@ -0,0 +1,27 @@
+ALTER TABLE `rosters`
+    ADD COLUMN `mode` VARCHAR(20) NOT NULL DEFAULT 'assigned',
+    ADD COLUMN `waitlist_enabled` TINYINT(1) NOT NULL DEFAULT 0,
+    ADD CONSTRAINT chk_rosters_mode
+        CHECK (`mode` IN ('assigned', 'self_signup'));
+
+ALTER TABLE `saved_shifts`
+    ADD COLUMN `capacity` BIGINT UNSIGNED NOT NULL DEFAULT 0;
+
+CREATE TABLE `saved_shift_waitlist_entries` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `saved_shift_id` BIGINT UNSIGNED NOT NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    UNIQUE INDEX `idx_waitlist_user` (`saved_shift_id`, `user_id`),
+
+    CONSTRAINT `fk_saved_shifts_waitlist`
+        FOREIGN KEY (`saved_shift_id`)
+            REFERENCES `saved_shifts`(`id`)
+            ON DELETE CASCADE,
+
+    CONSTRAINT `fk_saved_shift_waitlist_entries_user`
+        FOREIGN KEY (`user_id`)
+            REFERENCES `users`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE `rosters` DROP COLUMN `published`;
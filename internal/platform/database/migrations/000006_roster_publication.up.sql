@@ -0,0 +1,2 @@
+ALTER TABLE `rosters`
+    ADD COLUMN `published` TINYINT(1) NOT NULL DEFAULT 0;
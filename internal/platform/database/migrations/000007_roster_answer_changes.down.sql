@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `roster_answer_changes`;
@@ -0,0 +1,21 @@
+CREATE TABLE `roster_answer_changes` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `roster_answer_id` BIGINT UNSIGNED NOT NULL,
+    `roster_id` BIGINT UNSIGNED NOT NULL,
+    `roster_shift_id` BIGINT UNSIGNED NOT NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `actor_id` BIGINT UNSIGNED DEFAULT NULL,
+    `old_value` longtext DEFAULT NULL,
+    `new_value` longtext DEFAULT NULL,
+    `source` VARCHAR(20) NOT NULL,
+    INDEX `idx_roster_answer_changes_roster_answer_id` (`roster_answer_id`),
+    INDEX `idx_roster_answer_changes_roster_id` (`roster_id`),
+    INDEX `idx_roster_answer_changes_user_id` (`user_id`),
+
+    CONSTRAINT `fk_roster_answer_changes_roster`
+        FOREIGN KEY (`roster_id`)
+            REFERENCES `rosters`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
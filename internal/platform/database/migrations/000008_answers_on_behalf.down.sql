@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `notifications`;
+
+ALTER TABLE `roster_answers`
+    DROP FOREIGN KEY `fk_roster_answers_entered_by`,
+    DROP COLUMN `entered_by_id`;
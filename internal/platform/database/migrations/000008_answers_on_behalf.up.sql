@@ -0,0 +1,23 @@
+ALTER TABLE `roster_answers`
+    ADD COLUMN `entered_by_id` BIGINT UNSIGNED DEFAULT NULL,
+    ADD CONSTRAINT `fk_roster_answers_entered_by`
+        FOREIGN KEY (`entered_by_id`)
+            REFERENCES `users`(`id`)
+            ON DELETE SET NULL;
+
+CREATE TABLE `notifications` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `type` VARCHAR(50) DEFAULT NULL,
+    `message` VARCHAR(255) DEFAULT NULL,
+    `roster_id` BIGINT UNSIGNED DEFAULT NULL,
+    `read_at` datetime(3) DEFAULT NULL,
+    INDEX `idx_notifications_user_id` (`user_id`),
+
+    CONSTRAINT `fk_notifications_user`
+        FOREIGN KEY (`user_id`)
+            REFERENCES `users`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1 @@
+ALTER TABLE user_organs DROP COLUMN manual_role;
@@ -0,0 +1,2 @@
+ALTER TABLE user_organs
+    ADD COLUMN manual_role BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,30 @@
+CREATE TABLE `sessions` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `revoked_at` datetime(3) DEFAULT NULL,
+    INDEX `idx_sessions_user_id` (`user_id`),
+
+    CONSTRAINT `fk_sessions_user`
+        FOREIGN KEY (`user_id`)
+            REFERENCES `users`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `refresh_tokens` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `session_id` BIGINT UNSIGNED NOT NULL,
+    `token_hash` CHAR(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `used_at` datetime(3) DEFAULT NULL,
+    INDEX `idx_refresh_tokens_session_id` (`session_id`),
+    UNIQUE INDEX `idx_refresh_tokens_token_hash` (`token_hash`),
+
+    CONSTRAINT `fk_refresh_tokens_session`
+        FOREIGN KEY (`session_id`)
+            REFERENCES `sessions`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,16 @@
+CREATE TABLE `auth_codes` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `code_hash` CHAR(64) NOT NULL,
+    `expires_at` datetime(3) NOT NULL,
+    `used_at` datetime(3) DEFAULT NULL,
+    INDEX `idx_auth_codes_user_id` (`user_id`),
+    UNIQUE INDEX `idx_auth_codes_code_hash` (`code_hash`),
+
+    CONSTRAINT `fk_auth_codes_user`
+        FOREIGN KEY (`user_id`)
+            REFERENCES `users`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
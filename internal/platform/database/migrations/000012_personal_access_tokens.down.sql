@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS `personal_access_token_organs`;
+DROP TABLE IF EXISTS `personal_access_tokens`;
@@ -0,0 +1,35 @@
+CREATE TABLE `personal_access_tokens` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `name` VARCHAR(100) DEFAULT NULL,
+    `prefix` VARCHAR(12) DEFAULT NULL,
+    `token_hash` CHAR(64) NOT NULL,
+    `scope` VARCHAR(10) NOT NULL,
+    `expires_at` datetime(3) DEFAULT NULL,
+    `last_used_at` datetime(3) DEFAULT NULL,
+    `revoked_at` datetime(3) DEFAULT NULL,
+    INDEX `idx_personal_access_tokens_user_id` (`user_id`),
+    UNIQUE INDEX `idx_personal_access_tokens_token_hash` (`token_hash`),
+
+    CONSTRAINT `fk_personal_access_tokens_user`
+        FOREIGN KEY (`user_id`)
+            REFERENCES `users`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE `personal_access_token_organs` (
+    `personal_access_token_id` BIGINT UNSIGNED NOT NULL,
+    `organ_id` BIGINT UNSIGNED NOT NULL,
+    PRIMARY KEY (`personal_access_token_id`, `organ_id`),
+
+    CONSTRAINT `fk_personal_access_token_organs_token`
+        FOREIGN KEY (`personal_access_token_id`)
+            REFERENCES `personal_access_tokens`(`id`)
+            ON DELETE CASCADE,
+    CONSTRAINT `fk_personal_access_token_organs_organ`
+        FOREIGN KEY (`organ_id`)
+            REFERENCES `organs`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
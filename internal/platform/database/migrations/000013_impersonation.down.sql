@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS `audit_logs`;
+
+ALTER TABLE `sessions`
+    DROP FOREIGN KEY `fk_sessions_impersonator`,
+    DROP COLUMN `impersonator_id`;
@@ -0,0 +1,21 @@
+ALTER TABLE `sessions`
+    ADD COLUMN `impersonator_id` BIGINT UNSIGNED DEFAULT NULL,
+    ADD CONSTRAINT `fk_sessions_impersonator`
+        FOREIGN KEY (`impersonator_id`)
+            REFERENCES `users`(`id`)
+            ON DELETE CASCADE;
+
+CREATE TABLE `audit_logs` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `actor_id` BIGINT UNSIGNED NOT NULL,
+    `user_id` BIGINT UNSIGNED NOT NULL,
+    `session_id` BIGINT UNSIGNED DEFAULT NULL,
+    `method` VARCHAR(10) DEFAULT NULL,
+    `route` VARCHAR(255) DEFAULT NULL,
+    `path` VARCHAR(255) DEFAULT NULL,
+    `status` BIGINT DEFAULT NULL,
+    INDEX `idx_audit_logs_actor_id` (`actor_id`),
+    INDEX `idx_audit_logs_user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,2 @@
+ALTER TABLE `users`
+    ADD COLUMN `platform_admin` BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS `organ_role_definitions`;
+
+UPDATE user_organs SET role = 'member' WHERE role NOT IN ('owner', 'admin', 'member');
+
+ALTER TABLE user_organs
+    MODIFY COLUMN role VARCHAR(20) NOT NULL DEFAULT 'member',
+    ADD CONSTRAINT chk_user_organs_role
+        CHECK (role IN ('owner', 'admin', 'member'));
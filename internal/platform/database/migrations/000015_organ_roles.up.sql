@@ -0,0 +1,18 @@
+ALTER TABLE user_organs
+    DROP CHECK chk_user_organs_role,
+    MODIFY COLUMN role VARCHAR(50) NOT NULL DEFAULT 'member';
+
+CREATE TABLE `organ_role_definitions` (
+    `id` BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+    `organ_id` BIGINT UNSIGNED NOT NULL,
+    `name` VARCHAR(50) NOT NULL,
+    `permissions` LONGTEXT,
+    UNIQUE INDEX `idx_organ_role_definitions_name` (`organ_id`, `name`),
+
+    CONSTRAINT `fk_organ_role_definitions_organ`
+        FOREIGN KEY (`organ_id`)
+            REFERENCES `organs`(`id`)
+            ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
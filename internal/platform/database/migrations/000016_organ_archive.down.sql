@@ -0,0 +1 @@
+ALTER TABLE `organs` DROP COLUMN `archived_at`;
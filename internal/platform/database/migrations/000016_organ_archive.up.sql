@@ -0,0 +1,2 @@
+ALTER TABLE `organs`
+    ADD COLUMN `archived_at` datetime(3) DEFAULT NULL;
@@ -0,0 +1,7 @@
+DELETE FROM user_organs WHERE `status` <> 'active';
+
+ALTER TABLE user_organs
+    DROP INDEX `idx_user_organs_status`,
+    DROP COLUMN `active_until`,
+    DROP COLUMN `active_from`,
+    DROP COLUMN `status`;
@@ -0,0 +1,5 @@
+ALTER TABLE user_organs
+    ADD COLUMN `status` VARCHAR(20) NOT NULL DEFAULT 'active',
+    ADD COLUMN `active_from` datetime(3) DEFAULT NULL,
+    ADD COLUMN `active_until` datetime(3) DEFAULT NULL,
+    ADD INDEX `idx_user_organs_status` (`status`);
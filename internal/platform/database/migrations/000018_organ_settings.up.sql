@@ -0,0 +1,23 @@
+CREATE TABLE `organ_settings` (
+    `organ_id` BIGINT UNSIGNED NOT NULL PRIMARY KEY,
+    `answer_values` LONGTEXT,
+    `default_template_id` BIGINT UNSIGNED DEFAULT NULL,
+    `timezone` VARCHAR(64) DEFAULT NULL,
+    `ordering_rule` VARCHAR(20) DEFAULT NULL,
+    `export_header_color` VARCHAR(7) DEFAULT NULL,
+    `export_header_text_color` VARCHAR(7) DEFAULT NULL,
+    `export_stripe_color` VARCHAR(7) DEFAULT NULL,
+    `export_text_color` VARCHAR(7) DEFAULT NULL,
+    `export_logo` MEDIUMBLOB,
+    `created_at` datetime(3) DEFAULT NULL,
+    `updated_at` datetime(3) DEFAULT NULL,
+
+    CONSTRAINT `fk_organ_settings_organ`
+        FOREIGN KEY (`organ_id`)
+            REFERENCES `organs`(`id`)
+            ON DELETE CASCADE,
+    CONSTRAINT `fk_organ_settings_default_template`
+        FOREIGN KEY (`default_template_id`)
+            REFERENCES `roster_templates`(`id`)
+            ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
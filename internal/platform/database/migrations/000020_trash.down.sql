@@ -0,0 +1,10 @@
+DELETE FROM rosters WHERE `deleted_at` IS NOT NULL;
+DELETE FROM roster_templates WHERE `deleted_at` IS NOT NULL;
+
+ALTER TABLE rosters
+    DROP INDEX `idx_rosters_deleted_at`,
+    DROP COLUMN `deleted_at`;
+
+ALTER TABLE roster_templates
+    DROP INDEX `idx_roster_templates_deleted_at`,
+    DROP COLUMN `deleted_at`;
@@ -0,0 +1,7 @@
+ALTER TABLE rosters
+    ADD COLUMN `deleted_at` datetime(3) DEFAULT NULL,
+    ADD INDEX `idx_rosters_deleted_at` (`deleted_at`);
+
+ALTER TABLE roster_templates
+    ADD COLUMN `deleted_at` datetime(3) DEFAULT NULL,
+    ADD INDEX `idx_roster_templates_deleted_at` (`deleted_at`);
@@ -0,0 +1,5 @@
+DELETE FROM `users` WHERE `guest` = 1;
+
+ALTER TABLE `users`
+    DROP COLUMN `email`,
+    DROP COLUMN `guest`;
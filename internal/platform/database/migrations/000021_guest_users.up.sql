@@ -0,0 +1,3 @@
+ALTER TABLE `users`
+    ADD COLUMN `guest` tinyint(1) NOT NULL DEFAULT 0,
+    ADD COLUMN `email` varchar(255) DEFAULT NULL;
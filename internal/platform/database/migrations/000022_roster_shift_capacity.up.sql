@@ -0,0 +1,2 @@
+ALTER TABLE `rosters`
+    ADD COLUMN `shift_capacity` BIGINT UNSIGNED NOT NULL DEFAULT 1;
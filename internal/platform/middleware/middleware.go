@@ -2,9 +2,11 @@ package middleware
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"GEWIS-Rooster/internal/platform/signing"
+	"GEWIS-Rooster/internal/token"
 	"GEWIS-Rooster/internal/user"
 	"context"
-	"fmt"
 	"github.com/coreos/go-oidc/v3/oidc"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -12,15 +14,20 @@ import (
 	"golang.org/x/oauth2"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
 )
 
-var (
-	verifier *oidc.IDTokenVerifier
-)
-
 type AuthProvider interface {
-	HandleLocalAuthentication(ctx *gin.Context) (string, error)
+	CheckSession(sessionID uint) (*models.Session, error)
+}
+
+type AuditRecorder interface {
+	Record(entry *models.AuditLog) error
+}
+
+type TokenProvider interface {
+	Authenticate(raw string) (*models.PersonalAccessToken, error)
 }
 
 type UserProvider interface {
@@ -29,34 +36,25 @@ type UserProvider interface {
 
 type AuthMiddlewareInterface interface {
 	AuthMiddlewareCheck() gin.HandlerFunc
-	SetupOIDC() (*oidc.IDToken, *oidc.Config)
+	SetupOIDC(issuer string) (*oidc.Provider, *oauth2.Config, *oidc.IDTokenVerifier)
 }
 
 type AuthMiddleware struct {
-	authService AuthProvider
-	userService UserProvider
+	authService  AuthProvider
+	userService  UserProvider
+	tokenService TokenProvider
+	audit        AuditRecorder
+	keys         *signing.KeySet
 }
 
-func NewAuthMiddleware(auth AuthProvider, user UserProvider) *AuthMiddleware {
-	return &AuthMiddleware{authService: auth, userService: user}
+func NewAuthMiddleware(auth AuthProvider, user UserProvider, tokens TokenProvider, audit AuditRecorder, keys *signing.KeySet) *AuthMiddleware {
+	return &AuthMiddleware{authService: auth, userService: user, tokenService: tokens, audit: audit, keys: keys}
 }
 
-// AuthMiddlewareCheck creates a middleware that validates OIDC tokens
+// AuthMiddlewareCheck creates a middleware that validates internal tokens and
+// personal access tokens
 func (a *AuthMiddleware) AuthMiddlewareCheck() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		if os.Getenv("DEV_TYPE") == "local" {
-			token, err := a.authService.HandleLocalAuthentication(c)
-
-			if err != nil {
-				return
-			}
-
-			c.Header("Authorization", "Bearer "+token)
-
-			c.Next()
-			return
-		}
-
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
 			c.AbortWithStatusJSON(401, gin.H{"error": "Missing Authorization header"})
@@ -75,20 +73,13 @@ func (a *AuthMiddleware) AuthMiddlewareCheck() gin.HandlerFunc {
 			return
 		}
 
-		secret := os.Getenv("JWT_SECRET")
-		if strings.TrimSpace(secret) == "" {
-			log.Error().Msg("INTERNAL_JWT_SECRET is not set or empty")
-			c.AbortWithStatusJSON(500, gin.H{"error": "Internal server error"})
+		if strings.HasPrefix(tokenString, token.Prefix) {
+			a.authenticateAccessToken(c, tokenString)
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			// Ensure the token's signing method is HS256
-			if token.Method.Alg() != jwt.SigningMethodHS256.Alg() {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(secret), nil
-		})
+		// The key is picked by the kid header, HS256 tokens use JWT_SECRET
+		token, err := jwt.Parse(tokenString, a.keys.Keyfunc, jwt.WithValidMethods(a.keys.ValidMethods()))
 
 		if err != nil || !token.Valid {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
@@ -96,6 +87,7 @@ func (a *AuthMiddleware) AuthMiddlewareCheck() gin.HandlerFunc {
 		}
 
 		var authUser models.User
+		var session *models.Session
 
 		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
 			subValue, exists := claims["sub"]
@@ -113,6 +105,19 @@ func (a *AuthMiddleware) AuthMiddlewareCheck() gin.HandlerFunc {
 				return
 			}
 
+			// Access tokens of revoked sessions are rejected before they expire
+			sessionID, ok := claims["sid"].(float64)
+			if !ok {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session missing in token"})
+				return
+			}
+
+			session, err = a.authService.CheckSession(uint(sessionID))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+				return
+			}
+
 			users, err := a.userService.Get(&user.FilterParams{GEWISID: &gewisID})
 
 			if err != nil || len(users) != 1 {
@@ -127,17 +132,72 @@ func (a *AuthMiddleware) AuthMiddlewareCheck() gin.HandlerFunc {
 		}
 
 		c.Set("userID", authUser.ID)
+		c.Set("sessionID", session.ID)
+
+		if session.ImpersonatorID != nil {
+			a.impersonate(c, session)
+			return
+		}
+
 		c.Next()
 	}
 }
 
-func (a *AuthMiddleware) SetupOIDC() (*oidc.Provider, *oauth2.Config) {
+// impersonate runs the request as the impersonated user. Responses carry the
+// admin in the X-Impersonated-By header and every request is audited.
+func (a *AuthMiddleware) impersonate(c *gin.Context, session *models.Session) {
+	actorID := *session.ImpersonatorID
+
+	authz.SetImpersonator(c, actorID)
+	c.Header("X-Impersonated-By", strconv.FormatUint(uint64(actorID), 10))
+
+	c.Next()
+
+	err := a.audit.Record(&models.AuditLog{
+		ActorID:   actorID,
+		UserID:    session.UserID,
+		SessionID: &session.ID,
+		Method:    c.Request.Method,
+		Route:     c.FullPath(),
+		Path:      c.Request.URL.Path,
+		Status:    c.Writer.Status(),
+	})
+	if err != nil {
+		log.Error().Err(err).Uint("actor_id", actorID).Str("path", c.Request.URL.Path).Msg("Failed to record impersonated request")
+	}
+}
+
+// authenticateAccessToken signs the request in with a personal access token,
+// limited to the organs and scope of the token.
+func (a *AuthMiddleware) authenticateAccessToken(c *gin.Context, raw string) {
+	accessToken, err := a.tokenService.Authenticate(raw)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	organIDs := make([]uint, 0, len(accessToken.Organs))
+	for _, organ := range accessToken.Organs {
+		organIDs = append(organIDs, organ.ID)
+	}
+
+	c.Set("userID", accessToken.UserID)
+	authz.SetTokenScope(c, authz.TokenScope{
+		OrganIDs: organIDs,
+		ReadOnly: accessToken.Scope != models.TokenScopeWrite,
+	})
+	c.Next()
+}
+
+// SetupOIDC discovers the identity provider and returns the OAuth2 config for
+// the login flow, together with the verifier for the ID tokens it issues.
+func (a *AuthMiddleware) SetupOIDC(issuer string) (*oidc.Provider, *oauth2.Config, *oidc.IDTokenVerifier) {
 	ctx := context.Background()
 
-	provider, err := oidc.NewProvider(ctx, "https://auth.gewis.nl/realms/GEWISWG")
+	provider, err := oidc.NewProvider(ctx, issuer)
 	if err != nil {
-		log.Fatal().Err(err).Msg("Failed to create OIDC provider")
-		return nil, nil
+		log.Fatal().Err(err).Str("issuer", issuer).Msg("Failed to create OIDC provider")
+		return nil, nil, nil
 	}
 
 	clientID := os.Getenv("CLIENT_ID")
@@ -150,24 +210,9 @@ func (a *AuthMiddleware) SetupOIDC() (*oidc.Provider, *oauth2.Config) {
 		Scopes:       []string{oidc.ScopeOpenID},
 	}
 
-	err = setupOIDCVerifier(provider, clientID)
-	if err != nil {
-		return nil, nil
-	}
-
-	return provider, config
-}
-
-// setupOIDCVerifier creates and stores the OIDC verifier globally
-func setupOIDCVerifier(provider *oidc.Provider, clientID string) error {
-	verifier = provider.Verifier(&oidc.Config{
-		ClientID:          clientID,
-		SkipClientIDCheck: true,
-	})
-
-	if verifier == nil {
-		return fmt.Errorf("failed to create OIDC verifier")
-	}
+	// The verifier checks the signature, issuer, expiry and that the token was
+	// issued to this client
+	verifier := provider.Verifier(&oidc.Config{ClientID: clientID})
 
-	return nil
+	return provider, config, verifier
 }
@@ -0,0 +1,289 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no signing key configured, set JWT_KEYS_DIR or JWT_SECRET")
+	ErrUnknownKey   = errors.New("token is signed with an unknown key")
+)
+
+// Key is a key that verifies internal tokens, and signs them when the private
+// half is known.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+
+	private crypto.Signer
+	public  crypto.PublicKey
+}
+
+// KeySet holds the keys of internal tokens. Tokens are signed with a single
+// key and carry its ID in the kid header. Every key in the set verifies
+// tokens, so a new key can be rolled out while tokens signed with the previous
+// one are still in use.
+type KeySet struct {
+	signing *Key
+	keys    map[string]*Key
+
+	// secret signs HS256 tokens when no asymmetric key is configured, and keeps
+	// verifying them while switching to one
+	secret []byte
+}
+
+// LoadFromEnv builds the key set from the environment. JWT_KEYS_DIR is a
+// directory of PEM encoded RSA or Ed25519 keys, named after their key ID.
+// JWT_SIGNING_KEY_ID picks the private key that signs, which may be left out
+// when there is only one. JWT_SECRET is the HS256 fallback.
+func LoadFromEnv() (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*Key)}
+
+	if secret := strings.TrimSpace(os.Getenv("JWT_SECRET")); secret != "" {
+		set.secret = []byte(secret)
+	}
+
+	if dir := os.Getenv("JWT_KEYS_DIR"); dir != "" {
+		if err := set.loadDir(dir); err != nil {
+			return nil, err
+		}
+
+		if err := set.selectSigningKey(os.Getenv("JWT_SIGNING_KEY_ID")); err != nil {
+			return nil, err
+		}
+	}
+
+	if set.signing == nil && set.secret == nil {
+		return nil, ErrNoSigningKey
+	}
+
+	return set, nil
+}
+
+// Add adds a key to the set. A private key can sign, a public key only
+// verifies.
+func (s *KeySet) Add(id string, key interface{}) error {
+	k := &Key{ID: id}
+
+	switch typed := key.(type) {
+	case *rsa.PrivateKey:
+		k.Method, k.private, k.public = jwt.SigningMethodRS256, typed, &typed.PublicKey
+	case *rsa.PublicKey:
+		k.Method, k.public = jwt.SigningMethodRS256, typed
+	case ed25519.PrivateKey:
+		k.Method, k.private, k.public = jwt.SigningMethodEdDSA, typed, typed.Public()
+	case ed25519.PublicKey:
+		k.Method, k.public = jwt.SigningMethodEdDSA, typed
+	default:
+		return fmt.Errorf("key %s: unsupported key type %T, use RSA or Ed25519", id, key)
+	}
+
+	if _, exists := s.keys[id]; exists {
+		return fmt.Errorf("key %s: duplicate key ID", id)
+	}
+	s.keys[id] = k
+	return nil
+}
+
+// SetSigningKey picks the key that signs new tokens.
+func (s *KeySet) SetSigningKey(id string) error {
+	key, ok := s.keys[id]
+	if !ok {
+		return fmt.Errorf("signing key %s not found", id)
+	}
+	if key.private == nil {
+		return fmt.Errorf("signing key %s has no private key", id)
+	}
+
+	s.signing = key
+	return nil
+}
+
+// Sign signs the claims with the signing key, or with the HS256 secret when no
+// signing key is configured.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	if s.signing == nil {
+		if s.secret == nil {
+			return "", ErrNoSigningKey
+		}
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.secret)
+	}
+
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	token.Header["kid"] = s.signing.ID
+	return token.SignedString(s.signing.private)
+}
+
+// Keyfunc picks the key to verify a token with by its kid header. The
+// algorithm must match the key, so a public key can never be used as an HMAC
+// secret.
+func (s *KeySet) Keyfunc(token *jwt.Token) (interface{}, error) {
+	if token.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+		if s.secret == nil {
+			return nil, ErrUnknownKey
+		}
+		return s.secret, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok || key.Method.Alg() != token.Method.Alg() {
+		return nil, ErrUnknownKey
+	}
+	return key.public, nil
+}
+
+// ValidMethods lists the algorithms tokens may be signed with.
+func (s *KeySet) ValidMethods() []string {
+	seen := make(map[string]bool)
+	var methods []string
+	add := func(alg string) {
+		if !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+
+	if s.secret != nil {
+		add(jwt.SigningMethodHS256.Alg())
+	}
+	for _, id := range s.keyIDs() {
+		add(s.keys[id].Method.Alg())
+	}
+	return methods
+}
+
+// JWK is a public key in JSON Web Key format.
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Curve string `json:"crv,omitempty"`
+	X     string `json:"x,omitempty"`
+} // @name JWK
+
+// JWKS is a JSON Web Key Set.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+} // @name JWKS
+
+// JWKS returns the public keys of the set, so other services can verify
+// internal tokens. The HS256 secret is never published.
+func (s *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, id := range s.keyIDs() {
+		key := s.keys[id]
+		jwk := JWK{KeyID: id, Algorithm: key.Method.Alg(), Use: "sig"}
+
+		switch public := key.public.(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+	return jwks
+}
+
+func (s *KeySet) keyIDs() []string {
+	ids := make([]string, 0, len(s.keys))
+	for id := range s.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+func (s *KeySet) loadDir(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no .pem keys found in %s", dir)
+	}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		key, err := parsePEM(data)
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+
+		id := strings.TrimSuffix(filepath.Base(file), ".pem")
+		if err := s.Add(id, key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// selectSigningKey uses the configured key, or the only private key when none
+// is configured.
+func (s *KeySet) selectSigningKey(id string) error {
+	if id != "" {
+		return s.SetSigningKey(id)
+	}
+
+	var private []string
+	for _, keyID := range s.keyIDs() {
+		if s.keys[keyID].private != nil {
+			private = append(private, keyID)
+		}
+	}
+
+	if len(private) != 1 {
+		return fmt.Errorf("found %d private keys, set JWT_SIGNING_KEY_ID to pick one", len(private))
+	}
+	return s.SetSigningKey(private[0])
+}
+
+func parsePEM(data []byte) (interface{}, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	}
+	return nil, fmt.Errorf("unsupported PEM block %q", block.Type)
+}
@@ -0,0 +1,121 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeKey(t *testing.T, dir string, id string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, id+".pem"), data, 0o600))
+}
+
+func claims() jwt.MapClaims {
+	return jwt.MapClaims{"sub": 1, "exp": time.Now().Add(time.Minute).Unix()}
+}
+
+func parse(set *KeySet, raw string) (*jwt.Token, error) {
+	return jwt.Parse(raw, set.Keyfunc, jwt.WithValidMethods(set.ValidMethods()))
+}
+
+func TestLoadFromEnv_RotatesKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeKey(t, dir, "2025-rsa", rsaKey)
+	writeKey(t, dir, "2026-ed", edKey)
+
+	t.Setenv("JWT_SECRET", "")
+	t.Setenv("JWT_KEYS_DIR", dir)
+	t.Setenv("JWT_SIGNING_KEY_ID", "2025-rsa")
+	old, err := LoadFromEnv()
+	require.NoError(t, err)
+
+	oldToken, err := old.Sign(claims())
+	require.NoError(t, err)
+
+	t.Setenv("JWT_SIGNING_KEY_ID", "2026-ed")
+	current, err := LoadFromEnv()
+	require.NoError(t, err)
+
+	newToken, err := current.Sign(claims())
+	require.NoError(t, err)
+
+	parsed, err := parse(current, newToken)
+	require.NoError(t, err)
+	assert.Equal(t, "2026-ed", parsed.Header["kid"])
+	assert.Equal(t, jwt.SigningMethodEdDSA.Alg(), parsed.Method.Alg())
+
+	// Tokens signed with the previous key stay valid while it is in the set
+	parsed, err = parse(current, oldToken)
+	require.NoError(t, err)
+	assert.Equal(t, "2025-rsa", parsed.Header["kid"])
+
+	jwks := current.JWKS()
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "RSA", jwks.Keys[0].KeyType)
+	assert.Equal(t, "OKP", jwks.Keys[1].KeyType)
+	assert.Equal(t, "Ed25519", jwks.Keys[1].Curve)
+}
+
+func TestLoadFromEnv_SecretFallback(t *testing.T) {
+	t.Setenv("JWT_KEYS_DIR", "")
+	t.Setenv("JWT_SECRET", "test-secret")
+
+	set, err := LoadFromEnv()
+	require.NoError(t, err)
+
+	raw, err := set.Sign(claims())
+	require.NoError(t, err)
+
+	parsed, err := parse(set, raw)
+	require.NoError(t, err)
+	assert.Equal(t, jwt.SigningMethodHS256.Alg(), parsed.Method.Alg())
+	assert.Empty(t, set.JWKS().Keys)
+
+	t.Setenv("JWT_SECRET", "")
+	_, err = LoadFromEnv()
+	assert.ErrorIs(t, err, ErrNoSigningKey)
+}
+
+func TestKeyfunc_RejectsUnknownAndMismatchedKeys(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	set := &KeySet{keys: make(map[string]*Key)}
+	require.NoError(t, set.Add("rsa", rsaKey))
+	require.NoError(t, set.SetSigningKey("rsa"))
+
+	unknown := jwt.NewWithClaims(jwt.SigningMethodRS256, claims())
+	unknown.Header["kid"] = "other"
+	raw, err := unknown.SignedString(rsaKey)
+	require.NoError(t, err)
+	_, err = parse(set, raw)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	// Without a secret HS256 is not accepted, not even signed with the public key
+	der, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	require.NoError(t, err)
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims())
+	forged.Header["kid"] = "rsa"
+	raw, err = forged.SignedString(der)
+	require.NoError(t, err)
+	_, err = parse(set, raw)
+	assert.Error(t, err)
+}
@@ -15,6 +15,13 @@ type CreateRequest struct {
 	Shifts []string `json:"shifts"`
 
 	TemplateID *uint `json:"templateId"`
+
+	Mode models.RosterMode `json:"mode"`
+
+	WaitlistEnabled bool `json:"waitlistEnabled"`
+
+	// ShiftCapacity defaults to 1
+	ShiftCapacity uint `json:"shiftCapacity"`
 } // @name RosterCreateRequest
 
 type UpdateRequest struct {
@@ -23,6 +30,14 @@ type UpdateRequest struct {
 	Date *time.Time `json:"date"`
 
 	Saved *bool `json:"saved"`
+
+	Mode *models.RosterMode `json:"mode"`
+
+	WaitlistEnabled *bool `json:"waitlistEnabled"`
+
+	ShiftCapacity *uint `json:"shiftCapacity"`
+
+	Published *bool `json:"published"`
 } // @name RosterUpdateRequest
 
 type ShiftCreateRequest struct {
@@ -51,16 +66,107 @@ type AnswerUpdateRequest struct {
 	Value string `json:"value"`
 } // @name AnswerUpdateRequest
 
+type OnBehalfAnswer struct {
+	RosterShiftID uint `json:"rosterShiftId" binding:"required"`
+
+	Value string `json:"value" binding:"required"`
+} // @name OnBehalfAnswer
+
+type OnBehalfAnswerRequest struct {
+	UserID uint `json:"userId" binding:"required"`
+
+	Answers []OnBehalfAnswer `json:"answers" binding:"required,min=1,dive"`
+} // @name OnBehalfAnswerRequest
+
+type AnswerHistoryFilterParams struct {
+	RosterID *uint `form:"-"`
+
+	OrganID *uint `form:"organId"`
+
+	UserID *uint `form:"userId"`
+} // @name AnswerHistoryFilterParams
+
 type SavedShiftUpdateRequest struct {
 	UserIDs []uint `json:"users"`
+
+	Capacity *uint `json:"capacity"`
 } // @name SavedShiftUpdateRequest
 
+type ClaimResponse struct {
+	SavedShift *models.SavedShift `json:"savedShift"`
+
+	Waitlisted bool `json:"waitlisted"`
+
+	// Position is the 1-based place on the waitlist, or 0 when the shift was claimed
+	Position int `json:"position"`
+} // @name SavedShiftClaimResponse
+
 type SavedShiftResponse struct {
 	SavedShifts []*models.SavedShift `json:"savedShifts"`
 
 	SavedShiftOrdering []*models.SavedShiftOrdering `json:"savedShiftOrdering"`
 } // @name SavedShiftResponse
 
+type ShiftAnswerCount struct {
+	RosterShiftID uint `json:"rosterShiftId"`
+
+	ShiftName string `json:"shiftName"`
+
+	Counts map[string]int `json:"counts"`
+} // @name ShiftAnswerCount
+
+type MemberAnswerStatus struct {
+	UserID uint `json:"userId"`
+
+	Name string `json:"name"`
+
+	Username string `json:"username"`
+
+	Answered int `json:"answered"`
+
+	Total int `json:"total"`
+} // @name MemberAnswerStatus
+
+type SummaryResponse struct {
+	RosterID uint `json:"rosterId"`
+
+	Shifts []*ShiftAnswerCount `json:"shifts"`
+
+	NotAnswered []*MemberAnswerStatus `json:"notAnswered"`
+
+	PartiallyAnswered []*MemberAnswerStatus `json:"partiallyAnswered"`
+} // @name RosterSummaryResponse
+
+type ScheduleUser struct {
+	ID uint `json:"id"`
+
+	Name string `json:"name"`
+
+	Username string `json:"username"`
+
+	Guest bool `json:"guest"`
+} // @name ScheduleUser
+
+type ScheduleShift struct {
+	ShiftName string `json:"shiftName"`
+
+	Users []*ScheduleUser `json:"users"`
+} // @name ScheduleShift
+
+type ScheduleResponse struct {
+	RosterID uint `json:"rosterId"`
+
+	Name string `json:"name"`
+
+	Date time.Time `json:"date"`
+
+	OrganID uint `json:"organId"`
+
+	Published bool `json:"published"`
+
+	Shifts []*ScheduleShift `json:"shifts"`
+} // @name RosterSchedule
+
 type FilterParams struct {
 	ID       *uint      `form:"id"`
 	Date     *time.Time `form:"date" time_format:"2006-01-02"`
@@ -80,6 +186,14 @@ type TemplateFilterParams struct {
 	OrganID *uint `form:"organId"`
 } // @name TemplateFilterParams
 
+// TrashResponse lists the rosters and templates of an organ in the trash,
+// most recently deleted first.
+type TrashResponse struct {
+	Rosters []*models.Roster `json:"rosters"`
+
+	Templates []*models.RosterTemplate `json:"templates"`
+} // @name RosterTrashResponse
+
 // TODO updating roster templates does not yet work
 
 type TemplateUpdateParams struct {
@@ -3,6 +3,7 @@ package roster
 import (
 	"GEWIS-Rooster/internal/models"
 	_ "GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -16,26 +17,29 @@ type Handler struct {
 	rosterService Service
 }
 
-func NewRosterHandler(rosterService Service, rg *gin.RouterGroup, db *gorm.DB) *Handler {
+func NewRosterHandler(rosterService Service, rg *authz.Router) *Handler {
 	h := &Handler{rosterService: rosterService}
 	g := rg.Group("/roster")
 
-	h.registerRosterRoutes(g, db)
-	h.registerShiftRoutes(g, db)
-	h.registerTemplateRoutes(g, db)
+	h.registerRosterRoutes(g)
+	h.registerShiftRoutes(g)
+	h.registerTemplateRoutes(g)
+	h.registerClaimRoutes(g)
+	h.registerTrashRoutes(g)
 
-	g.POST("/:id/fill", requireRosterOrganRoleParam(db, "id", models.RoleAdmin), h.FillRosterPreferences)
+	g.POST("/:id/fill", authz.Require(rosterParam("id"), models.PermRosterAssign), h.FillRosterPreferences)
 
-	g.POST("/:id/save", requireRosterOrganRoleParam(db, "id", models.RoleAdmin), h.SaveRoster)
-	g.PATCH("/saved-shift/:id", h.UpdateSavedShift)
-	g.GET("/saved-shift/:id", h.GetSavedRoster)
+	g.POST("/:id/save", authz.Require(rosterParam("id"), models.PermRosterAssign), h.SaveRoster)
+	g.PATCH("/saved-shift/:id", authz.Require(savedShiftParam("id"), models.PermRosterAssign), h.UpdateSavedShift)
+	// Unlike the other saved-shift routes this one takes the roster ID
+	g.GET("/saved-shift/:id", authz.Require(rosterParam("id"), models.PermRosterView), h.GetSavedRoster)
 
-	g.POST("/shift-groups", requireRosterOrganRoleBody(db, models.RoleAdmin), h.CreateShiftGroup)
-	g.GET("/shift-groups", h.GetShiftGroups)
-	g.GET("/shift-groups/:id", h.GetShiftGroup)
+	g.POST("/shift-groups", authz.Require(authz.OrganBody("organId"), models.PermTemplateEdit), h.CreateShiftGroup)
+	g.GET("/shift-groups", authz.Require(authz.OrganQuery("organ_id"), models.PermRosterView), h.GetShiftGroups)
+	g.GET("/shift-groups/:id", authz.Require(shiftGroupParam("id"), models.PermRosterView), h.GetShiftGroup)
 
-	g.GET("/shift-groups/:id/priority", requireShiftGroupOrganRoleParams(db, models.RoleAdmin), h.GetShiftGroupPriorities)
-	g.PUT("/shift-groups/:id/priority", requireShiftGroupOrganRoleParams(db, models.RoleAdmin), h.UpdateShiftGroupPriority)
+	g.GET("/shift-groups/:id/priority", authz.Require(shiftGroupParam("id"), models.PermRosterAssign), h.GetShiftGroupPriorities)
+	g.PUT("/shift-groups/:id/priority", authz.Require(shiftGroupParam("id"), models.PermRosterAssign), h.UpdateShiftGroupPriority)
 
 	return h
 }
@@ -60,7 +64,13 @@ func (h *Handler) FillRosterPreferences(c *gin.Context) {
 		return
 	}
 
-	answers, err := h.rosterService.FillRosterPreferences(uint(rosterID))
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	answers, err := h.rosterService.FillRosterPreferences(uint(rosterID), userID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	}
@@ -147,6 +157,7 @@ func (h *Handler) UpdateSavedShift(c *gin.Context) {
 //
 //	@Summary	Get all saved shifts for a specific roster
 //	@Security	BearerAuth
+//	@Description	Members can only see the saved shifts once the roster is published or when it is a self sign-up roster
 //	@Tags		Saved Shift
 //	@Accept		json
 //	@Produce	json
@@ -164,7 +175,23 @@ func (h *Handler) GetSavedRoster(c *gin.Context) {
 		return
 	}
 
-	savedShifts, savedShiftOrdering, err := h.rosterService.GetSavedRoster(uint(id))
+	rosterID := uint(id)
+	canAssign := hasPermission(c, models.PermRosterAssign)
+	if !canAssign {
+		rosters, err := h.rosterService.GetRosters(&FilterParams{ID: &rosterID})
+		if err != nil || len(rosters) != 1 {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Roster not found"})
+			return
+		}
+
+		// Self sign-up rosters double as the claim board, so members always see those
+		if !rosters[0].Published && rosters[0].Mode != models.ModeSelfSignup {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Roster has not been published yet"})
+			return
+		}
+	}
+
+	savedShifts, savedShiftOrdering, err := h.rosterService.GetSavedRoster(rosterID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -174,6 +201,10 @@ func (h *Handler) GetSavedRoster(c *gin.Context) {
 		SavedShifts:        savedShifts,
 		SavedShiftOrdering: savedShiftOrdering,
 	}
+	if !canAssign {
+		// The ordering is the admin's assignment aid and not part of the member view
+		response.SavedShiftOrdering = []*models.SavedShiftOrdering{}
+	}
 	// Log the entire struct as a field called "response"
 	log.Debug().Interface("response", response).Msg("Sending saved roster response")
 
@@ -0,0 +1,102 @@
+package roster
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) registerClaimRoutes(g *authz.Router) {
+	g.POST("/saved-shift/:id/claim", authz.Require(savedShiftParam("id"), models.PermShiftClaim), h.ClaimSavedShift)
+	g.DELETE("/saved-shift/:id/claim", authz.Require(savedShiftParam("id"), models.PermShiftClaim), h.ReleaseSavedShift)
+}
+
+// ClaimSavedShift
+//
+//	@Summary		Claim an open slot on a saved shift
+//	@Security		BearerAuth
+//	@Description	Assigns the authenticated member to a saved shift of a self sign-up roster. When the shift is full and the roster has a waitlist, the member is waitlisted instead.
+//	@Tags			Saved Shift
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"SavedShift ID"
+//	@Success		200	{object}	SavedShiftClaimResponse
+//	@Failure		400	{string}	string
+//	@Failure		404	{string}	string
+//	@Failure		409	{string}	string	"Shift is full or already claimed"
+//	@ID				claimSavedShift
+//	@Router			/roster/saved-shift/{id}/claim [post]
+func (h *Handler) ClaimSavedShift(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved shift ID"})
+		return
+	}
+
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	claim, err := h.rosterService.ClaimSavedShift(uint(id), userID)
+	if err != nil {
+		writeClaimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+// ReleaseSavedShift
+//
+//	@Summary		Release a claimed saved shift
+//	@Security		BearerAuth
+//	@Description	Removes the authenticated member from a saved shift or its waitlist. A freed slot goes to the first member on the waitlist.
+//	@Tags			Saved Shift
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"SavedShift ID"
+//	@Success		200	{object}	models.SavedShift
+//	@Failure		400	{string}	string
+//	@Failure		404	{string}	string
+//	@ID				releaseSavedShift
+//	@Router			/roster/saved-shift/{id}/claim [delete]
+func (h *Handler) ReleaseSavedShift(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid saved shift ID"})
+		return
+	}
+
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	saved, err := h.rosterService.ReleaseSavedShift(uint(id), userID)
+	if err != nil {
+		writeClaimError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, saved)
+}
+
+func writeClaimError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "SavedShift not found"})
+	case errors.Is(err, ErrShiftFull), errors.Is(err, ErrAlreadyClaimed):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, ErrNotSelfSignup), errors.Is(err, ErrNotClaimed):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update shift"})
+	}
+}
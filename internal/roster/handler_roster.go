@@ -2,6 +2,8 @@ package roster
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"gorm.io/gorm"
@@ -9,12 +11,14 @@ import (
 	"strconv"
 )
 
-func (h *Handler) registerRosterRoutes(g *gin.RouterGroup, db *gorm.DB) {
-	g.POST("", requireRosterOrganRoleBody(db, models.RoleAdmin), h.CreateRoster)
-	g.GET("", requireRosterOrganRoleQuery(db, "organId", models.RoleMember), h.GetRosters)
-	g.GET(":id", requireRosterOrganRoleParam(db, "id", models.RoleMember), h.GetRoster)
-	g.PATCH("/:id", requireRosterOrganRoleParam(db, "id", models.RoleAdmin), h.UpdateRoster)
-	g.DELETE("/:id", requireRosterOrganRoleParam(db, "id", models.RoleAdmin), h.DeleteRoster)
+func (h *Handler) registerRosterRoutes(g *authz.Router) {
+	g.POST("", authz.Require(authz.OrganBody("organId"), models.PermRosterCreate), h.CreateRoster)
+	g.GET("", authz.Require(authz.OrganQuery("organId"), models.PermRosterView), h.GetRosters)
+	g.GET(":id", authz.Require(rosterParam("id"), models.PermRosterView), h.GetRoster)
+	g.PATCH("/:id", authz.Require(rosterParam("id"), models.PermRosterCreate), h.UpdateRoster)
+	g.DELETE("/:id", authz.Require(rosterParam("id"), models.PermRosterCreate), h.DeleteRoster)
+	g.GET("/:id/summary", authz.Require(rosterParam("id"), models.PermRosterAssign), h.GetRosterSummary)
+	g.GET("/:id/schedule", authz.Require(rosterParam("id"), models.PermRosterView), h.GetRosterSchedule)
 }
 
 // CreateRoster
@@ -71,6 +75,8 @@ func (h *Handler) GetRosters(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 	}
 
+	hideOtherAnswers(c, rosters)
+
 	c.JSON(http.StatusOK, rosters)
 }
 
@@ -108,6 +114,8 @@ func (h *Handler) GetRoster(c *gin.Context) {
 		return
 	}
 
+	hideOtherAnswers(c, roster)
+
 	c.JSON(http.StatusOK, roster)
 }
 
@@ -149,9 +157,10 @@ func (h *Handler) UpdateRoster(c *gin.Context) {
 
 // DeleteRoster
 //
-//	@Summary	DeleteRoster a roster
-//	@Security	BearerAuth
-//	@Tags		Roster
+//	@Summary		DeleteRoster a roster
+//	@Security		BearerAuth
+//	@Description	Moves the roster to the trash of the organ, from which it can be restored until it is purged
+//	@Tags			Roster
 //	@Accept		json
 //	@Produce	json
 //	@Param		id	path		int	true	"Roster ID"
@@ -173,69 +182,100 @@ func (h *Handler) DeleteRoster(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Roster deleted",
+		"message": "Roster moved to the trash",
 	})
 }
 
-func requireRosterOrganRoleQuery(db *gorm.DB, queryStr string, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		val := c.Query(queryStr)
-		if val == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": queryStr + " is required"})
-			return
-		}
-		checkAccess(c, db, val, minRole)
+// GetRosterSummary
+//
+//	@Summary		Get answer statistics and non-responders for a roster
+//	@Security		BearerAuth
+//	@Description	Counts the answers per shift for every roster value and lists the organ members that have not answered at all or only partially
+//	@Tags			Roster
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint	true	"Roster ID"
+//	@Success		200	{object}	RosterSummaryResponse
+//	@Failure		400	{string}	string
+//	@Failure		404	{string}	string
+//	@ID				getRosterSummary
+//	@Router			/roster/{id}/summary [get]
+func (h *Handler) GetRosterSummary(c *gin.Context) {
+	rosterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid roster ID"})
+		return
 	}
-}
 
-// requireRosterOrganRoleParam validates the existence of a roster by its ID
-// from the URL parameters and ensures the current user has the required
-// minimum role within that roster's organization.
-func requireRosterOrganRoleParam(db *gorm.DB, paramStr string, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		rosterID := c.Param(paramStr)
-		if rosterID == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": paramStr + " is required"})
+	summary, err := h.rosterService.GetRosterSummary(uint(rosterID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Roster not found"})
 			return
 		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
 
-		var roster models.Roster
-		if err := db.First(&roster, "id = ?", rosterID).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Roster not found"})
-			return
-		}
+	c.JSON(http.StatusOK, summary)
+}
 
-		checkAccess(c, db, roster.OrganID, minRole)
+// GetRosterSchedule
+//
+//	@Summary		Get the published schedule of a roster
+//	@Security		BearerAuth
+//	@Description	Read-only view of who works which shift. Members can only see it once the roster is published, admins can always see it.
+//	@Tags			Roster
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		uint	true	"Roster ID"
+//	@Success		200	{object}	RosterSchedule
+//	@Failure		400	{string}	string
+//	@Failure		403	{string}	string
+//	@Failure		404	{string}	string
+//	@ID				getRosterSchedule
+//	@Router			/roster/{id}/schedule [get]
+func (h *Handler) GetRosterSchedule(c *gin.Context) {
+	rosterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid roster ID"})
+		return
 	}
-}
 
-func requireRosterOrganRoleBody(db *gorm.DB, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var body struct {
-			OrganID uint `json:"organId"`
-		}
-		if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil || body.OrganID == 0 {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Valid organId is required in body"})
+	schedule, err := h.rosterService.GetRosterSchedule(uint(rosterID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Roster not found"})
 			return
 		}
-		checkAccess(c, db, body.OrganID, minRole)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
 	}
+
+	if !schedule.Published && !hasPermission(c, models.PermRosterAssign) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Roster has not been published yet"})
+		return
+	}
+
+	c.JSON(http.StatusOK, schedule)
 }
 
-func requireShiftGroupOrganRoleParams(db *gorm.DB, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		groupID := c.Param("id")
-		if groupID == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID"})
-			return
-		}
+// hideOtherAnswers limits members to their own answers, also once the roster
+// is published. Who works which shift is shown by the schedule instead. Members
+// with roster.assign keep the full view.
+func hideOtherAnswers(c *gin.Context, rosters []*models.Roster) {
+	if hasPermission(c, models.PermRosterAssign) {
+		return
+	}
 
-		var shiftGroup models.ShiftGroup
-		if err := db.First(&shiftGroup, "id = ?", groupID).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Shift group not found"})
-			return
+	userID, _ := authenticatedUserID(c)
+	for _, roster := range rosters {
+		ownAnswers := make([]models.RosterAnswer, 0)
+		for _, answer := range roster.RosterAnswer {
+			if answer.UserID == userID {
+				ownAnswers = append(ownAnswers, answer)
+			}
 		}
-
-		checkAccess(c, db, shiftGroup.OrganID, minRole)
+		roster.RosterAnswer = ownAnswers
 	}
 }
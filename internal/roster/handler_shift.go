@@ -2,6 +2,7 @@ package roster
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
 	"errors"
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
@@ -10,20 +11,24 @@ import (
 	"strconv"
 )
 
-func (h *Handler) registerShiftRoutes(g *gin.RouterGroup, db *gorm.DB) {
+func (h *Handler) registerShiftRoutes(g *authz.Router) {
 	shiftGroup := g.Group("/shift")
 	{
-		shiftGroup.POST("", requireShiftOrganRoleBody(db, models.RoleAdmin), h.CreateRosterShift)
-		shiftGroup.PATCH("/:id", requireShiftOrganRoleParam(db, "id", models.RoleAdmin), h.UpdateRosterShift)
-		shiftGroup.DELETE("/:id", requireShiftOrganRoleParam(db, "id", models.RoleAdmin), h.DeleteRosterShift)
+		shiftGroup.POST("", authz.Require(rosterBody(), models.PermRosterCreate), h.CreateRosterShift)
+		shiftGroup.PATCH("/:id", authz.Require(rosterShiftParam("id"), models.PermRosterCreate), h.UpdateRosterShift)
+		shiftGroup.DELETE("/:id", authz.Require(rosterShiftParam("id"), models.PermRosterCreate), h.DeleteRosterShift)
 	}
 
+	// Members may write their own answers, answers of others require roster.assign
 	answerGroup := g.Group("/answer")
 	{
-		answerGroup.POST("", requireShiftOrganRoleBody(db, models.RoleMember), h.CreateRosterAnswer)
-		answerGroup.PATCH("/:id", requireShiftAnswerOrganRoleParam(db, "id", models.RoleMember), h.UpdateRosterAnswer)
+		answerGroup.POST("", authz.Require(answerBody(), models.PermRosterAssign), h.CreateRosterAnswer)
+		answerGroup.PATCH("/:id", authz.Require(answerParam("id"), models.PermRosterAssign), h.UpdateRosterAnswer)
 	}
 
+	g.POST("/:id/answer/on-behalf", authz.Require(rosterParam("id"), models.PermRosterAssign), h.AnswerOnBehalf)
+	g.GET("/answer-history", authz.Require(authz.OrganQuery("organId"), models.PermRosterAssign), h.GetAnswerHistory)
+	g.GET("/:id/answer-history", authz.Require(rosterParam("id"), models.PermRosterAssign), h.GetRosterAnswerHistory)
 }
 
 // CreateRosterShift
@@ -146,7 +151,13 @@ func (h *Handler) CreateRosterAnswer(c *gin.Context) {
 		return
 	}
 
-	createdAnswer, err := h.rosterService.CreateRosterAnswer(param)
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	createdAnswer, err := h.rosterService.CreateRosterAnswer(param, userID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -183,7 +194,13 @@ func (h *Handler) UpdateRosterAnswer(c *gin.Context) {
 		return
 	}
 
-	updatedAnswer, err := h.rosterService.UpdateRosterAnswer(uint(id), &updateParams)
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	updatedAnswer, err := h.rosterService.UpdateRosterAnswer(uint(id), &updateParams, userID)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
@@ -192,63 +209,120 @@ func (h *Handler) UpdateRosterAnswer(c *gin.Context) {
 	c.JSON(http.StatusOK, updatedAnswer)
 }
 
-// requireRosterOrganRoleParam validates the existence of a roster by its ID
-// from the URL parameters and ensures the current user has the required
-// minimum role within that roster's organization.
-func requireShiftOrganRoleParam(db *gorm.DB, paramStr string, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		shiftID := c.Param(paramStr)
-		if shiftID == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": paramStr + " is required"})
-			return
-		}
+// AnswerOnBehalf
+//
+//	@Summary		Enter answers on behalf of a member
+//	@Security		BearerAuth
+//	@Description	Creates or updates the answers of a member of the organ. The admin is stored as the one who entered the answers and the member is notified.
+//	@Tags			Roster Answer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id				path		uint					true	"Roster ID"
+//	@Param			createParams	body		OnBehalfAnswerRequest	true	"Answers of the member"
+//	@Success		200				{array}		models.RosterAnswer
+//	@Failure		400				{string}	string
+//	@Failure		404				{string}	string
+//	@ID				answerOnBehalf
+//	@Router			/roster/{id}/answer/on-behalf [post]
+func (h *Handler) AnswerOnBehalf(c *gin.Context) {
+	rosterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid roster ID"})
+		return
+	}
 
-		var shift models.RosterShift
-		if err := db.Preload("Roster").First(&shift, "id = ?", shiftID).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Roster not found"})
-			return
-		}
+	var params OnBehalfAnswerRequest
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request " + err.Error()})
+		return
+	}
 
-		checkAccess(c, db, shift.Roster.OrganID, minRole)
+	userID, ok := authenticatedUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
 	}
-}
 
-// requireShiftAnswerOrganRoleParam validates the existence of a roster by its ID
-// from the URL parameters and ensures the current user has the required
-// minimum role within that roster's organization.
-func requireShiftAnswerOrganRoleParam(db *gorm.DB, paramStr string, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		answerID := c.Param(paramStr)
-		if answerID == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": paramStr + " is required"})
+	answers, err := h.rosterService.AnswerOnBehalf(uint(rosterID), &params, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 			return
 		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		var answer models.RosterAnswer
-		if err := db.Preload("Roster").First(&answer, "id = ?", answerID).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Answer not found"})
-			return
-		}
+	c.JSON(http.StatusOK, answers)
+}
 
-		checkAccess(c, db, answer.Roster.OrganID, minRole)
+// GetAnswerHistory
+//
+//	@Summary		Get the answer history of an organ
+//	@Security		BearerAuth
+//	@Description	Lists every create and update of roster answers within an organ, newest first. Filter on userId to get the history of a single member.
+//	@Tags			Roster Answer
+//	@Accept			json
+//	@Produce		json
+//	@Param			organId	query		uint	true	"Organ ID"
+//	@Param			userId	query		uint	false	"User ID"
+//	@Success		200		{array}		models.RosterAnswerChange
+//	@Failure		400		{string}	string
+//	@ID				getAnswerHistory
+//	@Router			/roster/answer-history [get]
+func (h *Handler) GetAnswerHistory(c *gin.Context) {
+	var params AnswerHistoryFilterParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		return
 	}
+
+	history, err := h.rosterService.GetAnswerHistory(&params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
 }
 
-func requireShiftOrganRoleBody(db *gorm.DB, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		var body struct {
-			RosterID uint `json:"rosterID"`
-		}
-		if err := c.ShouldBindBodyWith(&body, binding.JSON); err != nil || body.RosterID == 0 {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Valid rosterID is required in body"})
-			return
-		}
+// GetRosterAnswerHistory
+//
+//	@Summary		Get the answer history of a roster
+//	@Security		BearerAuth
+//	@Description	Lists every create and update of the answers of a roster, newest first. Filter on userId to get the history of a single member.
+//	@Tags			Roster Answer
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path		uint	true	"Roster ID"
+//	@Param			userId	query		uint	false	"User ID"
+//	@Success		200		{array}		models.RosterAnswerChange
+//	@Failure		400		{string}	string
+//	@Failure		404		{string}	string
+//	@ID				getRosterAnswerHistory
+//	@Router			/roster/{id}/answer-history [get]
+func (h *Handler) GetRosterAnswerHistory(c *gin.Context) {
+	rosterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid roster ID"})
+		return
+	}
 
-		var roster models.Roster
-		if err := db.First(&roster, "id = ?", body.RosterID).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Roster not found"})
-			return
-		}
-		checkAccess(c, db, roster.OrganID, minRole)
+	var params AnswerHistoryFilterParams
+	if err := c.ShouldBindQuery(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters: " + err.Error()})
+		return
 	}
+
+	id := uint(rosterID)
+	params.RosterID = &id
+	params.OrganID = nil
+
+	history, err := h.rosterService.GetAnswerHistory(&params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
 }
@@ -2,27 +2,30 @@ package roster
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
 	"errors"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 )
 
-func (h *Handler) registerTemplateRoutes(g *gin.RouterGroup, db *gorm.DB) {
+func (h *Handler) registerTemplateRoutes(g *authz.Router) {
 	templateGroup := g.Group("/template")
 	{
-		templateGroup.POST("", requireRosterOrganRoleBody(db, models.RoleAdmin), h.CreateRosterTemplate)
-		templateGroup.GET("", h.GetRosterTemplates)
-		templateGroup.GET("/:id", h.GetRosterTemplate)
-		templateGroup.PUT("/:id", requireTemplateOrganRoleParam(db, "id", models.RoleAdmin), h.UpdateRosterTemplate)
-		templateGroup.DELETE("/:id", requireTemplateOrganRoleParam(db, "id", models.RoleAdmin), h.DeleteRosterTemplate)
-
-		templateGroup.PATCH("/shift/:id", h.UpdateRosterTemplateShift)
-
-		templateGroup.POST("/shift-preference", h.CreateRosterTemplateShiftPreference)
-		templateGroup.GET("/shift-preference", h.GetRosterTemplateShiftPreferences)
-		templateGroup.PATCH("/shift-preference/:id", h.UpdateRosterTemplateShiftPreference)
+		templateGroup.POST("", authz.Require(authz.OrganBody("organId"), models.PermTemplateEdit), h.CreateRosterTemplate)
+		templateGroup.GET("", authz.Require(authz.OrganQuery("organId"), models.PermRosterView), h.GetRosterTemplates)
+		templateGroup.GET("/:id", authz.Require(templateParam("id"), models.PermRosterView), h.GetRosterTemplate)
+		templateGroup.PUT("/:id", authz.Require(templateParam("id"), models.PermTemplateEdit), h.UpdateRosterTemplate)
+		templateGroup.DELETE("/:id", authz.Require(templateParam("id"), models.PermTemplateEdit), h.DeleteRosterTemplate)
+
+		templateGroup.PATCH("/shift/:id", authz.Require(templateShiftParam("id"), models.PermTemplateEdit), h.UpdateRosterTemplateShift)
+
+		// Members may manage their own preferences, preferences of others require template.edit
+		templateGroup.POST("/shift-preference", authz.Require(preferenceBody(), models.PermTemplateEdit), h.CreateRosterTemplateShiftPreference)
+		templateGroup.GET("/shift-preference", authz.Require(preferenceQuery(), models.PermTemplateEdit), h.GetRosterTemplateShiftPreferences)
+		templateGroup.PATCH("/shift-preference/:id", authz.Require(preferenceParam("id"), models.PermTemplateEdit), h.UpdateRosterTemplateShiftPreference)
 	}
 }
 
@@ -159,9 +162,10 @@ func (h *Handler) UpdateRosterTemplate(c *gin.Context) {
 
 // DeleteRosterTemplate
 //
-//	@Summary	Deletes a roster template by ID
-//	@Security	BearerAuth
-//	@Tags		Roster
+//	@Summary		Deletes a roster template by ID
+//	@Security		BearerAuth
+//	@Description	Moves the template to the trash of the organ, from which it can be restored until it is purged
+//	@Tags			Roster
 //	@Accept		json
 //	@Produce	json
 //	@Param		id	path		int	true	"Template ID"
@@ -183,9 +187,10 @@ func (h *Handler) DeleteRosterTemplate(c *gin.Context) {
 	err = h.rosterService.DeleteRosterTemplate(id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Deleted successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "Template moved to the trash"})
 }
 
 // UpdateRosterTemplateShift
@@ -239,7 +244,7 @@ func (h *Handler) UpdateRosterTemplateShift(c *gin.Context) {
 //	@Router    /roster/template/shift-preference [post]
 func (h *Handler) CreateRosterTemplateShiftPreference(c *gin.Context) {
 	var params TemplateShiftPreferenceCreateRequest
-	if err := c.ShouldBindJSON(&params); err != nil {
+	if err := c.ShouldBindBodyWith(&params, binding.JSON); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON: " + err.Error()})
 		return
 	}
@@ -325,24 +330,3 @@ func (h *Handler) UpdateRosterTemplateShiftPreference(c *gin.Context) {
 
 	c.JSON(http.StatusOK, updatedPreference)
 }
-
-// requireRosterOrganRoleParam validates the existence of a roster by its ID
-// from the URL parameters and ensures the current user has the required
-// minimum role within that roster's organization.
-func requireTemplateOrganRoleParam(db *gorm.DB, paramStr string, minRole models.OrganRole) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		templateID := c.Param(paramStr)
-		if templateID == "" {
-			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": paramStr + " is required"})
-			return
-		}
-
-		var template models.RosterTemplate
-		if err := db.First(&template, "id = ?", templateID).Error; err != nil {
-			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "Roster not found"})
-			return
-		}
-
-		checkAccess(c, db, template.OrganID, minRole)
-	}
-}
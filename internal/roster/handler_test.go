@@ -0,0 +1,258 @@
+package roster
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type TestRosterHandlerSuite struct {
+	suite.Suite
+	db     *gorm.DB
+	router *gin.Engine
+
+	member uint
+	other  uint
+	admin  uint
+
+	roster models.Roster
+	shift  models.RosterShift
+
+	templateShift models.RosterTemplateShift
+}
+
+func (suite *TestRosterHandlerSuite) SetupTest() {
+	gin.SetMode(gin.TestMode)
+
+	db := seeder.Seeder(":memory:")
+	suite.db = db
+
+	var users []models.User
+	db.Order("id ASC").Limit(3).Find(&users)
+	suite.member, suite.other, suite.admin = users[0].ID, users[1].ID, users[2].ID
+
+	// The seeder hands out random roles, the tests need fixed ones
+	db.Model(&models.UserOrgan{}).Where("organ_id = ? AND user_id IN ?", 1, []uint{suite.member, suite.other}).
+		Update("role", models.RoleMember)
+	db.Model(&models.UserOrgan{}).Where("organ_id = ? AND user_id = ?", 1, suite.admin).
+		Update("role", models.RoleAdmin)
+
+	suite.roster = models.Roster{Name: "Handler Roster", Values: []string{"yes", "no"}, OrganID: 1}
+	db.Create(&suite.roster)
+	suite.shift = models.RosterShift{RosterID: suite.roster.ID, Name: "Opening"}
+	db.Create(&suite.shift)
+
+	template := models.RosterTemplate{OrganID: 1, Name: "Handler Template"}
+	db.Create(&template)
+	suite.templateShift = models.RosterTemplateShift{TemplateID: template.ID, ShiftName: "Opening"}
+	db.Create(&suite.templateShift)
+
+	suite.router = gin.New()
+	api := suite.router.Group("", func(c *gin.Context) {
+		var userID uint
+		_, _ = fmt.Sscan(c.GetHeader("X-User-ID"), &userID)
+		c.Set("userID", userID)
+	})
+	NewRosterHandler(&service{db: db}, authz.NewRouter(api, db, authz.NewRegistry()))
+}
+
+func (suite *TestRosterHandlerSuite) request(method string, path string, userID uint, body interface{}) *httptest.ResponseRecorder {
+	payload, _ := json.Marshal(body)
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", fmt.Sprint(userID))
+
+	w := httptest.NewRecorder()
+	suite.router.ServeHTTP(w, req)
+	return w
+}
+
+func (suite *TestRosterHandlerSuite) answerBody(userID uint) AnswerCreateRequest {
+	return AnswerCreateRequest{
+		UserID:        userID,
+		RosterID:      suite.roster.ID,
+		RosterShiftID: suite.shift.ID,
+		Value:         "yes",
+	}
+}
+
+func (suite *TestRosterHandlerSuite) TestCreateRosterAnswer_Own() {
+	w := suite.request(http.MethodPost, "/roster/answer", suite.member, suite.answerBody(suite.member))
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+}
+
+func (suite *TestRosterHandlerSuite) TestCreateRosterAnswer_OtherMemberForbidden() {
+	w := suite.request(http.MethodPost, "/roster/answer", suite.member, suite.answerBody(suite.other))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	var count int64
+	suite.db.Model(&models.RosterAnswer{}).Where("roster_id = ?", suite.roster.ID).Count(&count)
+	assert.Zero(suite.T(), count)
+}
+
+func (suite *TestRosterHandlerSuite) TestCreateRosterAnswer_AdminForOther() {
+	w := suite.request(http.MethodPost, "/roster/answer", suite.admin, suite.answerBody(suite.other))
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+}
+
+func (suite *TestRosterHandlerSuite) TestUpdateRosterAnswer_Ownership() {
+	answer := models.RosterAnswer{UserID: suite.other, RosterID: suite.roster.ID, RosterShiftID: suite.shift.ID, Value: "yes"}
+	suite.db.Create(&answer)
+	path := fmt.Sprintf("/roster/answer/%d", answer.ID)
+
+	w := suite.request(http.MethodPatch, path, suite.member, AnswerUpdateRequest{Value: "no"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.request(http.MethodPatch, path, suite.other, AnswerUpdateRequest{Value: "no"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request(http.MethodPatch, path, suite.admin, AnswerUpdateRequest{Value: "yes"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *TestRosterHandlerSuite) TestCreateShiftPreference_Ownership() {
+	body := func(userID uint) TemplateShiftPreferenceCreateRequest {
+		return TemplateShiftPreferenceCreateRequest{
+			UserID:                userID,
+			RosterTemplateShiftID: suite.templateShift.ID,
+			Preference:            "yes",
+		}
+	}
+
+	w := suite.request(http.MethodPost, "/roster/template/shift-preference", suite.member, body(suite.other))
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.request(http.MethodPost, "/roster/template/shift-preference", suite.member, body(suite.member))
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+
+	w = suite.request(http.MethodPost, "/roster/template/shift-preference", suite.admin, body(suite.other))
+	assert.Equal(suite.T(), http.StatusCreated, w.Code)
+}
+
+func (suite *TestRosterHandlerSuite) TestUpdateShiftPreference_Ownership() {
+	preference := models.RosterTemplateShiftPreference{
+		UserID:                suite.other,
+		RosterTemplateShiftID: suite.templateShift.ID,
+		Preference:            "yes",
+	}
+	suite.db.Create(&preference)
+	path := fmt.Sprintf("/roster/template/shift-preference/%d", preference.ID)
+
+	w := suite.request(http.MethodPatch, path, suite.member, TemplateShiftPreferenceUpdateRequest{Preference: "no"})
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.request(http.MethodPatch, path, suite.other, TemplateShiftPreferenceUpdateRequest{Preference: "no"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request(http.MethodPatch, path, suite.admin, TemplateShiftPreferenceUpdateRequest{Preference: "yes"})
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *TestRosterHandlerSuite) TestOtherOrganForbidden() {
+	suite.db.Where("organ_id = ? AND user_id = ?", 2, suite.member).Delete(&models.UserOrgan{})
+
+	template := models.RosterTemplate{OrganID: 2, Name: "Other Organ Template"}
+	suite.db.Create(&template)
+	group := models.ShiftGroup{OrganID: 2, Name: "Other Organ Group"}
+	suite.db.Create(&group)
+
+	paths := []string{
+		fmt.Sprintf("/roster/template/%d", template.ID),
+		"/roster/template?organId=2",
+		fmt.Sprintf("/roster/shift-groups/%d", group.ID),
+		"/roster/shift-groups?organ_id=2",
+	}
+	for _, path := range paths {
+		w := suite.request(http.MethodGet, path, suite.member, nil)
+		assert.Equal(suite.T(), http.StatusForbidden, w.Code, path)
+	}
+}
+
+func (suite *TestRosterHandlerSuite) TestRestoreRoster_FromTrash() {
+	w := suite.request(http.MethodDelete, fmt.Sprintf("/roster/%d", suite.roster.ID), suite.admin, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request(http.MethodGet, fmt.Sprintf("/roster/%d", suite.roster.ID), suite.admin, nil)
+	assert.Equal(suite.T(), http.StatusNotFound, w.Code)
+
+	w = suite.request(http.MethodPost, fmt.Sprintf("/roster/%d/restore", suite.roster.ID), suite.member, nil)
+	assert.Equal(suite.T(), http.StatusForbidden, w.Code)
+
+	w = suite.request(http.MethodPost, fmt.Sprintf("/roster/%d/restore", suite.roster.ID), suite.admin, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+
+	w = suite.request(http.MethodGet, fmt.Sprintf("/roster/%d", suite.roster.ID), suite.admin, nil)
+	assert.Equal(suite.T(), http.StatusOK, w.Code)
+}
+
+func (suite *TestRosterHandlerSuite) TestGetRoster_MembersOnlySeeOwnAnswers() {
+	suite.db.Create(&models.RosterAnswer{UserID: suite.member, RosterID: suite.roster.ID, RosterShiftID: suite.shift.ID, Value: "yes"})
+	suite.db.Create(&models.RosterAnswer{UserID: suite.other, RosterID: suite.roster.ID, RosterShiftID: suite.shift.ID, Value: "no"})
+
+	answers := func(userID uint) []models.RosterAnswer {
+		w := suite.request(http.MethodGet, fmt.Sprintf("/roster/%d", suite.roster.ID), userID, nil)
+		suite.Require().Equal(http.StatusOK, w.Code)
+
+		var rosters []models.Roster
+		suite.Require().NoError(json.Unmarshal(w.Body.Bytes(), &rosters))
+		suite.Require().Len(rosters, 1)
+		return rosters[0].RosterAnswer
+	}
+
+	// Publishing shows the schedule, not the answers of other members
+	for _, published := range []bool{false, true} {
+		suite.db.Model(&suite.roster).Update("published", published)
+
+		own := answers(suite.member)
+		assert.Len(suite.T(), own, 1, "published: %v", published)
+		assert.Equal(suite.T(), suite.member, own[0].UserID)
+
+		assert.Len(suite.T(), answers(suite.admin), 2, "published: %v", published)
+	}
+}
+
+func (suite *TestRosterHandlerSuite) TestChildrenOfTrashedParents_NotFound() {
+	answer := models.RosterAnswer{UserID: suite.other, RosterID: suite.roster.ID, RosterShiftID: suite.shift.ID, Value: "yes"}
+	suite.db.Create(&answer)
+	preference := models.RosterTemplateShiftPreference{
+		UserID:                suite.other,
+		RosterTemplateShiftID: suite.templateShift.ID,
+		Preference:            "yes",
+	}
+	suite.db.Create(&preference)
+
+	w := suite.request(http.MethodDelete, fmt.Sprintf("/roster/%d", suite.roster.ID), suite.admin, nil)
+	suite.Require().Equal(http.StatusOK, w.Code)
+	w = suite.request(http.MethodDelete, fmt.Sprintf("/roster/template/%d", suite.templateShift.TemplateID), suite.admin, nil)
+	suite.Require().Equal(http.StatusOK, w.Code)
+
+	requests := []struct {
+		method string
+		path   string
+		body   interface{}
+	}{
+		{http.MethodPatch, fmt.Sprintf("/roster/answer/%d", answer.ID), AnswerUpdateRequest{Value: "no"}},
+		{http.MethodDelete, fmt.Sprintf("/roster/shift/%d", suite.shift.ID), nil},
+		{http.MethodPost, "/roster/answer", suite.answerBody(suite.other)},
+		{http.MethodPatch, fmt.Sprintf("/roster/template/shift/%d", suite.templateShift.ID), nil},
+		{http.MethodPatch, fmt.Sprintf("/roster/template/shift-preference/%d", preference.ID), TemplateShiftPreferenceUpdateRequest{Preference: "no"}},
+	}
+	for _, r := range requests {
+		w := suite.request(r.method, r.path, suite.admin, r.body)
+		assert.Equal(suite.T(), http.StatusNotFound, w.Code, "%s %s", r.method, r.path)
+	}
+}
+
+func TestRosterHandler(t *testing.T) {
+	suite.Run(t, new(TestRosterHandlerSuite))
+}
@@ -0,0 +1,118 @@
+package roster
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+func (h *Handler) registerTrashRoutes(g *authz.Router) {
+	g.GET("/trash", authz.Require(authz.OrganQuery("organId"), models.PermRosterCreate), h.GetTrash)
+	g.POST("/:id/restore", authz.Require(trashedParam(&models.Roster{}, "id"), models.PermRosterCreate), h.RestoreRoster)
+	g.POST("/template/:id/restore", authz.Require(trashedParam(&models.RosterTemplate{}, "id"), models.PermTemplateEdit), h.RestoreRosterTemplate)
+}
+
+// GetTrash
+//
+//	@Summary		List the trash of an organ
+//	@Security		BearerAuth
+//	@Description	Lists the deleted rosters and templates of the organ that have not been purged yet. Templates are only listed for users with template.edit.
+//	@Tags			Roster
+//	@Produce		json
+//	@Param			organId	query		uint	true	"Organ ID"
+//	@Success		200		{object}	RosterTrashResponse
+//	@Failure		400		{string}	string
+//	@ID				getRosterTrash
+//	@Router			/roster/trash [get]
+func (h *Handler) GetTrash(c *gin.Context) {
+	organID, err := strconv.ParseUint(c.Query("organId"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid organ ID"})
+		return
+	}
+
+	trash, err := h.rosterService.GetTrash(uint(organID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !hasPermission(c, models.PermTemplateEdit) {
+		trash.Templates = []*models.RosterTemplate{}
+	}
+
+	c.JSON(http.StatusOK, trash)
+}
+
+// RestoreRoster
+//
+//	@Summary		Restore a roster from the trash
+//	@Security		BearerAuth
+//	@Description	Restores a deleted roster with its shifts, answers and saved shifts
+//	@Tags			Roster
+//	@Produce		json
+//	@Param			id	path		uint	true	"Roster ID"
+//	@Success		200	{object}	models.Roster
+//	@Failure		400	{string}	string
+//	@Failure		404	{string}	string
+//	@ID				restoreRoster
+//	@Router			/roster/{id}/restore [post]
+func (h *Handler) RestoreRoster(c *gin.Context) {
+	rosterID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid roster ID"})
+		return
+	}
+
+	roster, err := h.rosterService.RestoreRoster(uint(rosterID))
+	if err != nil {
+		writeTrashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, roster)
+}
+
+// RestoreRosterTemplate
+//
+//	@Summary		Restore a roster template from the trash
+//	@Security		BearerAuth
+//	@Description	Restores a deleted template with its shifts and preferences
+//	@Tags			Roster
+//	@Produce		json
+//	@Param			id	path		uint	true	"Template ID"
+//	@Success		200	{object}	models.RosterTemplate
+//	@Failure		400	{string}	string
+//	@Failure		404	{string}	string
+//	@ID				restoreRosterTemplate
+//	@Router			/roster/template/{id}/restore [post]
+func (h *Handler) RestoreRosterTemplate(c *gin.Context) {
+	templateID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	template, err := h.rosterService.RestoreRosterTemplate(uint(templateID))
+	if err != nil {
+		writeTrashError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, template)
+}
+
+func writeTrashError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Could not find the item, it may have been purged"})
+	case errors.Is(err, ErrNotInTrash):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
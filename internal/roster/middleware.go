@@ -2,29 +2,234 @@ package roster
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/platform/authz"
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
-	"net/http"
 )
 
-func checkAccess(c *gin.Context, db *gorm.DB, organID interface{}, minRole models.OrganRole) {
-	userID, exists := c.Get("userID")
-	if !exists {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
-		return
+// authenticatedUserID returns the ID of the user set by the auth middleware.
+func authenticatedUserID(c *gin.Context) (uint, bool) {
+	return authz.UserID(c)
+}
+
+// hasPermission reports whether the role stored by the route policy grants permission.
+func hasPermission(c *gin.Context, permission models.Permission) bool {
+	return authz.HasPermission(c, permission)
+}
+
+// rosterParam resolves the organ of the roster in a path parameter.
+func rosterParam(param string) authz.Resolver {
+	return authz.ModelParam(&models.Roster{}, param)
+}
+
+// rosterBody resolves the organ of the roster referenced by the rosterId field
+// in the body.
+func rosterBody() authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		rosterID, err := authz.BodyID(c, "rosterId")
+		if err != nil {
+			return nil, err
+		}
+		return rosterResource(db, rosterID)
+	}
+}
+
+// rosterShiftParam resolves the organ of the roster shift in a path parameter.
+func rosterShiftParam(param string) authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		shiftID, err := authz.ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
+
+		var shift models.RosterShift
+		if err := db.First(&shift, shiftID).Error; err != nil {
+			return nil, err
+		}
+		return rosterResource(db, shift.RosterID)
+	}
+}
+
+// answerParam resolves the roster answer in a path parameter, owned by the
+// member that gave it.
+func answerParam(param string) authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		answerID, err := authz.ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
+
+		var answer models.RosterAnswer
+		if err := db.First(&answer, answerID).Error; err != nil {
+			return nil, err
+		}
+
+		resource, err := rosterResource(db, answer.RosterID)
+		if err != nil {
+			return nil, err
+		}
+		resource.OwnerID = &answer.UserID
+		return resource, nil
+	}
+}
+
+// answerBody resolves a new roster answer from the rosterId and userId fields
+// in the body.
+func answerBody() authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		resource, err := rosterBody()(c, db)
+		if err != nil {
+			return nil, err
+		}
+
+		userID, err := authz.BodyID(c, "userId")
+		if err != nil {
+			return nil, err
+		}
+		resource.OwnerID = &userID
+		return resource, nil
+	}
+}
+
+// savedShiftParam resolves the organ of the saved shift in a path parameter.
+func savedShiftParam(param string) authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		savedShiftID, err := authz.ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
+
+		var saved models.SavedShift
+		if err := db.First(&saved, savedShiftID).Error; err != nil {
+			return nil, err
+		}
+		return rosterResource(db, saved.RosterID)
+	}
+}
+
+// templateParam resolves the organ of the roster template in a path parameter.
+func templateParam(param string) authz.Resolver {
+	return authz.ModelParam(&models.RosterTemplate{}, param)
+}
+
+// trashedParam resolves the organ of a soft deleted model in a path parameter,
+// which the other resolvers do not find.
+func trashedParam(model interface{}, param string) authz.Resolver {
+	resolve := authz.ModelParam(model, param)
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		return resolve(c, db.Unscoped())
+	}
+}
+
+// templateShiftParam resolves the organ of the template shift in a path parameter.
+func templateShiftParam(param string) authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		shiftID, err := authz.ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
+		return templateShiftResource(db, shiftID)
+	}
+}
+
+// preferenceBody resolves a new shift preference from the rosterTemplateShiftID
+// and userId fields in the body.
+func preferenceBody() authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		shiftID, err := authz.BodyID(c, "rosterTemplateShiftID")
+		if err != nil {
+			return nil, err
+		}
+
+		userID, err := authz.BodyID(c, "userId")
+		if err != nil {
+			return nil, err
+		}
+
+		resource, err := templateShiftResource(db, shiftID)
+		if err != nil {
+			return nil, err
+		}
+		resource.OwnerID = &userID
+		return resource, nil
+	}
+}
+
+// preferenceQuery resolves the shift preferences of the user and template in
+// the userId and templateId query parameters.
+func preferenceQuery() authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		templateID, err := authz.ParseID(c.Query("templateId"), "templateId")
+		if err != nil {
+			return nil, err
+		}
+
+		userID, err := authz.ParseID(c.Query("userId"), "userId")
+		if err != nil {
+			return nil, err
+		}
+
+		resource, err := templateResource(db, templateID)
+		if err != nil {
+			return nil, err
+		}
+		resource.OwnerID = &userID
+		return resource, nil
 	}
+}
+
+// preferenceParam resolves the shift preference in a path parameter, owned by
+// the member that stated it.
+func preferenceParam(param string) authz.Resolver {
+	return func(c *gin.Context, db *gorm.DB) (*authz.Resource, error) {
+		preferenceID, err := authz.ParseID(c.Param(param), param)
+		if err != nil {
+			return nil, err
+		}
 
-	var userOrgan models.UserOrgan
+		var preference models.RosterTemplateShiftPreference
+		if err := db.First(&preference, preferenceID).Error; err != nil {
+			return nil, err
+		}
 
-	if err := db.Where("user_id = ? AND organ_id = ?", userID, organID).First(&userOrgan).Error; err != nil {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "You are not a member of this organ"})
-		return
+		resource, err := templateShiftResource(db, preference.RosterTemplateShiftID)
+		if err != nil {
+			return nil, err
+		}
+		resource.OwnerID = &preference.UserID
+		return resource, nil
 	}
+}
+
+// shiftGroupParam resolves the organ of the shift group in a path parameter.
+func shiftGroupParam(param string) authz.Resolver {
+	return authz.ModelParam(&models.ShiftGroup{}, param)
+}
 
-	if models.RoleWeights[userOrgan.Role] < models.RoleWeights[minRole] {
-		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "Insufficient organ permissions"})
-		return
+// The resources below look up the parent explicitly rather than preloading it.
+// A preload skips a parent in the trash and leaves it nil, while First reports
+// it as not found.
+
+func rosterResource(db *gorm.DB, rosterID uint) (*authz.Resource, error) {
+	var roster models.Roster
+	if err := db.First(&roster, rosterID).Error; err != nil {
+		return nil, err
+	}
+	return &authz.Resource{OrganID: roster.OrganID}, nil
+}
+
+func templateResource(db *gorm.DB, templateID uint) (*authz.Resource, error) {
+	var template models.RosterTemplate
+	if err := db.First(&template, templateID).Error; err != nil {
+		return nil, err
 	}
+	return &authz.Resource{OrganID: template.OrganID}, nil
+}
 
-	c.Next()
+func templateShiftResource(db *gorm.DB, shiftID uint) (*authz.Resource, error) {
+	var shift models.RosterTemplateShift
+	if err := db.First(&shift, shiftID).Error; err != nil {
+		return nil, err
+	}
+	return templateResource(db, shift.TemplateID)
 }
@@ -14,12 +14,15 @@ type Service interface {
 	RosterManager
 	ShiftManager
 	TemplateManager
+	ClaimManager
+	TrashManager
 
-	FillRosterPreferences(uint) ([]*models.RosterAnswer, error)
+	FillRosterPreferences(rosterID uint, actorID uint) ([]*models.RosterAnswer, error)
 
 	SaveRoster(uint) error
 	UpdateSavedShift(uint, *SavedShiftUpdateRequest) (*models.SavedShift, error)
 	GetSavedRoster(uint) ([]*models.SavedShift, []*models.SavedShiftOrdering, error)
+	GetRosterSchedule(uint) (*ScheduleResponse, error)
 
 	CreateShiftGroup(ShiftGroupCreateRequest) (*models.ShiftGroup, error)
 	GetShiftGroups(ShiftGroupFilterParams) (*[]models.ShiftGroup, error)
@@ -33,16 +36,21 @@ type UserProvider interface {
 	Get(*user.FilterParams) ([]*models.User, error)
 }
 
+type Notifier interface {
+	Notify(*models.Notification) error
+}
+
 type service struct {
 	db *gorm.DB
 	u  UserProvider
+	n  Notifier
 }
 
-func NewRosterService(db *gorm.DB, userService UserProvider) Service {
-	return &service{db: db, u: userService}
+func NewRosterService(db *gorm.DB, userService UserProvider, notifier Notifier) Service {
+	return &service{db: db, u: userService, n: notifier}
 }
 
-func (s *service) FillRosterPreferences(rosterID uint) ([]*models.RosterAnswer, error) {
+func (s *service) FillRosterPreferences(rosterID uint, actorID uint) ([]*models.RosterAnswer, error) {
 	filter := &FilterParams{
 		ID: &rosterID,
 	}
@@ -63,8 +71,10 @@ func (s *service) FillRosterPreferences(rosterID uint) ([]*models.RosterAnswer,
 		return nil, errors.New("roster shift has no linked template")
 	}
 
+	active := models.MembershipActive
 	userFilter := user.FilterParams{
 		OrganID: &toFillRoster.OrganID,
+		Status:  &active,
 	}
 	users, err := s.u.Get(&userFilter)
 
@@ -113,7 +123,14 @@ func (s *service) FillRosterPreferences(rosterID uint) ([]*models.RosterAnswer,
 					Value:         pref.Preference,
 				}
 
-				if err := s.db.Create(&answer).Error; err != nil {
+				err := s.db.Transaction(func(tx *gorm.DB) error {
+					if err := tx.Create(&answer).Error; err != nil {
+						return err
+					}
+
+					return recordAnswerChange(tx, &answer, nil, &actorID, models.SourceTemplate)
+				})
+				if err != nil {
 					return nil, err
 				}
 				newAnswers[prefID] = &answer
@@ -137,7 +154,7 @@ func (s *service) SaveRoster(ID uint) error {
 		err := s.db.Where("roster_id = ? AND roster_shift_id = ?", roster.ID, shift.ID).First(&existing).Error
 		if err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
-				if err := s.createSavedShift(roster.ID, &shift); err != nil {
+				if err := s.createSavedShift(roster, &shift); err != nil {
 					return err
 				}
 			} else {
@@ -156,7 +173,12 @@ func (s *service) SaveRoster(ID uint) error {
 
 func (s *service) GetSavedRoster(ID uint) ([]*models.SavedShift, []*models.SavedShiftOrdering, error) {
 	var savedShifts []*models.SavedShift
-	if err := s.db.Preload(clause.Associations).Where("roster_id = ?", ID).Find(&savedShifts).Error; err != nil {
+	err := s.db.Preload(clause.Associations).
+		Preload("Waitlist", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Waitlist.User").
+		Where("roster_id = ?", ID).
+		Find(&savedShifts).Error
+	if err != nil {
 		return nil, nil, err
 	}
 
@@ -169,29 +191,113 @@ func (s *service) GetSavedRoster(ID uint) ([]*models.SavedShift, []*models.Saved
 	return savedShifts, savedShiftOrdering, nil
 }
 
-func (s *service) UpdateSavedShift(ID uint, updateParams *SavedShiftUpdateRequest) (*models.SavedShift, error) {
-	var saved *models.SavedShift
-	if err := s.db.Preload("Users").First(&saved, ID).Error; err != nil {
+// GetRosterSchedule builds the read-only "who works when" view of a saved
+// roster, with the shifts in roster order.
+func (s *service) GetRosterSchedule(ID uint) (*ScheduleResponse, error) {
+	var roster models.Roster
+	if err := s.db.First(&roster, ID).Error; err != nil {
 		return nil, err
 	}
 
-	if updateParams.UserIDs != nil {
-		var users []*models.User
-		if err := s.db.Where("ID IN ?", updateParams.UserIDs).Find(&users).Error; err != nil {
+	var savedShifts []*models.SavedShift
+	err := s.db.Preload("RosterShift").
+		Preload("Users").
+		Joins("JOIN roster_shifts ON roster_shifts.id = saved_shifts.roster_shift_id").
+		Where("saved_shifts.roster_id = ?", ID).
+		Order("roster_shifts.`order` ASC").
+		Find(&savedShifts).Error
+	if err != nil {
+		return nil, err
+	}
 
-			return nil, err
+	var memberships []models.UserOrgan
+	if err := s.db.Where("organ_id = ?", roster.OrganID).Find(&memberships).Error; err != nil {
+		return nil, err
+	}
+
+	usernames := make(map[uint]string, len(memberships))
+	for _, membership := range memberships {
+		usernames[membership.UserID] = membership.Username
+	}
+
+	schedule := &ScheduleResponse{
+		RosterID:  roster.ID,
+		Name:      roster.Name,
+		Date:      roster.Date,
+		OrganID:   roster.OrganID,
+		Published: roster.Published,
+		Shifts:    make([]*ScheduleShift, 0, len(savedShifts)),
+	}
+
+	for _, savedShift := range savedShifts {
+		shift := &ScheduleShift{
+			Users: make([]*ScheduleUser, 0, len(savedShift.Users)),
 		}
-		// Replace existing users with the new set
-		if err := s.db.Model(&saved).Association("Users").Replace(users); err != nil {
-			return nil, err
+		if savedShift.RosterShift != nil {
+			shift.ShiftName = savedShift.RosterShift.Name
 		}
-		// Reload associations to get fresh data
-		if err := s.db.Preload("Users").Preload("RosterShift").First(&saved, ID).Error; err != nil {
-			return nil, err
+
+		for _, u := range savedShift.Users {
+			shift.Users = append(shift.Users, &ScheduleUser{
+				ID:       u.ID,
+				Name:     u.Name,
+				Username: usernames[u.ID],
+				Guest:    u.Guest,
+			})
 		}
+
+		schedule.Shifts = append(schedule.Shifts, shift)
 	}
 
-	return saved, nil
+	return schedule, nil
+}
+
+// UpdateSavedShift replaces the users or capacity of a saved shift. It locks
+// the shift like a claim does, so the waitlist stays consistent with the
+// users: assigned users leave the waitlist and open slots are handed out.
+func (s *service) UpdateSavedShift(ID uint, updateParams *SavedShiftUpdateRequest) (*models.SavedShift, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		saved, _, err := lockSavedShift(tx, ID)
+		if err != nil {
+			return err
+		}
+
+		if updateParams.UserIDs != nil {
+			var users []*models.User
+			if err := tx.Where("id IN ?", updateParams.UserIDs).Find(&users).Error; err != nil {
+				return err
+			}
+			// Replace existing users with the new set
+			if err := tx.Model(saved).Association("Users").Replace(users); err != nil {
+				return err
+			}
+
+			if len(users) > 0 {
+				err := tx.Where("saved_shift_id = ? AND user_id IN ?", saved.ID, updateParams.UserIDs).
+					Delete(&models.SavedShiftWaitlistEntry{}).Error
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if updateParams.Capacity != nil {
+			if err := tx.Model(saved).Update("capacity", *updateParams.Capacity).Error; err != nil {
+				return err
+			}
+		}
+
+		if updateParams.UserIDs == nil && updateParams.Capacity == nil {
+			return nil
+		}
+		// Removed users and a raised capacity free up slots for the waitlist
+		return promoteFromWaitlist(tx, saved.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getSavedShift(ID)
 }
 
 func (s *service) CreateShiftGroup(params ShiftGroupCreateRequest) (*models.ShiftGroup, error) {
@@ -265,11 +371,14 @@ func (s *service) UpdateShiftGroupPriority(groupID uint, params GroupPriorityUpd
 	return &newRecord, nil
 }
 
-func (s *service) createSavedShift(rID uint, shift *models.RosterShift) error {
+// createSavedShift saves the shift with the capacity of the roster, so shifts
+// of a self sign-up roster can be claimed right away.
+func (s *service) createSavedShift(roster *models.Roster, shift *models.RosterShift) error {
 	var savedShift = models.SavedShift{
-		RosterID:    rID,
+		RosterID:    roster.ID,
 		RosterShift: shift,
 		Users:       []*models.User{},
+		Capacity:    roster.ShiftCapacity,
 	}
 
 	if err := s.db.Create(&savedShift).Error; err != nil {
@@ -292,13 +401,19 @@ func (s *service) getSavedShiftOrdering(savedShifts []*models.SavedShift) ([]*mo
 			return nil, err
 		}
 
+		settings, err := models.LoadOrganSettings(s.db, organID)
+		if err != nil {
+			return nil, err
+		}
+
 		// Get the latest shift from users to check when they were last assigned
 		// It first checks by groups and if no group is assigned it checks on name
-		err := s.db.Table("users AS u").
+		err = s.db.Table("users AS u").
 			Select(`
 				u.*, 
 				MAX(r.date) AS last_date, 
-				COALESCE(MAX(sgp.priority), 1) AS group_priority
+				COALESCE(MAX(sgp.priority), 1) AS group_priority,
+				COUNT(DISTINCT CASE WHEN r.id IS NOT NULL THEN ss.id END) AS shift_count
     		`).
 			Joins("JOIN user_organs AS uo ON u.id = uo.user_id").
 			Joins("JOIN roster_shifts AS target_rs ON target_rs.name = ?", savedShift.RosterShift.Name).
@@ -311,10 +426,11 @@ func (s *service) getSavedShiftOrdering(savedShifts []*models.SavedShift) ([]*mo
 			)`).
 			Joins("LEFT JOIN user_shift_saved AS uss ON uss.user_id = u.id").
 			Joins("LEFT JOIN saved_shifts AS ss ON ss.roster_shift_id = rs.id AND ss.id = uss.saved_shift_id").
-			Joins("LEFT JOIN rosters AS r ON r.id = ss.roster_id").
-			Where("uo.organ_id = ?", organID).
+			// Shifts of rosters in the trash do not count
+			Joins("LEFT JOIN rosters AS r ON r.id = ss.roster_id AND r.deleted_at IS NULL").
+			Where("uo.organ_id = ? AND uo.status = ?", organID, models.MembershipActive).
 			Group("u.id").
-			Order("group_priority DESC, last_date ASC").
+			Order(orderingClause(settings.OrderingRule)).
 			Scan(&users).Error
 
 		if err != nil {
@@ -330,11 +446,27 @@ func (s *service) getSavedShiftOrdering(savedShifts []*models.SavedShift) ([]*mo
 	return orderings, nil
 }
 
-func isTodayOrLater(date time.Time) bool {
-	now := time.Now().In(date.Location())
+// orderingClause returns the ORDER BY of the saved shift ordering for the rule
+// of the organ.
+func orderingClause(rule models.OrderingRule) string {
+	switch rule {
+	case models.OrderingLeastRecent:
+		return "last_date ASC"
+	case models.OrderingFewestShifts:
+		return "shift_count ASC, last_date ASC"
+	default:
+		return "group_priority DESC, last_date ASC"
+	}
+}
+
+// isTodayOrLater reports whether the date falls on today or later, with both
+// days taken in the timezone of the organ.
+func isTodayOrLater(date time.Time, location *time.Location) bool {
+	now := time.Now().In(location)
+	date = date.In(location)
 
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, date.Location())
-	inputDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+	inputDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, location)
 
 	return !inputDate.Before(today)
 }
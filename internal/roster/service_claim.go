@@ -0,0 +1,197 @@
+package roster
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"gorm.io/gorm"
+	"slices"
+	"time"
+)
+
+var (
+	ErrNotSelfSignup  = errors.New("roster does not allow self sign-up")
+	ErrShiftFull      = errors.New("shift has no open capacity")
+	ErrAlreadyClaimed = errors.New("shift is already claimed or waitlisted by this user")
+	ErrNotClaimed     = errors.New("shift is not claimed by this user")
+)
+
+type ClaimManager interface {
+	ClaimSavedShift(savedShiftID uint, userID uint) (*ClaimResponse, error)
+	ReleaseSavedShift(savedShiftID uint, userID uint) (*models.SavedShift, error)
+}
+
+// ClaimSavedShift lets a member take an open slot on a saved shift of a self
+// sign-up roster. When the shift is full and the roster has a waitlist, the
+// member is put on the waitlist instead.
+func (s *service) ClaimSavedShift(savedShiftID uint, userID uint) (*ClaimResponse, error) {
+	response := &ClaimResponse{}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		saved, roster, err := lockSavedShift(tx, savedShiftID)
+		if err != nil {
+			return err
+		}
+
+		if roster.Mode != models.ModeSelfSignup {
+			return ErrNotSelfSignup
+		}
+
+		if hasUser(saved.Users, userID) || hasWaitlistEntry(saved.Waitlist, userID) {
+			return ErrAlreadyClaimed
+		}
+
+		if uint(len(saved.Users)) < saved.Capacity {
+			var claimant models.User
+			if err := tx.First(&claimant, userID).Error; err != nil {
+				return err
+			}
+
+			return tx.Model(saved).Association("Users").Append(&claimant)
+		}
+
+		if !roster.WaitlistEnabled {
+			return ErrShiftFull
+		}
+
+		entry := models.SavedShiftWaitlistEntry{
+			SavedShiftID: saved.ID,
+			UserID:       userID,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+
+		response.Waitlisted = true
+		response.Position = len(saved.Waitlist) + 1
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	saved, err := s.getSavedShift(savedShiftID)
+	if err != nil {
+		return nil, err
+	}
+	response.SavedShift = saved
+
+	return response, nil
+}
+
+// ReleaseSavedShift removes a member from a saved shift or its waitlist. A slot
+// that opens up is handed to the first member on the waitlist.
+func (s *service) ReleaseSavedShift(savedShiftID uint, userID uint) (*models.SavedShift, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		saved, roster, err := lockSavedShift(tx, savedShiftID)
+		if err != nil {
+			return err
+		}
+
+		if roster.Mode != models.ModeSelfSignup {
+			return ErrNotSelfSignup
+		}
+
+		if hasWaitlistEntry(saved.Waitlist, userID) {
+			return tx.Where("saved_shift_id = ? AND user_id = ?", saved.ID, userID).
+				Delete(&models.SavedShiftWaitlistEntry{}).Error
+		}
+
+		if !hasUser(saved.Users, userID) {
+			return ErrNotClaimed
+		}
+
+		if err := tx.Model(saved).Association("Users").Delete(&models.User{BaseModel: models.BaseModel{ID: userID}}); err != nil {
+			return err
+		}
+
+		return promoteFromWaitlist(tx, saved.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.getSavedShift(savedShiftID)
+}
+
+// lockSavedShift touches the saved shift before reading it. The write takes a
+// row lock (or the database lock on SQLite) that is held until the transaction
+// ends, so concurrent claims on the same shift are handled one at a time and
+// the capacity check cannot race.
+func lockSavedShift(tx *gorm.DB, savedShiftID uint) (*models.SavedShift, *models.Roster, error) {
+	result := tx.Model(&models.SavedShift{}).Where("id = ?", savedShiftID).Update("updated_at", time.Now())
+	if result.Error != nil {
+		return nil, nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil, gorm.ErrRecordNotFound
+	}
+
+	var saved models.SavedShift
+	err := tx.Preload("Users").
+		Preload("Waitlist", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&saved, savedShiftID).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var roster models.Roster
+	if err := tx.First(&roster, saved.RosterID).Error; err != nil {
+		return nil, nil, err
+	}
+
+	return &saved, &roster, nil
+}
+
+// promoteFromWaitlist moves waiting members onto the saved shift until it is
+// full or the waitlist is empty. It must run inside the transaction that
+// locked the saved shift.
+func promoteFromWaitlist(tx *gorm.DB, savedShiftID uint) error {
+	var saved models.SavedShift
+	err := tx.Preload("Users").
+		Preload("Waitlist", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Waitlist.User").
+		First(&saved, savedShiftID).Error
+	if err != nil {
+		return err
+	}
+
+	open := int(saved.Capacity) - len(saved.Users)
+	for _, entry := range saved.Waitlist {
+		if open <= 0 {
+			break
+		}
+
+		if err := tx.Model(&saved).Association("Users").Append(entry.User); err != nil {
+			return err
+		}
+		if err := tx.Delete(entry).Error; err != nil {
+			return err
+		}
+		open--
+	}
+
+	return nil
+}
+
+func (s *service) getSavedShift(ID uint) (*models.SavedShift, error) {
+	var saved models.SavedShift
+	err := s.db.Preload("Users").
+		Preload("RosterShift").
+		Preload("Waitlist", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Waitlist.User").
+		First(&saved, ID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &saved, nil
+}
+
+func hasUser(users []*models.User, userID uint) bool {
+	return slices.ContainsFunc(users, func(u *models.User) bool { return u.ID == userID })
+}
+
+func hasWaitlistEntry(entries []*models.SavedShiftWaitlistEntry, userID uint) bool {
+	return slices.ContainsFunc(entries, func(e *models.SavedShiftWaitlistEntry) bool { return e.UserID == userID })
+}
@@ -2,7 +2,10 @@ package roster
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"cmp"
 	"errors"
+	"fmt"
+	"slices"
 	"time"
 )
 
@@ -11,33 +14,63 @@ type RosterManager interface {
 	GetRosters(*FilterParams) ([]*models.Roster, error)
 	UpdateRoster(uint, *UpdateRequest) (*models.Roster, error)
 	DeleteRoster(ID uint) error
+	GetRosterSummary(ID uint) (*SummaryResponse, error)
 }
 
 func (s *service) CreateRoster(params *CreateRequest) (*models.Roster, error) {
 	var users []models.User
-	var values = models.Values{"J", "X", "L", "N"} //TODO Change this to input values
 
-	err := s.db.Joins("JOIN user_organs ON user_organs.user_id = users.id").
+	settings, err := models.LoadOrganSettings(s.db, params.OrganID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.db.Joins("JOIN user_organs ON user_organs.user_id = users.id").
 		Where("user_organs.organ_id = ?", params.OrganID).
+		Scopes(models.ActiveMembers).
 		Find(&users).Error
 
 	if err != nil {
 		return nil, err
 	}
 
-	if !isTodayOrLater(params.Date) {
+	if !isTodayOrLater(params.Date, settings.Location()) {
 		return nil, errors.New("date must be after the current date")
 	}
 	if params.Name == "" {
 		return nil, errors.New("name is required")
 	}
 
+	mode := params.Mode
+	if mode == "" {
+		mode = models.ModeAssigned
+	}
+	if !isValidRosterMode(mode) {
+		return nil, fmt.Errorf("%s is not a valid roster mode", mode)
+	}
+
+	shiftCapacity := params.ShiftCapacity
+	if shiftCapacity == 0 {
+		shiftCapacity = 1
+	}
+
+	templateID := params.TemplateID
+	if templateID == nil && settings.DefaultTemplateID != nil {
+		// A default template in the trash is not used until it is restored
+		if s.db.Select("id").First(&models.RosterTemplate{}, *settings.DefaultTemplateID).Error == nil {
+			templateID = settings.DefaultTemplateID
+		}
+	}
+
 	roster := models.Roster{
-		Name:       params.Name,
-		Date:       params.Date,
-		OrganID:    params.OrganID,
-		Values:     values,
-		TemplateID: params.TemplateID,
+		Name:            params.Name,
+		Date:            params.Date,
+		OrganID:         params.OrganID,
+		Values:          settings.AnswerValues,
+		TemplateID:      templateID,
+		Mode:            mode,
+		WaitlistEnabled: params.WaitlistEnabled,
+		ShiftCapacity:   shiftCapacity,
 	}
 
 	if err := s.db.Create(&roster).Error; err != nil {
@@ -45,18 +78,26 @@ func (s *service) CreateRoster(params *CreateRequest) (*models.Roster, error) {
 	}
 
 	groupMapping := make(map[string]*uint)
+	shifts := params.Shifts
 
-	if params.TemplateID != nil {
+	if templateID != nil {
 		var templateShifts []models.RosterTemplateShift
-		s.db.Where("template_id = ?", params.TemplateID).Find(&templateShifts)
+		s.db.Where("template_id = ?", templateID).Order("id ASC").Find(&templateShifts)
 
 		for _, ts := range templateShifts {
 			groupMapping[ts.ShiftName] = ts.ShiftGroupID
 		}
+
+		// Without shifts of its own the roster gets the shifts of the template
+		if len(shifts) == 0 {
+			for _, ts := range templateShifts {
+				shifts = append(shifts, ts.ShiftName)
+			}
+		}
 	}
 
-	if params.Shifts != nil && len(params.Shifts) > 0 {
-		for index, shift := range params.Shifts {
+	if len(shifts) > 0 {
+		for index, shift := range shifts {
 			var groupID *uint
 			if gID, ok := groupMapping[shift]; ok {
 				groupID = gID
@@ -105,7 +146,8 @@ func (s *service) GetRosters(params *FilterParams) ([]*models.Roster, error) {
 	db.
 		Preload("RosterShift").
 		Preload("Organ").
-		Preload("RosterAnswer")
+		Preload("RosterAnswer").
+		Preload("RosterAnswer.EnteredBy")
 
 	if err := db.Find(&rosters).Error; err != nil {
 		return nil, err
@@ -121,8 +163,14 @@ func (s *service) UpdateRoster(id uint, params *UpdateRequest) (*models.Roster,
 		return nil, err
 	}
 
-	if params.Date != nil && !isTodayOrLater(*params.Date) {
-		return nil, errors.New("date must be after the current date")
+	if params.Date != nil {
+		settings, err := models.LoadOrganSettings(s.db, roster.OrganID)
+		if err != nil {
+			return nil, err
+		}
+		if !isTodayOrLater(*params.Date, settings.Location()) {
+			return nil, errors.New("date must be after the current date")
+		}
 	}
 
 	if params.Date != nil {
@@ -134,6 +182,24 @@ func (s *service) UpdateRoster(id uint, params *UpdateRequest) (*models.Roster,
 	if params.Saved != nil {
 		roster.Saved = *params.Saved
 	}
+	if params.Mode != nil {
+		if !isValidRosterMode(*params.Mode) {
+			return nil, fmt.Errorf("%s is not a valid roster mode", *params.Mode)
+		}
+		roster.Mode = *params.Mode
+	}
+	if params.WaitlistEnabled != nil {
+		roster.WaitlistEnabled = *params.WaitlistEnabled
+	}
+	if params.ShiftCapacity != nil {
+		if *params.ShiftCapacity == 0 {
+			return nil, errors.New("shift capacity must be at least 1")
+		}
+		roster.ShiftCapacity = *params.ShiftCapacity
+	}
+	if params.Published != nil {
+		roster.Published = *params.Published
+	}
 
 	if err := s.db.Save(&roster).Error; err != nil {
 		return nil, err
@@ -150,3 +216,93 @@ func (s *service) DeleteRoster(ID uint) error {
 
 	return nil
 }
+
+func isValidRosterMode(mode models.RosterMode) bool {
+	return mode == models.ModeAssigned || mode == models.ModeSelfSignup
+}
+
+// GetRosterSummary counts the answers per shift and lists the organ members
+// that have not (fully) answered the roster yet.
+func (s *service) GetRosterSummary(ID uint) (*SummaryResponse, error) {
+	var roster models.Roster
+	if err := s.db.Preload("RosterShift").Preload("RosterAnswer").First(&roster, ID).Error; err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(roster.RosterShift, func(a, b models.RosterShift) int {
+		return cmp.Compare(a.Order, b.Order)
+	})
+
+	summary := &SummaryResponse{
+		RosterID:          roster.ID,
+		Shifts:            make([]*ShiftAnswerCount, 0, len(roster.RosterShift)),
+		NotAnswered:       []*MemberAnswerStatus{},
+		PartiallyAnswered: []*MemberAnswerStatus{},
+	}
+
+	shiftCounts := make(map[uint]*ShiftAnswerCount)
+	for _, shift := range roster.RosterShift {
+		counts := make(map[string]int, len(roster.Values))
+		for _, value := range roster.Values {
+			counts[value] = 0
+		}
+
+		shiftCount := &ShiftAnswerCount{
+			RosterShiftID: shift.ID,
+			ShiftName:     shift.Name,
+			Counts:        counts,
+		}
+		shiftCounts[shift.ID] = shiftCount
+		summary.Shifts = append(summary.Shifts, shiftCount)
+	}
+
+	answeredShifts := make(map[uint]int)
+	for _, answer := range roster.RosterAnswer {
+		shiftCount, ok := shiftCounts[answer.RosterShiftID]
+		if !ok {
+			continue
+		}
+
+		shiftCount.Counts[answer.Value]++
+		answeredShifts[answer.UserID]++
+	}
+
+	if len(roster.RosterShift) == 0 {
+		return summary, nil
+	}
+
+	var members []struct {
+		UserID   uint
+		Name     string
+		Username string
+	}
+	err := s.db.Table("user_organs").
+		Select("user_organs.user_id, users.name, user_organs.username").
+		Joins("JOIN users ON users.id = user_organs.user_id").
+		Where("user_organs.organ_id = ?", roster.OrganID).
+		Scopes(models.ActiveMembers).
+		Order("users.name ASC").
+		Scan(&members).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, member := range members {
+		status := &MemberAnswerStatus{
+			UserID:   member.UserID,
+			Name:     member.Name,
+			Username: member.Username,
+			Answered: answeredShifts[member.UserID],
+			Total:    len(roster.RosterShift),
+		}
+
+		switch {
+		case status.Answered == 0:
+			summary.NotAnswered = append(summary.NotAnswered, status)
+		case status.Answered < status.Total:
+			summary.PartiallyAnswered = append(summary.PartiallyAnswered, status)
+		}
+	}
+
+	return summary, nil
+}
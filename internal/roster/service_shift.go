@@ -2,7 +2,10 @@ package roster
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"errors"
 	"fmt"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
 	"slices"
 )
 
@@ -11,8 +14,10 @@ type ShiftManager interface {
 	UpdateRosterShift(uint, *ShiftUpdateRequest) (*models.RosterShift, error)
 	DeleteRosterShift(ID uint) error
 
-	CreateRosterAnswer(*AnswerCreateRequest) (*models.RosterAnswer, error)
-	UpdateRosterAnswer(uint, *AnswerUpdateRequest) (*models.RosterAnswer, error)
+	CreateRosterAnswer(params *AnswerCreateRequest, actorID uint) (*models.RosterAnswer, error)
+	UpdateRosterAnswer(ID uint, params *AnswerUpdateRequest, actorID uint) (*models.RosterAnswer, error)
+	AnswerOnBehalf(rosterID uint, params *OnBehalfAnswerRequest, actorID uint) ([]*models.RosterAnswer, error)
+	GetAnswerHistory(*AnswerHistoryFilterParams) ([]*models.RosterAnswerChange, error)
 }
 
 func (s *service) CreateRosterShift(createParams *ShiftCreateRequest) (*models.RosterShift, error) {
@@ -78,7 +83,7 @@ func (s *service) DeleteRosterShift(ID uint) error {
 	return nil
 }
 
-func (s *service) CreateRosterAnswer(params *AnswerCreateRequest) (*models.RosterAnswer, error) {
+func (s *service) CreateRosterAnswer(params *AnswerCreateRequest, actorID uint) (*models.RosterAnswer, error) {
 	var roster *models.Roster
 	if err := s.db.First(&roster, params.RosterID).Error; err != nil {
 		return nil, fmt.Errorf("roster not found: %w", err)
@@ -98,27 +103,242 @@ func (s *service) CreateRosterAnswer(params *AnswerCreateRequest) (*models.Roste
 		RosterID:      roster.ID,
 		RosterShiftID: params.RosterShiftID,
 		Value:         params.Value,
+		EnteredByID:   enteredBy(actorID, params.UserID),
 	}
 
-	if err := s.db.Create(&rosterAnswer).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rosterAnswer).Error; err != nil {
+			return err
+		}
+
+		return recordAnswerChange(tx, &rosterAnswer, nil, &actorID, answerSource(actorID, rosterAnswer.UserID))
+	})
+	if err != nil {
 		return nil, err
 	}
 
+	s.notifyAnswersEntered(roster, params.UserID, actorID, 1)
+
 	return &rosterAnswer, nil
 }
 
-func (s *service) UpdateRosterAnswer(ID uint, updateParams *AnswerUpdateRequest) (*models.RosterAnswer, error) {
+func (s *service) UpdateRosterAnswer(ID uint, updateParams *AnswerUpdateRequest, actorID uint) (*models.RosterAnswer, error) {
 	var answer *models.RosterAnswer
 
 	if err := s.db.First(&answer, ID).Error; err != nil {
 		return nil, err
 	}
 
-	if err := s.db.Model(&answer).Updates(updateParams).Error; err != nil {
+	oldValue := answer.Value
+	changed := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&answer).Updates(updateParams).Error; err != nil {
+			return err
+		}
+
+		// Empty values are ignored by Updates, so those do not change the answer either
+		if updateParams.Value == "" || updateParams.Value == oldValue {
+			return nil
+		}
+
+		answer.Value = updateParams.Value
+		answer.EnteredByID = enteredBy(actorID, answer.UserID)
+		if err := tx.Model(&answer).Update("entered_by_id", answer.EnteredByID).Error; err != nil {
+			return err
+		}
+
+		changed = true
+		return recordAnswerChange(tx, answer, &oldValue, &actorID, answerSource(actorID, answer.UserID))
+	})
+	if err != nil {
 		return nil, err
 	}
+
+	if changed && actorID != answer.UserID {
+		var roster models.Roster
+		if err := s.db.First(&roster, answer.RosterID).Error; err == nil {
+			s.notifyAnswersEntered(&roster, answer.UserID, actorID, 1)
+		}
+	}
+
 	if err := s.db.First(&answer, ID).Error; err != nil {
 		return nil, err
 	}
 	return answer, nil
 }
+
+// AnswerOnBehalf lets an admin enter the answers of a member, e.g. when the
+// member sent their availability by chat. The admin is stored as the one who
+// entered the answers and the member gets notified.
+func (s *service) AnswerOnBehalf(rosterID uint, params *OnBehalfAnswerRequest, actorID uint) ([]*models.RosterAnswer, error) {
+	var roster models.Roster
+	if err := s.db.Preload("RosterShift").First(&roster, rosterID).Error; err != nil {
+		return nil, err
+	}
+
+	var membership models.UserOrgan
+	err := s.db.Scopes(models.ActiveMembers).
+		Where("user_id = ? AND organ_id = ?", params.UserID, roster.OrganID).
+		First(&membership).Error
+	if err != nil {
+		return nil, fmt.Errorf("user %d is not an active member of this organ: %w", params.UserID, err)
+	}
+
+	rosterShifts := make(map[uint]bool, len(roster.RosterShift))
+	for _, shift := range roster.RosterShift {
+		rosterShifts[shift.ID] = true
+	}
+
+	answers := make([]*models.RosterAnswer, 0, len(params.Answers))
+	// changed counts the answers that were created or got a new value
+	changed := 0
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		for _, entry := range params.Answers {
+			if !rosterShifts[entry.RosterShiftID] {
+				return fmt.Errorf("roster shift %d is not part of this roster", entry.RosterShiftID)
+			}
+
+			if !slices.Contains(roster.Values, entry.Value) {
+				return fmt.Errorf("%s is not a valid value for this roster", entry.Value)
+			}
+
+			var answer models.RosterAnswer
+			err := tx.Where("user_id = ? AND roster_id = ? AND roster_shift_id = ?", params.UserID, roster.ID, entry.RosterShiftID).
+				First(&answer).Error
+
+			switch {
+			case errors.Is(err, gorm.ErrRecordNotFound):
+				answer = models.RosterAnswer{
+					UserID:        params.UserID,
+					RosterID:      roster.ID,
+					RosterShiftID: entry.RosterShiftID,
+					Value:         entry.Value,
+					EnteredByID:   enteredBy(actorID, params.UserID),
+				}
+				if err := tx.Create(&answer).Error; err != nil {
+					return err
+				}
+
+				if err := recordAnswerChange(tx, &answer, nil, &actorID, answerSource(actorID, params.UserID)); err != nil {
+					return err
+				}
+				changed++
+			case err != nil:
+				return err
+			case answer.Value != entry.Value:
+				oldValue := answer.Value
+				answer.Value = entry.Value
+				answer.EnteredByID = enteredBy(actorID, params.UserID)
+
+				updates := map[string]interface{}{
+					"value":         answer.Value,
+					"entered_by_id": answer.EnteredByID,
+				}
+				if err := tx.Model(&answer).Updates(updates).Error; err != nil {
+					return err
+				}
+
+				if err := recordAnswerChange(tx, &answer, &oldValue, &actorID, answerSource(actorID, params.UserID)); err != nil {
+					return err
+				}
+				changed++
+			}
+
+			answers = append(answers, &answer)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyAnswersEntered(&roster, params.UserID, actorID, changed)
+
+	return answers, nil
+}
+
+// notifyAnswersEntered tells the member that someone else entered count
+// answers for them on the roster, whichever route the answers came through.
+// Nothing is sent when no answer changed. The answers are stored already, so a
+// failing notification is only logged.
+func (s *service) notifyAnswersEntered(roster *models.Roster, userID uint, actorID uint, count int) {
+	if actorID == userID || count == 0 || s.n == nil {
+		return
+	}
+
+	var actor models.User
+	if err := s.db.First(&actor, actorID).Error; err != nil {
+		log.Error().Err(err).Uint("actor_id", actorID).Msg("Failed to look up who entered answers")
+		return
+	}
+
+	notification := models.Notification{
+		UserID:   userID,
+		Type:     models.NotificationAnswersEntered,
+		Message:  fmt.Sprintf("%s filled in %d answer(s) for you on %s", actor.Name, count, roster.Name),
+		RosterID: &roster.ID,
+	}
+	if err := s.n.Notify(&notification); err != nil {
+		log.Error().Err(err).Uint("user_id", userID).Msg("Failed to notify user about answers entered on their behalf")
+	}
+}
+
+// GetAnswerHistory returns the recorded answer changes, newest first.
+func (s *service) GetAnswerHistory(params *AnswerHistoryFilterParams) ([]*models.RosterAnswerChange, error) {
+	query := s.db.Model(&models.RosterAnswerChange{})
+
+	if params.RosterID != nil {
+		query = query.Where("roster_answer_changes.roster_id = ?", *params.RosterID)
+	}
+	if params.UserID != nil {
+		query = query.Where("roster_answer_changes.user_id = ?", *params.UserID)
+	}
+	if params.OrganID != nil {
+		query = query.Joins("JOIN rosters ON rosters.id = roster_answer_changes.roster_id").
+			Where("rosters.organ_id = ?", *params.OrganID)
+	}
+
+	var changes []*models.RosterAnswerChange
+	if err := query.Order("roster_answer_changes.created_at DESC, roster_answer_changes.id DESC").Find(&changes).Error; err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// answerSource derives whether a member changed their own answer or an admin
+// changed it for them.
+func answerSource(actorID uint, userID uint) models.AnswerSource {
+	if actorID == userID {
+		return models.SourceMember
+	}
+	return models.SourceAdmin
+}
+
+// enteredBy returns the actor when they answer for someone else, and nil when
+// members answer for themselves.
+func enteredBy(actorID uint, userID uint) *uint {
+	if actorID == userID {
+		return nil
+	}
+	return &actorID
+}
+
+// recordAnswerChange stores the change of an answer in the answer history. It
+// is meant to run in the same transaction as the change itself.
+func recordAnswerChange(tx *gorm.DB, answer *models.RosterAnswer, oldValue *string, actorID *uint, source models.AnswerSource) error {
+	change := models.RosterAnswerChange{
+		RosterAnswerID: answer.ID,
+		RosterID:       answer.RosterID,
+		RosterShiftID:  answer.RosterShiftID,
+		UserID:         answer.UserID,
+		ActorID:        actorID,
+		OldValue:       oldValue,
+		NewValue:       answer.Value,
+		Source:         source,
+	}
+
+	return tx.Create(&change).Error
+}
@@ -97,6 +97,54 @@ func (suite *TestRosterSuite) TestCreateRoster_WithShifts() {
 	assert.ElementsMatch(suite.T(), shift, rosterShiftNames)
 }
 
+func (suite *TestRosterSuite) TestCreateRoster_OrganSettings() {
+	var template models.RosterTemplate
+	suite.db.Preload("Shifts").Where("organ_id = ?", 1).First(&template)
+	suite.Require().NotEmpty(template.Shifts)
+
+	suite.db.Create(&models.OrganSettings{
+		OrganID:           1,
+		AnswerValues:      models.Values{"Y", "N"},
+		DefaultTemplateID: &template.ID,
+		Timezone:          "Pacific/Kiritimati",
+	})
+
+	roster, err := suite.service.CreateRoster(&CreateRequest{
+		Name:    "Defaults",
+		Date:    time.Now().Add(25 * time.Hour),
+		OrganID: 1,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), models.Values{"Y", "N"}, roster.Values)
+	assert.Equal(suite.T(), &template.ID, roster.TemplateID)
+	assert.Len(suite.T(), roster.RosterShift, len(template.Shifts))
+
+	// A default template in the trash is not used
+	assert.NoError(suite.T(), suite.service.DeleteRosterTemplate(template.ID))
+	roster, err = suite.service.CreateRoster(&CreateRequest{
+		Name:    "Without template",
+		Date:    time.Now().Add(25 * time.Hour),
+		OrganID: 1,
+	})
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), roster.TemplateID)
+	assert.Empty(suite.T(), roster.RosterShift)
+}
+
+func (suite *TestRosterSuite) TestIsTodayOrLater_Timezone() {
+	// Pick a zone where it is half past midnight, and one where it is midday
+	now := time.Now().UTC()
+	sinceMidnight := now.Sub(now.Truncate(24 * time.Hour))
+	offset := int((30*time.Minute - sinceMidnight).Seconds())
+	justPastMidnight := time.FixedZone("midnight", offset)
+	midday := time.FixedZone("midday", offset+12*60*60)
+
+	// An hour ago is yesterday in the first zone, but today in the second
+	hourAgo := now.Add(-time.Hour)
+	assert.False(suite.T(), isTodayOrLater(hourAgo, justPastMidnight))
+	assert.True(suite.T(), isTodayOrLater(hourAgo, midday))
+}
+
 func (suite *TestRosterSuite) TestGetRosters_All() {
 	cParams := CreateRequest{
 		Name:    "Valid Name",
@@ -432,7 +480,7 @@ func (suite *TestRosterSuite) TestCreateRosterAnswer_Valid() {
 		Value:         "yes",
 	}
 
-	answer, err := suite.service.CreateRosterAnswer(createParams)
+	answer, err := suite.service.CreateRosterAnswer(createParams, createParams.UserID)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), answer)
@@ -460,7 +508,7 @@ func (suite *TestRosterSuite) TestCreateRosterAnswer_InvalidValue() {
 		Value:         "maybe",
 	}
 
-	answer, err := suite.service.CreateRosterAnswer(createParams)
+	answer, err := suite.service.CreateRosterAnswer(createParams, createParams.UserID)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), answer)
@@ -475,7 +523,7 @@ func (suite *TestRosterSuite) TestCreateRosterAnswer_RosterNotFound() {
 		Value:         "yes",
 	}
 
-	answer, err := suite.service.CreateRosterAnswer(createParams)
+	answer, err := suite.service.CreateRosterAnswer(createParams, createParams.UserID)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), answer)
@@ -497,7 +545,7 @@ func (suite *TestRosterSuite) TestCreateRosterAnswer_RosterShiftNotFound() {
 		Value:         "yes",
 	}
 
-	answer, err := suite.service.CreateRosterAnswer(createParams)
+	answer, err := suite.service.CreateRosterAnswer(createParams, createParams.UserID)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), answer)
@@ -512,7 +560,7 @@ func (suite *TestRosterSuite) TestUpdateRosterAnswer_Valid() {
 		Value: "new value",
 	}
 
-	updatedAnswer, err := suite.service.UpdateRosterAnswer(answer.ID, updateParams)
+	updatedAnswer, err := suite.service.UpdateRosterAnswer(answer.ID, updateParams, answer.UserID)
 
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), updatedAnswer)
@@ -525,7 +573,7 @@ func (suite *TestRosterSuite) TestUpdateRosterAnswer_NotFound() {
 		Value: "new value",
 	}
 
-	updatedAnswer, err := suite.service.UpdateRosterAnswer(nonExistentID, updateParams)
+	updatedAnswer, err := suite.service.UpdateRosterAnswer(nonExistentID, updateParams, 1)
 
 	assert.Error(suite.T(), err)
 	assert.Nil(suite.T(), updatedAnswer)
@@ -765,6 +813,572 @@ func (suite *TestRosterSuite) TestRosterTemplateDelete_InValid() {
 	assert.Error(suite.T(), err)
 }
 
+func (suite *TestRosterSuite) TestGetRosterSummary_Valid() {
+	var organ models.Organ
+	suite.db.First(&organ)
+
+	roster, err := suite.service.CreateRoster(&CreateRequest{
+		Name:    "Summary Roster",
+		Date:    time.Now().Add(25 * time.Hour),
+		OrganID: organ.ID,
+		Shifts:  []string{"Shift 1", "Shift 2"},
+	})
+	assert.NoError(suite.T(), err)
+
+	var members []models.UserOrgan
+	suite.db.Where("organ_id = ?", organ.ID).Order("user_id").Find(&members)
+	assert.GreaterOrEqual(suite.T(), len(members), 3)
+
+	full, partial := members[0].UserID, members[1].UserID
+	answers := []models.RosterAnswer{
+		{UserID: full, RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Value: "J"},
+		{UserID: full, RosterID: roster.ID, RosterShiftID: roster.RosterShift[1].ID, Value: "X"},
+		{UserID: partial, RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Value: "J"},
+	}
+	suite.db.Create(&answers)
+
+	summary, err := suite.service.GetRosterSummary(roster.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), summary.Shifts, 2)
+
+	counts := make(map[string]map[string]int)
+	for _, shift := range summary.Shifts {
+		counts[shift.ShiftName] = shift.Counts
+	}
+	assert.Equal(suite.T(), 2, counts["Shift 1"]["J"])
+	assert.Equal(suite.T(), 0, counts["Shift 1"]["X"])
+	assert.Equal(suite.T(), 1, counts["Shift 2"]["X"])
+
+	assert.Len(suite.T(), summary.PartiallyAnswered, 1)
+	assert.Equal(suite.T(), partial, summary.PartiallyAnswered[0].UserID)
+	assert.Equal(suite.T(), 1, summary.PartiallyAnswered[0].Answered)
+	assert.Len(suite.T(), summary.NotAnswered, len(members)-2)
+	for _, status := range summary.NotAnswered {
+		assert.NotEqual(suite.T(), full, status.UserID)
+		assert.NotEqual(suite.T(), partial, status.UserID)
+	}
+}
+
+func (suite *TestRosterSuite) TestGetRosterSummary_FormerMembers() {
+	var organ models.Organ
+	suite.db.First(&organ)
+
+	roster, err := suite.service.CreateRoster(&CreateRequest{
+		Name:    "Summary Roster",
+		Date:    time.Now().Add(25 * time.Hour),
+		OrganID: organ.ID,
+		Shifts:  []string{"Shift 1"},
+	})
+	assert.NoError(suite.T(), err)
+
+	var members []models.UserOrgan
+	suite.db.Where("organ_id = ?", organ.ID).Order("user_id").Find(&members)
+	answered, former := members[0].UserID, members[1].UserID
+	suite.db.Create(&models.RosterAnswer{UserID: answered, RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Value: "J"})
+	suite.db.Create(&models.RosterAnswer{UserID: former, RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Value: "J"})
+	suite.db.Model(&models.UserOrgan{}).
+		Where("organ_id = ? AND user_id IN ?", organ.ID, []uint{former, members[2].UserID}).
+		Update("status", models.MembershipAlumni)
+
+	summary, err := suite.service.GetRosterSummary(roster.ID)
+	assert.NoError(suite.T(), err)
+
+	// Answers of former members still count, but they are not asked to answer
+	assert.Equal(suite.T(), 2, summary.Shifts[0].Counts["J"])
+	assert.Len(suite.T(), summary.NotAnswered, len(members)-3)
+	for _, status := range summary.NotAnswered {
+		assert.NotEqual(suite.T(), members[2].UserID, status.UserID)
+	}
+}
+
+func (suite *TestRosterSuite) TestGetRosterSummary_NotFound() {
+	summary, err := suite.service.GetRosterSummary(99999)
+	assert.Error(suite.T(), err)
+	assert.Nil(suite.T(), summary)
+}
+
+func (suite *TestRosterSuite) createSelfSignupShift(capacity uint, waitlist bool) (*models.SavedShift, []models.User) {
+	roster := models.Roster{
+		Name:            "Self Signup Roster",
+		OrganID:         1,
+		Mode:            models.ModeSelfSignup,
+		WaitlistEnabled: waitlist,
+	}
+	suite.db.Create(&roster)
+
+	shift := models.RosterShift{RosterID: roster.ID, Name: "Bar"}
+	suite.db.Create(&shift)
+
+	saved := models.SavedShift{RosterID: roster.ID, RosterShiftID: shift.ID, Capacity: capacity}
+	suite.db.Create(&saved)
+
+	var users []models.User
+	suite.db.Limit(3).Find(&users)
+
+	return &saved, users
+}
+
+func (suite *TestRosterSuite) TestClaimSavedShift_Valid() {
+	saved, users := suite.createSelfSignupShift(1, false)
+
+	claim, err := suite.service.ClaimSavedShift(saved.ID, users[0].ID)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), claim.Waitlisted)
+	assert.Len(suite.T(), claim.SavedShift.Users, 1)
+	assert.Equal(suite.T(), users[0].ID, claim.SavedShift.Users[0].ID)
+
+	_, err = suite.service.ClaimSavedShift(saved.ID, users[0].ID)
+	assert.ErrorIs(suite.T(), err, ErrAlreadyClaimed)
+}
+
+func (suite *TestRosterSuite) TestClaimSavedShift_Full() {
+	saved, users := suite.createSelfSignupShift(1, false)
+
+	_, err := suite.service.ClaimSavedShift(saved.ID, users[0].ID)
+	assert.NoError(suite.T(), err)
+
+	claim, err := suite.service.ClaimSavedShift(saved.ID, users[1].ID)
+	assert.ErrorIs(suite.T(), err, ErrShiftFull)
+	assert.Nil(suite.T(), claim)
+}
+
+func (suite *TestRosterSuite) TestClaimSavedShift_NotSelfSignup() {
+	saved, users := suite.createSelfSignupShift(1, false)
+	suite.db.Model(&models.Roster{}).Where("id = ?", saved.RosterID).Update("mode", models.ModeAssigned)
+
+	_, err := suite.service.ClaimSavedShift(saved.ID, users[0].ID)
+	assert.ErrorIs(suite.T(), err, ErrNotSelfSignup)
+}
+
+func (suite *TestRosterSuite) TestReleaseSavedShift_PromotesWaitlist() {
+	saved, users := suite.createSelfSignupShift(1, true)
+
+	_, err := suite.service.ClaimSavedShift(saved.ID, users[0].ID)
+	assert.NoError(suite.T(), err)
+
+	claim, err := suite.service.ClaimSavedShift(saved.ID, users[1].ID)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), claim.Waitlisted)
+	assert.Equal(suite.T(), 1, claim.Position)
+
+	claim, err = suite.service.ClaimSavedShift(saved.ID, users[2].ID)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), 2, claim.Position)
+
+	released, err := suite.service.ReleaseSavedShift(saved.ID, users[0].ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), released.Users, 1)
+	assert.Equal(suite.T(), users[1].ID, released.Users[0].ID)
+	assert.Len(suite.T(), released.Waitlist, 1)
+	assert.Equal(suite.T(), users[2].ID, released.Waitlist[0].UserID)
+}
+
+func (suite *TestRosterSuite) TestReleaseSavedShift_NotClaimed() {
+	saved, users := suite.createSelfSignupShift(1, false)
+
+	released, err := suite.service.ReleaseSavedShift(saved.ID, users[0].ID)
+	assert.ErrorIs(suite.T(), err, ErrNotClaimed)
+	assert.Nil(suite.T(), released)
+}
+
+func (suite *TestRosterSuite) TestUpdateSavedShift_CapacityPromotesWaitlist() {
+	saved, users := suite.createSelfSignupShift(0, true)
+
+	claim, err := suite.service.ClaimSavedShift(saved.ID, users[0].ID)
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), claim.Waitlisted)
+
+	capacity := uint(2)
+	updated, err := suite.service.UpdateSavedShift(saved.ID, &SavedShiftUpdateRequest{Capacity: &capacity})
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), capacity, updated.Capacity)
+	assert.Len(suite.T(), updated.Users, 1)
+	assert.Empty(suite.T(), updated.Waitlist)
+}
+
+func (suite *TestRosterSuite) TestSaveRoster_SelfSignupShiftCapacity() {
+	roster := models.Roster{Name: "Self Signup Roster", OrganID: 1, Mode: models.ModeSelfSignup, ShiftCapacity: 2}
+	suite.db.Create(&roster)
+	suite.db.Create(&models.RosterShift{RosterID: roster.ID, Name: "Bar"})
+
+	assert.NoError(suite.T(), suite.service.SaveRoster(roster.ID))
+
+	var saved models.SavedShift
+	suite.Require().NoError(suite.db.Where("roster_id = ?", roster.ID).First(&saved).Error)
+	assert.Equal(suite.T(), uint(2), saved.Capacity)
+
+	var users []models.User
+	suite.db.Limit(2).Find(&users)
+	for _, user := range users {
+		claim, err := suite.service.ClaimSavedShift(saved.ID, user.ID)
+		assert.NoError(suite.T(), err)
+		assert.False(suite.T(), claim.Waitlisted)
+	}
+}
+
+func (suite *TestRosterSuite) TestUpdateSavedShift_UsersReconcileWaitlist() {
+	saved, users := suite.createSelfSignupShift(1, true)
+
+	for _, user := range users {
+		_, err := suite.service.ClaimSavedShift(saved.ID, user.ID)
+		suite.Require().NoError(err)
+	}
+
+	// Assigning a waiting member takes them off the waitlist
+	updated, err := suite.service.UpdateSavedShift(saved.ID, &SavedShiftUpdateRequest{UserIDs: []uint{users[1].ID}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), updated.Users, 1)
+	assert.Equal(suite.T(), users[1].ID, updated.Users[0].ID)
+	assert.Len(suite.T(), updated.Waitlist, 1)
+	assert.Equal(suite.T(), users[2].ID, updated.Waitlist[0].UserID)
+
+	// Removing the member hands the slot to the next one waiting
+	updated, err = suite.service.UpdateSavedShift(saved.ID, &SavedShiftUpdateRequest{UserIDs: []uint{}})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), updated.Users, 1)
+	assert.Equal(suite.T(), users[2].ID, updated.Users[0].ID)
+	assert.Empty(suite.T(), updated.Waitlist)
+}
+
+func (suite *TestRosterSuite) TestUpdateRoster_Publish() {
+	var roster *models.Roster
+	suite.db.First(&roster)
+
+	published := true
+	roster, err := suite.service.UpdateRoster(roster.ID, &UpdateRequest{Published: &published})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), roster.Published)
+}
+
+func (suite *TestRosterSuite) TestGetRosterSchedule_Valid() {
+	roster, err := suite.service.CreateRoster(&CreateRequest{
+		Name:    "Schedule Roster",
+		Date:    time.Now().Add(25 * time.Hour),
+		OrganID: 1,
+		Shifts:  []string{"Opening", "Closing"},
+	})
+	assert.NoError(suite.T(), err)
+	assert.NoError(suite.T(), suite.service.SaveRoster(roster.ID))
+
+	var user models.User
+	suite.db.First(&user)
+
+	var opening models.SavedShift
+	suite.db.Joins("RosterShift").Where("saved_shifts.roster_id = ? AND RosterShift.name = ?", roster.ID, "Opening").First(&opening)
+	_, err = suite.service.UpdateSavedShift(opening.ID, &SavedShiftUpdateRequest{UserIDs: []uint{user.ID}})
+	assert.NoError(suite.T(), err)
+
+	schedule, err := suite.service.GetRosterSchedule(roster.ID)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), schedule.Published)
+	assert.Len(suite.T(), schedule.Shifts, 2)
+	assert.Equal(suite.T(), "Opening", schedule.Shifts[0].ShiftName)
+	assert.Len(suite.T(), schedule.Shifts[0].Users, 1)
+	assert.Equal(suite.T(), user.ID, schedule.Shifts[0].Users[0].ID)
+	assert.NotEmpty(suite.T(), schedule.Shifts[0].Users[0].Username)
+	assert.Empty(suite.T(), schedule.Shifts[1].Users)
+}
+
+func (suite *TestRosterSuite) TestGetAnswerHistory_RecordsChanges() {
+	roster := models.Roster{
+		Name:    "History Roster",
+		Values:  []string{"yes", "no"},
+		OrganID: uint(1),
+	}
+	suite.db.Create(&roster)
+
+	shift := models.RosterShift{RosterID: roster.ID}
+	suite.db.Create(&shift)
+
+	var users []models.User
+	suite.db.Limit(2).Find(&users)
+	member, admin := users[0].ID, users[1].ID
+
+	answer, err := suite.service.CreateRosterAnswer(&AnswerCreateRequest{
+		UserID:        member,
+		RosterID:      roster.ID,
+		RosterShiftID: shift.ID,
+		Value:         "yes",
+	}, member)
+	assert.NoError(suite.T(), err)
+
+	_, err = suite.service.UpdateRosterAnswer(answer.ID, &AnswerUpdateRequest{Value: "no"}, admin)
+	assert.NoError(suite.T(), err)
+
+	// Writing the same value again is not a change
+	_, err = suite.service.UpdateRosterAnswer(answer.ID, &AnswerUpdateRequest{Value: "no"}, admin)
+	assert.NoError(suite.T(), err)
+
+	history, err := suite.service.GetAnswerHistory(&AnswerHistoryFilterParams{RosterID: &roster.ID})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), history, 2)
+
+	update, create := history[0], history[1]
+	assert.Equal(suite.T(), models.SourceAdmin, update.Source)
+	assert.Equal(suite.T(), admin, *update.ActorID)
+	assert.Equal(suite.T(), "yes", *update.OldValue)
+	assert.Equal(suite.T(), "no", update.NewValue)
+
+	assert.Equal(suite.T(), models.SourceMember, create.Source)
+	assert.Equal(suite.T(), member, *create.ActorID)
+	assert.Nil(suite.T(), create.OldValue)
+	assert.Equal(suite.T(), "yes", create.NewValue)
+
+	organID := uint(1)
+	history, err = suite.service.GetAnswerHistory(&AnswerHistoryFilterParams{OrganID: &organID, UserID: &admin})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), history)
+}
+
+func (suite *TestRosterSuite) TestAnswerOnBehalf_Valid() {
+	roster := models.Roster{
+		Name:    "On Behalf Roster",
+		Values:  []string{"yes", "no"},
+		OrganID: uint(1),
+	}
+	suite.db.Create(&roster)
+
+	first := models.RosterShift{RosterID: roster.ID, Name: "Opening"}
+	second := models.RosterShift{RosterID: roster.ID, Name: "Closing"}
+	suite.db.Create(&first)
+	suite.db.Create(&second)
+
+	var users []models.User
+	suite.db.Limit(2).Find(&users)
+	member, admin := users[0].ID, users[1].ID
+
+	existing, err := suite.service.CreateRosterAnswer(&AnswerCreateRequest{
+		UserID:        member,
+		RosterID:      roster.ID,
+		RosterShiftID: first.ID,
+		Value:         "yes",
+	}, member)
+	assert.NoError(suite.T(), err)
+	assert.Nil(suite.T(), existing.EnteredByID)
+
+	notifier := &recordingNotifier{}
+	suite.service.n = notifier
+
+	answers, err := suite.service.AnswerOnBehalf(roster.ID, &OnBehalfAnswerRequest{
+		UserID: member,
+		Answers: []OnBehalfAnswer{
+			{RosterShiftID: first.ID, Value: "no"},
+			{RosterShiftID: second.ID, Value: "yes"},
+		},
+	}, admin)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), answers, 2)
+	assert.Equal(suite.T(), existing.ID, answers[0].ID)
+
+	for _, answer := range answers {
+		var stored models.RosterAnswer
+		suite.db.First(&stored, answer.ID)
+		assert.Equal(suite.T(), admin, *stored.EnteredByID)
+	}
+
+	history, err := suite.service.GetAnswerHistory(&AnswerHistoryFilterParams{RosterID: &roster.ID, UserID: &member})
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), history, 3)
+
+	assert.Len(suite.T(), notifier.sent, 1)
+	assert.Equal(suite.T(), member, notifier.sent[0].UserID)
+	assert.Equal(suite.T(), models.NotificationAnswersEntered, notifier.sent[0].Type)
+	assert.Equal(suite.T(), roster.ID, *notifier.sent[0].RosterID)
+
+	// A member answering again takes back ownership of the answer
+	_, err = suite.service.UpdateRosterAnswer(existing.ID, &AnswerUpdateRequest{Value: "yes"}, member)
+	assert.NoError(suite.T(), err)
+
+	var stored models.RosterAnswer
+	suite.db.First(&stored, existing.ID)
+	assert.Nil(suite.T(), stored.EnteredByID)
+
+	// Answers an admin enters through the regular routes notify the member too
+	_, err = suite.service.UpdateRosterAnswer(existing.ID, &AnswerUpdateRequest{Value: "no"}, admin)
+	assert.NoError(suite.T(), err)
+	_, err = suite.service.CreateRosterAnswer(&AnswerCreateRequest{
+		UserID:        admin,
+		RosterID:      roster.ID,
+		RosterShiftID: first.ID,
+		Value:         "yes",
+	}, admin)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), notifier.sent, 2)
+	assert.Equal(suite.T(), member, notifier.sent[1].UserID)
+}
+
+func (suite *TestRosterSuite) TestAnswerCreate_ByAdminNotifiesMember() {
+	roster := models.Roster{Name: "Notify Roster", Values: []string{"yes", "no"}, OrganID: uint(1)}
+	suite.db.Create(&roster)
+	shift := models.RosterShift{RosterID: roster.ID, Name: "Bar"}
+	suite.db.Create(&shift)
+
+	var users []models.User
+	suite.db.Limit(2).Find(&users)
+	member, admin := users[0].ID, users[1].ID
+
+	notifier := &recordingNotifier{}
+	suite.service.n = notifier
+
+	_, err := suite.service.CreateRosterAnswer(&AnswerCreateRequest{
+		UserID:        member,
+		RosterID:      roster.ID,
+		RosterShiftID: shift.ID,
+		Value:         "yes",
+	}, admin)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), notifier.sent, 1)
+	assert.Equal(suite.T(), member, notifier.sent[0].UserID)
+	assert.Equal(suite.T(), models.NotificationAnswersEntered, notifier.sent[0].Type)
+}
+
+func (suite *TestRosterSuite) TestAnswerOnBehalf_CountsOnlyChanges() {
+	roster := models.Roster{Name: "On Behalf Roster", Values: []string{"yes", "no"}, OrganID: uint(1)}
+	suite.db.Create(&roster)
+	first := models.RosterShift{RosterID: roster.ID, Name: "Opening"}
+	second := models.RosterShift{RosterID: roster.ID, Name: "Closing"}
+	suite.db.Create(&first)
+	suite.db.Create(&second)
+
+	var users []models.User
+	suite.db.Limit(2).Find(&users)
+	member, admin := users[0].ID, users[1].ID
+
+	notifier := &recordingNotifier{}
+	suite.service.n = notifier
+
+	answer := func(firstValue, secondValue string) error {
+		_, err := suite.service.AnswerOnBehalf(roster.ID, &OnBehalfAnswerRequest{
+			UserID: member,
+			Answers: []OnBehalfAnswer{
+				{RosterShiftID: first.ID, Value: firstValue},
+				{RosterShiftID: second.ID, Value: secondValue},
+			},
+		}, admin)
+		return err
+	}
+
+	assert.NoError(suite.T(), answer("yes", "yes"))
+	assert.NoError(suite.T(), answer("yes", "no"))
+	assert.NoError(suite.T(), answer("yes", "no"))
+	if assert.Len(suite.T(), notifier.sent, 2) {
+		assert.Contains(suite.T(), notifier.sent[0].Message, "2 answer(s)")
+		assert.Contains(suite.T(), notifier.sent[1].Message, "1 answer(s)")
+	}
+
+	// Former members cannot be given answers
+	suite.db.Model(&models.UserOrgan{}).Where("user_id = ? AND organ_id = ?", member, 1).
+		Update("status", models.MembershipAlumni)
+	assert.Error(suite.T(), answer("no", "no"))
+}
+
+func (suite *TestRosterSuite) TestAnswerOnBehalf_InvalidShift() {
+	roster := models.Roster{
+		Name:    "On Behalf Roster",
+		Values:  []string{"yes", "no"},
+		OrganID: uint(1),
+	}
+	suite.db.Create(&roster)
+
+	other := models.Roster{Name: "Other Roster", Values: []string{"yes"}, OrganID: uint(1)}
+	suite.db.Create(&other)
+	shift := models.RosterShift{RosterID: other.ID}
+	suite.db.Create(&shift)
+
+	var users []models.User
+	suite.db.Limit(2).Find(&users)
+
+	_, err := suite.service.AnswerOnBehalf(roster.ID, &OnBehalfAnswerRequest{
+		UserID:  users[0].ID,
+		Answers: []OnBehalfAnswer{{RosterShiftID: shift.ID, Value: "yes"}},
+	}, users[1].ID)
+	assert.Error(suite.T(), err)
+}
+
+type recordingNotifier struct {
+	sent []*models.Notification
+}
+
+func (r *recordingNotifier) Notify(notification *models.Notification) error {
+	r.sent = append(r.sent, notification)
+	return nil
+}
+
+func (suite *TestRosterSuite) TestTrash_DeleteAndRestore() {
+	roster, err := suite.service.CreateRoster(&CreateRequest{
+		Name:    "Misclick",
+		Date:    time.Now().Add(25 * time.Hour),
+		OrganID: 1,
+		Shifts:  []string{"Bar"},
+	})
+	suite.Require().NoError(err)
+
+	var member models.UserOrgan
+	suite.db.Where("organ_id = ?", 1).First(&member)
+	suite.db.Create(&models.RosterAnswer{UserID: member.UserID, RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Value: "J"})
+
+	assert.NoError(suite.T(), suite.service.DeleteRoster(roster.ID))
+
+	rosters, err := suite.service.GetRosters(&FilterParams{ID: &roster.ID})
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), rosters)
+
+	trash, err := suite.service.GetTrash(1)
+	assert.NoError(suite.T(), err)
+	if assert.Len(suite.T(), trash.Rosters, 1) {
+		assert.Equal(suite.T(), roster.ID, trash.Rosters[0].ID)
+	}
+
+	restored, err := suite.service.RestoreRoster(roster.ID)
+	assert.NoError(suite.T(), err)
+	assert.False(suite.T(), restored.DeletedAt.Valid)
+	assert.Len(suite.T(), restored.RosterShift, 1)
+
+	var answers int64
+	suite.db.Model(&models.RosterAnswer{}).Where("roster_id = ?", roster.ID).Count(&answers)
+	assert.EqualValues(suite.T(), 1, answers)
+
+	_, err = suite.service.RestoreRoster(roster.ID)
+	assert.ErrorIs(suite.T(), err, ErrNotInTrash)
+}
+
+func (suite *TestRosterSuite) TestTrash_Purge() {
+	var template models.RosterTemplate
+	suite.db.Where("organ_id = ?", 1).First(&template)
+
+	roster, err := suite.service.CreateRoster(&CreateRequest{
+		Name:    "Old",
+		Date:    time.Now().Add(25 * time.Hour),
+		OrganID: 1,
+		Shifts:  []string{"Bar"},
+	})
+	suite.Require().NoError(err)
+
+	assert.NoError(suite.T(), suite.service.DeleteRoster(roster.ID))
+	assert.NoError(suite.T(), suite.service.DeleteRosterTemplate(template.ID))
+
+	// Nothing has been in the trash for a day yet
+	purged, err := suite.service.PurgeTrash(time.Now().Add(-24 * time.Hour))
+	assert.NoError(suite.T(), err)
+	assert.Zero(suite.T(), purged)
+
+	purged, err = suite.service.PurgeTrash(time.Now().Add(time.Minute))
+	assert.NoError(suite.T(), err)
+	assert.EqualValues(suite.T(), 2, purged)
+
+	trash, err := suite.service.GetTrash(1)
+	assert.NoError(suite.T(), err)
+	assert.Empty(suite.T(), trash.Rosters)
+	assert.Empty(suite.T(), trash.Templates)
+
+	var shifts int64
+	suite.db.Model(&models.RosterShift{}).Where("roster_id = ?", roster.ID).Count(&shifts)
+	assert.Zero(suite.T(), shifts)
+
+	_, err = suite.service.RestoreRosterTemplate(template.ID)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
+}
+
 func TestRosterService(t *testing.T) {
 	suite.Run(t, new(TestRosterSuite))
 }
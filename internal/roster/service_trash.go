@@ -0,0 +1,150 @@
+package roster
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultTrashRetention is how long deleted rosters and templates stay in the
+// trash when TRASH_RETENTION_DAYS is not set.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+var ErrNotInTrash = errors.New("item is not in the trash")
+
+type TrashManager interface {
+	GetTrash(organID uint) (*TrashResponse, error)
+	RestoreRoster(ID uint) (*models.Roster, error)
+	RestoreRosterTemplate(ID uint) (*models.RosterTemplate, error)
+	PurgeTrash(before time.Time) (int64, error)
+}
+
+// GetTrash lists the deleted rosters and templates of the organ that have not
+// been purged yet.
+func (s *service) GetTrash(organID uint) (*TrashResponse, error) {
+	trash := &TrashResponse{}
+
+	if err := s.db.Unscoped().Preload("RosterShift").
+		Where("organ_id = ? AND deleted_at IS NOT NULL", organID).
+		Order("deleted_at DESC").
+		Find(&trash.Rosters).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Unscoped().Preload("Shifts").
+		Where("organ_id = ? AND deleted_at IS NOT NULL", organID).
+		Order("deleted_at DESC").
+		Find(&trash.Templates).Error; err != nil {
+		return nil, err
+	}
+
+	return trash, nil
+}
+
+// RestoreRoster takes a roster out of the trash, with its shifts, answers and
+// saved shifts.
+func (s *service) RestoreRoster(ID uint) (*models.Roster, error) {
+	var roster models.Roster
+	if err := s.db.Unscoped().First(&roster, ID).Error; err != nil {
+		return nil, err
+	}
+	if !roster.DeletedAt.Valid {
+		return nil, ErrNotInTrash
+	}
+
+	if err := s.db.Unscoped().Model(&roster).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Organ").Preload("RosterShift").First(&roster, ID).Error; err != nil {
+		return nil, err
+	}
+	return &roster, nil
+}
+
+// RestoreRosterTemplate takes a template out of the trash, with its shifts
+// and preferences.
+func (s *service) RestoreRosterTemplate(ID uint) (*models.RosterTemplate, error) {
+	var template models.RosterTemplate
+	if err := s.db.Unscoped().First(&template, ID).Error; err != nil {
+		return nil, err
+	}
+	if !template.DeletedAt.Valid {
+		return nil, ErrNotInTrash
+	}
+
+	if err := s.db.Unscoped().Model(&template).Update("deleted_at", nil).Error; err != nil {
+		return nil, err
+	}
+
+	if err := s.db.Preload("Shifts").First(&template, ID).Error; err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// PurgeTrash permanently deletes the rosters and templates that were moved to
+// the trash before the given time. The database cascades the delete to their
+// shifts, answers and saved shifts.
+func (s *service) PurgeTrash(before time.Time) (int64, error) {
+	var purged int64
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.Roster{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged += result.RowsAffected
+
+		result = tx.Unscoped().Where("deleted_at < ?", before).Delete(&models.RosterTemplate{})
+		if result.Error != nil {
+			return result.Error
+		}
+		purged += result.RowsAffected
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return purged, nil
+}
+
+// TrashRetentionFromEnv reads TRASH_RETENTION_DAYS, falling back to
+// DefaultTrashRetention when it is unset or invalid.
+func TrashRetentionFromEnv() time.Duration {
+	value := os.Getenv("TRASH_RETENTION_DAYS")
+	if value == "" {
+		return DefaultTrashRetention
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 1 {
+		log.Warn().Str("value", value).Msg("Invalid TRASH_RETENTION_DAYS, using the default")
+		return DefaultTrashRetention
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// PurgeTrashPeriodically purges the trash every interval, deleting what has
+// been in it longer than retention. It blocks, so run it in a goroutine.
+func PurgeTrashPeriodically(s TrashManager, retention time.Duration, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.PurgeTrash(time.Now().Add(-retention))
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to purge the trash")
+		} else if purged > 0 {
+			log.Info().Int64("purged", purged).Msg("Purged rosters and templates from the trash")
+		}
+
+		<-ticker.C
+	}
+}
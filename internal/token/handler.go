@@ -0,0 +1,143 @@
+package token
+
+import (
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"net/http"
+	"strconv"
+)
+
+type Handler struct {
+	tokenService Service
+}
+
+func NewTokenHandler(rg *authz.Router, tokenService Service) *Handler {
+	h := &Handler{tokenService: tokenService}
+
+	g := rg.Group("/token")
+
+	// Tokens are scoped to the authenticated user by the service
+	g.GET("", authz.Authenticated.Sensitive(), h.GetTokens)
+	g.POST("", authz.Authenticated.Sensitive(), h.CreateToken)
+	g.DELETE("/:id", authz.Authenticated.Sensitive(), h.RevokeToken)
+
+	return h
+}
+
+// GetTokens
+//
+//	@Summary		List personal access tokens
+//	@Security		BearerAuth
+//	@Description	Lists the active personal access tokens of the authenticated user
+//	@Tags			Token
+//	@Produce		json
+//	@Success		200	{array}		models.PersonalAccessToken
+//	@Failure		403	{object}	map[string]string
+//	@ID				getTokens
+//	@Router			/token [get]
+func (h *Handler) GetTokens(c *gin.Context) {
+	userID, ok := interactiveUser(c)
+	if !ok {
+		return
+	}
+
+	tokens, err := h.tokenService.List(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, tokens)
+}
+
+// CreateToken
+//
+//	@Summary		Create a personal access token
+//	@Security		BearerAuth
+//	@Description	Creates a token for scripts acting as the authenticated user. The token is only returned once.
+//	@Tags			Token
+//	@Accept			json
+//	@Produce		json
+//	@Param			createParams	body		token.CreateRequest	true	"Token input"
+//	@Success		201				{object}	token.CreateResponse
+//	@Failure		400				{object}	map[string]string
+//	@Failure		403				{object}	map[string]string
+//	@Router			/token [post]
+func (h *Handler) CreateToken(c *gin.Context) {
+	userID, ok := interactiveUser(c)
+	if !ok {
+		return
+	}
+
+	var params CreateRequest
+	if err := c.ShouldBindJSON(&params); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	created, err := h.tokenService.Create(userID, &params)
+	if err != nil {
+		if errors.Is(err, ErrNotMember) || errors.Is(err, ErrExpired) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, created)
+}
+
+// RevokeToken
+//
+//	@Summary		Revoke a personal access token
+//	@Security		BearerAuth
+//	@Description	Revokes a personal access token of the authenticated user
+//	@Tags			Token
+//	@Param			id	path	uint	true	"Token ID"
+//	@Success		204
+//	@Failure		400	{object}	map[string]string
+//	@Failure		404	{object}	map[string]string
+//	@Router			/token/{id} [delete]
+func (h *Handler) RevokeToken(c *gin.Context) {
+	userID, ok := interactiveUser(c)
+	if !ok {
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid token ID"})
+		return
+	}
+
+	if err := h.tokenService.Revoke(uint(tokenID), userID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Token not found"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// interactiveUser returns the authenticated user, unless the request was made
+// with a personal access token. Tokens cannot manage tokens, so a leaked
+// token cannot be used to mint wider ones.
+func interactiveUser(c *gin.Context) (uint, bool) {
+	if _, viaToken := authz.GetTokenScope(c); viaToken {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Personal access tokens cannot manage tokens"})
+		return 0, false
+	}
+
+	userID, ok := authz.UserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return 0, false
+	}
+	return userID, true
+}
@@ -0,0 +1,164 @@
+package token
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"gorm.io/gorm"
+	"io"
+	"strings"
+	"time"
+)
+
+// Prefix starts every personal access token, so they are easy to recognise in
+// headers and secret scanners.
+const Prefix = "grt_"
+
+// lastUsedInterval throttles the last used updates, so a busy script does not
+// write on every request.
+const lastUsedInterval = time.Minute
+
+var (
+	ErrInvalidToken = errors.New("personal access token is invalid, expired or revoked")
+	ErrNotMember    = errors.New("tokens can only be scoped to organs you are a member of")
+	ErrExpired      = errors.New("expiry must be in the future")
+)
+
+type Service interface {
+	Create(userID uint, params *CreateRequest) (*CreateResponse, error)
+	List(userID uint) ([]*models.PersonalAccessToken, error)
+	Revoke(ID uint, userID uint) error
+	Authenticate(raw string) (*models.PersonalAccessToken, error)
+}
+
+type service struct {
+	db *gorm.DB
+}
+
+func NewTokenService(db *gorm.DB) Service {
+	return &service{db: db}
+}
+
+func (s *service) Create(userID uint, params *CreateRequest) (*CreateResponse, error) {
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(time.Now()) {
+		return nil, ErrExpired
+	}
+
+	var organs []models.Organ
+	if len(params.OrganIDs) > 0 {
+		err := s.db.Joins("JOIN user_organs ON user_organs.organ_id = organs.id").
+			Where("user_organs.user_id = ? AND organs.id IN ?", userID, params.OrganIDs).
+			Scopes(models.ActiveMembers).
+			Find(&organs).Error
+		if err != nil {
+			return nil, err
+		}
+
+		if len(organs) != len(uniqueIDs(params.OrganIDs)) {
+			return nil, ErrNotMember
+		}
+	}
+
+	raw, err := generate()
+	if err != nil {
+		return nil, err
+	}
+
+	token := models.PersonalAccessToken{
+		UserID:    userID,
+		Name:      params.Name,
+		Prefix:    raw[:len(Prefix)+8],
+		TokenHash: hash(raw),
+		Scope:     params.Scope,
+		Organs:    organs,
+		ExpiresAt: params.ExpiresAt,
+	}
+	if err := s.db.Create(&token).Error; err != nil {
+		return nil, err
+	}
+
+	return &CreateResponse{Token: raw, PersonalAccessToken: &token}, nil
+}
+
+func (s *service) List(userID uint) ([]*models.PersonalAccessToken, error) {
+	var tokens []*models.PersonalAccessToken
+	err := s.db.Preload("Organs").
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("created_at DESC, id DESC").
+		Find(&tokens).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+// Revoke revokes a token of the user. Tokens of other users are reported as
+// not found.
+func (s *service) Revoke(ID uint, userID uint) error {
+	result := s.db.Model(&models.PersonalAccessToken{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", ID, userID).
+		Update("revoked_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	return nil
+}
+
+// Authenticate returns the active token matching raw and records its use.
+func (s *service) Authenticate(raw string) (*models.PersonalAccessToken, error) {
+	if !strings.HasPrefix(raw, Prefix) {
+		return nil, ErrInvalidToken
+	}
+
+	var token models.PersonalAccessToken
+	err := s.db.Preload("Organs").Where("token_hash = ?", hash(raw)).First(&token).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidToken
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	if token.RevokedAt != nil || (token.ExpiresAt != nil && now.After(*token.ExpiresAt)) {
+		return nil, ErrInvalidToken
+	}
+
+	if token.LastUsedAt == nil || now.Sub(*token.LastUsedAt) > lastUsedInterval {
+		err := s.db.Model(&token).UpdateColumn("last_used_at", now).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &token, nil
+}
+
+func generate() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return Prefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hash(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func uniqueIDs(ids []uint) map[uint]struct{} {
+	unique := make(map[uint]struct{}, len(ids))
+	for _, id := range ids {
+		unique[id] = struct{}{}
+	}
+	return unique
+}
@@ -0,0 +1,107 @@
+package token
+
+import (
+	"GEWIS-Rooster/cmd/seeder/seeder"
+	"GEWIS-Rooster/internal/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/suite"
+	"gorm.io/gorm"
+	"strings"
+	"testing"
+	"time"
+)
+
+type TestTokenSuite struct {
+	suite.Suite
+	db      *gorm.DB
+	service service
+
+	user models.User
+}
+
+func (suite *TestTokenSuite) SetupTest() {
+	db := seeder.Seeder(":memory:")
+	suite.db = db
+	suite.service = service{db: db}
+	db.First(&suite.user)
+}
+
+func (suite *TestTokenSuite) TestCreate_HashedAndScoped() {
+	created, err := suite.service.Create(suite.user.ID, &CreateRequest{
+		Name:     "Chat bot",
+		Scope:    models.TokenScopeRead,
+		OrganIDs: []uint{1, 1},
+	})
+	assert.NoError(suite.T(), err)
+	assert.True(suite.T(), strings.HasPrefix(created.Token, Prefix))
+	assert.True(suite.T(), strings.HasPrefix(created.Token, created.Prefix))
+
+	var stored models.PersonalAccessToken
+	assert.NoError(suite.T(), suite.db.Preload("Organs").First(&stored, created.ID).Error)
+	assert.NotEqual(suite.T(), created.Token, stored.TokenHash)
+	assert.Len(suite.T(), stored.Organs, 1)
+}
+
+func (suite *TestTokenSuite) TestCreate_OnlyOwnOrgans() {
+	suite.db.Where("organ_id = ? AND user_id = ?", 2, suite.user.ID).Delete(&models.UserOrgan{})
+
+	_, err := suite.service.Create(suite.user.ID, &CreateRequest{
+		Name:     "Stats",
+		Scope:    models.TokenScopeRead,
+		OrganIDs: []uint{1, 2},
+	})
+	assert.ErrorIs(suite.T(), err, ErrNotMember)
+}
+
+func (suite *TestTokenSuite) TestAuthenticate() {
+	created, err := suite.service.Create(suite.user.ID, &CreateRequest{Name: "Bot", Scope: models.TokenScopeWrite})
+	suite.Require().NoError(err)
+
+	token, err := suite.service.Authenticate(created.Token)
+	assert.NoError(suite.T(), err)
+	assert.Equal(suite.T(), suite.user.ID, token.UserID)
+
+	var stored models.PersonalAccessToken
+	suite.db.First(&stored, created.ID)
+	assert.NotNil(suite.T(), stored.LastUsedAt)
+
+	_, err = suite.service.Authenticate(Prefix + "unknown")
+	assert.ErrorIs(suite.T(), err, ErrInvalidToken)
+	_, err = suite.service.Authenticate("not-a-token")
+	assert.ErrorIs(suite.T(), err, ErrInvalidToken)
+}
+
+func (suite *TestTokenSuite) TestAuthenticate_ExpiredOrRevoked() {
+	expiresAt := time.Now().Add(time.Hour)
+	expiring, err := suite.service.Create(suite.user.ID, &CreateRequest{Name: "Expiring", Scope: models.TokenScopeRead, ExpiresAt: &expiresAt})
+	suite.Require().NoError(err)
+	suite.db.Model(&models.PersonalAccessToken{}).Where("id = ?", expiring.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+	_, err = suite.service.Authenticate(expiring.Token)
+	assert.ErrorIs(suite.T(), err, ErrInvalidToken)
+
+	revoked, err := suite.service.Create(suite.user.ID, &CreateRequest{Name: "Revoked", Scope: models.TokenScopeRead})
+	suite.Require().NoError(err)
+	assert.NoError(suite.T(), suite.service.Revoke(revoked.ID, suite.user.ID))
+
+	_, err = suite.service.Authenticate(revoked.Token)
+	assert.ErrorIs(suite.T(), err, ErrInvalidToken)
+
+	tokens, err := suite.service.List(suite.user.ID)
+	assert.NoError(suite.T(), err)
+	assert.Len(suite.T(), tokens, 1)
+}
+
+func (suite *TestTokenSuite) TestRevoke_OtherUser() {
+	created, err := suite.service.Create(suite.user.ID, &CreateRequest{Name: "Bot", Scope: models.TokenScopeRead})
+	suite.Require().NoError(err)
+
+	assert.ErrorIs(suite.T(), suite.service.Revoke(created.ID, suite.user.ID+1), gorm.ErrRecordNotFound)
+
+	_, err = suite.service.Authenticate(created.Token)
+	assert.NoError(suite.T(), err)
+}
+
+func TestTokenService(t *testing.T) {
+	suite.Run(t, new(TestTokenSuite))
+}
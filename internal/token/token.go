@@ -0,0 +1,24 @@
+package token
+
+import (
+	"GEWIS-Rooster/internal/models"
+	"time"
+)
+
+type CreateRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+
+	Scope models.TokenScope `json:"scope" binding:"required,oneof=read write"`
+
+	// OrganIDs limits the token to these organs, all organs of the user when empty
+	OrganIDs []uint `json:"organIds"`
+
+	ExpiresAt *time.Time `json:"expiresAt"`
+} // @name TokenCreateRequest
+
+// CreateResponse holds the token itself, which is only shown once.
+type CreateResponse struct {
+	Token string `json:"token"`
+
+	*models.PersonalAccessToken
+} // @name TokenCreateResponse
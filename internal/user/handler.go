@@ -1,8 +1,12 @@
 package user
 
 import (
-	_ "GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/organ"
+	"GEWIS-Rooster/internal/platform/authz"
+	"errors"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"net/http"
 	"strconv"
 )
@@ -11,15 +15,17 @@ type Handler struct {
 	userService Service
 }
 
-func NewUserHandler(rg *gin.RouterGroup, userService Service) *Handler {
+func NewUserHandler(rg *authz.Router, userService Service) *Handler {
 	h := &Handler{userService: userService}
 
 	g := rg.Group("/user")
 
-	g.POST("/create", h.Create)
-	g.GET("/", h.GetAllUsers)
-	g.GET("/:id", h.GetUserByID)
-	g.DELETE("/:id", h.Delete)
+	// Users can get any GEWIS ID and organ, so only platform admins create them
+	g.POST("/create", authz.PlatformAdmin, h.Create)
+	g.GET("/", authz.Require(authz.OrganQuery("organId"), models.PermRosterView), h.GetAllUsers)
+	g.GET("/:id", authz.Authenticated, h.GetUserByID)
+	// Anonymising is irreversible, so it needs a login session of the user
+	g.DELETE("/:id", authz.Self("id").Sensitive(), h.Anonymize)
 
 	return h
 }
@@ -28,13 +34,14 @@ func NewUserHandler(rg *gin.RouterGroup, userService Service) *Handler {
 //
 //	@Summary		CreateRoster a new user
 //	@Security		BearerAuth
-//	@Description	create user
+//	@Description	create user, platform admins only
 //	@Tags			User
 //	@Accept			json
 //	@Produce		json
 //	@Param			createParams	body		UserCreateRequest	true	"User input"
 //	@Success		200				{object}	models.User
 //	@Failure		400				{string}	string
+//	@Failure		403				{string}	string
 //	@Router			/user/create [post]
 func (h *Handler) Create(c *gin.Context) {
 	var param *CreateRequest
@@ -58,15 +65,17 @@ func (h *Handler) Create(c *gin.Context) {
 // GetAllUsers
 //
 //		@Summary      Get all users with optional filtering
-//		@Description  Retrieve a list of users with optional query parameter filtering
+//		@Description  Retrieve the users of an organ with optional query parameter filtering
 //		@Security     BearerAuth
 //		@Tags         User
 //		@Accept       json
 //		@Produce      json
-//	 @Param        organId    query     uint    false  "Organ ID"
+//	 @Param        organId    query     uint    true   "Organ ID"
 //	 @Param        gewisId    query     uint    false  "GEWIS ID"
+//	 @Param        status     query     string  false  "Membership status in the organ"  Enums(active, inactive, alumni)
 //		@Success      200         {array}   models.User
 //		@Failure      400         {object}  map[string]string
+//		@Failure      403         {object}  map[string]string
 //		@Router       /user/ [get]
 func (h *Handler) GetAllUsers(c *gin.Context) {
 	f := FilterParams{}
@@ -118,31 +127,41 @@ func (h *Handler) GetUserByID(c *gin.Context) {
 	c.JSON(http.StatusOK, users)
 }
 
-// Delete
+// Anonymize
 //
-//	@Summary	DeleteRoster a user
-//	@Security	BearerAuth
-//	@Tags		User
-//	@Accept		json
-//	@Produce	json
-//	@Param		id	path		int	true	"User ID"
-//	@Success	200	{string}	string
-//	@Failure	400	{string}	string
-//	@Failure	404	{string}	string
-//	@Router		/user/{id} [delete]
-func (h *Handler) Delete(c *gin.Context) {
+//	@Summary		Anonymise a user
+//	@Security		BearerAuth
+//	@Description	Replaces the name, GEWIS ID and organ usernames of the user by a tombstone and signs them out everywhere. Answers and assignments are kept, so historic rosters stay complete. The last owner of an organ must hand over ownership first.
+//	@Tags			User
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"User ID"
+//	@Success		200	{string}	string
+//	@Failure		400	{string}	string
+//	@Failure		404	{string}	string
+//	@Failure		409	{string}	string
+//	@Router			/user/{id} [delete]
+func (h *Handler) Anonymize(c *gin.Context) {
 	userId, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID"})
 		return
 	}
 
-	if err := h.userService.Delete(uint(userId)); err != nil {
+	if err := h.userService.Anonymize(uint(userId)); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		if errors.Is(err, organ.ErrLastOwner) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message": "User deleted",
+		"message": "User anonymised",
 	})
 }
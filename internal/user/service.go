@@ -2,15 +2,18 @@ package user
 
 import (
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/organ"
 	"errors"
+	"fmt"
 	"github.com/rs/zerolog/log"
 	"gorm.io/gorm"
+	"time"
 )
 
 type Service interface {
 	Create(*CreateRequest) (*models.User, error)
 	Get(*FilterParams) ([]*models.User, error)
-	Delete(uint) error
+	Anonymize(uint) error
 }
 
 type service struct {
@@ -33,9 +36,10 @@ func (s *service) Create(createParams *CreateRequest) (*models.User, error) {
 		})
 	}
 
+	gewisID := createParams.GEWISID
 	user := models.User{
 		Name:    createParams.Name,
-		GEWISID: createParams.GEWISID,
+		GEWISID: &gewisID,
 		Organs:  userOrgans,
 	}
 
@@ -59,6 +63,10 @@ func (s *service) Get(filters *FilterParams) ([]*models.User, error) {
 		if filters.OrganID != nil {
 			db = db.Joins("JOIN user_organs ON user_organs.user_id = users.id").
 				Where("user_organs.organ_id = ?", *filters.OrganID)
+
+			if filters.Status != nil {
+				db = db.Where("user_organs.status = ?", *filters.Status)
+			}
 		}
 	}
 
@@ -70,16 +78,71 @@ func (s *service) Get(filters *FilterParams) ([]*models.User, error) {
 	return users, nil
 }
 
-func (s *service) Delete(ID uint) error {
-	result := s.db.Unscoped().Delete(&models.User{}, ID)
+// Anonymize replaces the personal data of the user by a tombstone: the name,
+// GEWIS ID, email and organ usernames. Answers, assignments, priorities and audit
+// logs are kept so historic rosters and statistics stay intact. The user
+// becomes alumni of their organs and loses their sessions, tokens,
+// notifications and waitlist entries. Anonymising a user twice is a no-op.
+// The last active owner of an organ cannot be anonymised, like they cannot
+// leave it, and gets organ.ErrLastOwner.
+func (s *service) Anonymize(ID uint) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, ID).Error; err != nil {
+			return err
+		}
+		if user.AnonymizedAt != nil {
+			return nil
+		}
 
-	if result.Error != nil {
-		return result.Error
-	}
+		var memberships []models.UserOrgan
+		if err := tx.Where("user_id = ?", ID).Find(&memberships).Error; err != nil {
+			return err
+		}
+		for _, membership := range memberships {
+			if membership.Role == models.RoleOwner && membership.Status == models.MembershipActive {
+				if err := organ.CheckOtherOwners(tx, membership.OrganID); err != nil {
+					return err
+				}
+			}
+		}
 
-	if result.RowsAffected == 0 {
-		return gorm.ErrRecordNotFound
-	}
+		if err := tx.Model(&user).Updates(map[string]interface{}{
+			"name":           fmt.Sprintf("Anonymous user %d", user.ID),
+			"gewis_id":       nil,
+			"email":          nil,
+			"platform_admin": false,
+			"anonymized_at":  time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		for _, membership := range memberships {
+			updates := map[string]interface{}{"username": ""}
+			if membership.Status != models.MembershipAlumni {
+				updates = membership.StatusChange(models.MembershipAlumni)
+				updates["username"] = ""
+			}
+			if err := tx.Model(&models.UserOrgan{}).
+				Where("organ_id = ? AND user_id = ?", membership.OrganID, ID).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, model := range []interface{}{
+			&models.Session{},
+			&models.AuthCode{},
+			&models.PersonalAccessToken{},
+			&models.Notification{},
+			&models.SavedShiftWaitlistEntry{},
+		} {
+			if err := tx.Where("user_id = ?", ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
 
-	return nil
+		// Sessions in which the user impersonated someone else
+		return tx.Where("impersonator_id = ?", ID).Delete(&models.Session{}).Error
+	})
 }
@@ -3,6 +3,8 @@ package user
 import (
 	"GEWIS-Rooster/cmd/seeder/seeder"
 	"GEWIS-Rooster/internal/models"
+	"GEWIS-Rooster/internal/organ"
+	"fmt"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 	"gorm.io/gorm"
@@ -34,7 +36,7 @@ func (suite *TestUserSuite) TestCreateUser_ValidInput() {
 	assert.NoError(suite.T(), err)
 	assert.NotNil(suite.T(), user)
 	assert.Equal(suite.T(), params.Name, user.Name)
-	assert.Equal(suite.T(), params.GEWISID, user.GEWISID)
+	assert.Equal(suite.T(), params.GEWISID, *user.GEWISID)
 }
 
 func (suite *TestUserSuite) TestCreateUser_WithoutOrgans() {
@@ -141,7 +143,7 @@ func (suite *TestUserSuite) TestGetUser_ByGEWISID() {
 
 	assert.NoError(suite.T(), err)
 	assert.Len(suite.T(), users, 1)
-	assert.Equal(suite.T(), gewisID, users[0].GEWISID)
+	assert.Equal(suite.T(), gewisID, *users[0].GEWISID)
 }
 
 func (suite *TestUserSuite) TestGetUser_ByOrganID() {
@@ -185,24 +187,67 @@ func (suite *TestUserSuite) TestGetUser_NoMatch() {
 	assert.Empty(suite.T(), users)
 }
 
-//func (suite *TestUserSuite) TestDeleteUser_ValidInput() {
-//	var user models.User
-//	suite.db.First(&user)
-//
-//	err := suite.service.Delete(user.ID)
-//	assert.NoError(suite.T(), err)
-//
-//	var deletedUser models.User
-//	result := suite.db.First(&deletedUser, user.ID)
-//	assert.Equal(suite.T(), gorm.ErrRecordNotFound, result.Error)
-//}
-
-func (suite *TestUserSuite) TestDeleteUser_NotFound() {
+func (suite *TestUserSuite) TestAnonymizeUser_KeepsHistory() {
+	var membership models.UserOrgan
+	suite.db.Where("status = ?", models.MembershipActive).First(&membership)
+	userID := membership.UserID
+
+	roster := models.Roster{Name: "History", OrganID: membership.OrganID, RosterShift: []models.RosterShift{{Name: "Bar"}}}
+	suite.db.Create(&roster)
+	suite.db.Create(&models.RosterAnswer{UserID: userID, RosterID: roster.ID, RosterShiftID: roster.RosterShift[0].ID, Value: "J"})
+	suite.db.Create(&models.Session{UserID: userID})
+
+	err := suite.service.Anonymize(userID)
+	assert.NoError(suite.T(), err)
+
+	var user models.User
+	suite.db.First(&user, userID)
+	assert.Nil(suite.T(), user.GEWISID)
+	assert.NotNil(suite.T(), user.AnonymizedAt)
+	assert.Equal(suite.T(), fmt.Sprintf("Anonymous user %d", userID), user.Name)
+
+	var memberships []models.UserOrgan
+	suite.db.Where("user_id = ?", userID).Find(&memberships)
+	assert.NotEmpty(suite.T(), memberships)
+	for _, m := range memberships {
+		assert.Empty(suite.T(), m.Username)
+		assert.Equal(suite.T(), models.MembershipAlumni, m.Status)
+	}
+
+	var answers, sessions int64
+	suite.db.Model(&models.RosterAnswer{}).Where("user_id = ? AND roster_id = ?", userID, roster.ID).Count(&answers)
+	suite.db.Model(&models.Session{}).Where("user_id = ?", userID).Count(&sessions)
+	assert.EqualValues(suite.T(), 1, answers)
+	assert.Zero(suite.T(), sessions)
+
+	// A second call changes nothing
+	assert.NoError(suite.T(), suite.service.Anonymize(userID))
+}
+
+func (suite *TestUserSuite) TestAnonymizeUser_LastOwner() {
+	var owner models.UserOrgan
+	suite.db.Where("organ_id = ? AND status = ?", 1, models.MembershipActive).First(&owner)
+	suite.db.Model(&models.UserOrgan{}).Where("organ_id = ?", 1).Update("role", models.RoleMember)
+	suite.db.Model(&models.UserOrgan{}).Where("organ_id = ? AND user_id = ?", 1, owner.UserID).Update("role", models.RoleOwner)
+
+	err := suite.service.Anonymize(owner.UserID)
+	assert.ErrorIs(suite.T(), err, organ.ErrLastOwner)
+
+	var user models.User
+	suite.db.First(&user, owner.UserID)
+	assert.Nil(suite.T(), user.AnonymizedAt)
+
+	// Once someone else owns the organ as well, the owner can be anonymised
+	suite.db.Model(&models.UserOrgan{}).Where("organ_id = ? AND user_id <> ?", 1, owner.UserID).Update("role", models.RoleOwner)
+	assert.NoError(suite.T(), suite.service.Anonymize(owner.UserID))
+}
+
+func (suite *TestUserSuite) TestAnonymizeUser_NotFound() {
 	var user models.User
 	suite.db.Last(&user)
 
-	err := suite.service.Delete(user.ID + 1)
-	assert.Error(suite.T(), err)
+	err := suite.service.Anonymize(user.ID + 1)
+	assert.ErrorIs(suite.T(), err, gorm.ErrRecordNotFound)
 }
 
 func TestUserService(t *testing.T) {
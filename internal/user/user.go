@@ -1,5 +1,7 @@
 package user
 
+import "GEWIS-Rooster/internal/models"
+
 type CreateRequest struct {
 	Name string
 
@@ -12,4 +14,7 @@ type FilterParams struct {
 	ID      *uint `form:"id"`
 	GEWISID *uint `form:"gewisId"`
 	OrganID *uint `form:"organId"`
+
+	// Status limits the members of OrganID to those with the membership status
+	Status *models.MembershipStatus `form:"status" binding:"omitempty,oneof=active inactive alumni"`
 } // @name UserFilterParams